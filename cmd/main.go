@@ -2,10 +2,17 @@ package main
 
 import (
 	"fmt"
+	"os"
+
 	"github.com/Orion777-cmd/weather-app/initiator"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		initiator.Migrate(os.Args[2:])
+		return
+	}
+
     fmt.Println("Hello, world")
 	initiator.Init()
 	fmt.Println("Initiator completed successfully")
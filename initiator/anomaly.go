@@ -0,0 +1,29 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/anomaly"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitAnomalyDetector builds the anomaly detector from the anomaly config
+// section. It returns nil when anomaly detection is disabled.
+func InitAnomalyDetector(repo *repository.WeatherRepository, logger *zap.Logger) *anomaly.Detector {
+	if !viper.GetBool("anomaly.enabled") {
+		logger.Info("Anomaly detection disabled")
+		return nil
+	}
+
+	cfg := anomaly.Config{
+		WindowDays:   viper.GetInt("anomaly.window_days"),
+		RadiusKm:     viper.GetFloat64("anomaly.radius_km"),
+		MinSamples:   viper.GetInt("anomaly.min_samples"),
+		Threshold:    viper.GetFloat64("anomaly.threshold"),
+		CacheEntries: viper.GetInt("anomaly.cache_entries"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid anomaly config", zap.Error(err))
+	}
+	return anomaly.NewDetector(repo, cfg)
+}
@@ -0,0 +1,21 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/handler"
+	md "github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitBatchHandler builds the batch weather handler from the batch config
+// section.
+func InitBatchHandler(weatherService md.WeatherService, logger *zap.Logger) *handler.BatchHandler {
+	cfg := handler.BatchConfig{
+		MaxLocations: viper.GetInt("batch.max_locations"),
+		Concurrency:  viper.GetInt("batch.concurrency"),
+	}
+	if cfg.MaxLocations <= 0 || cfg.Concurrency <= 0 {
+		logger.Fatal("Invalid batch config", zap.Any("config", cfg))
+	}
+	return handler.NewBatchHandler(weatherService, cfg, logger)
+}
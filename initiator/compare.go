@@ -0,0 +1,21 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/handler"
+	md "github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitCompareHandler builds the city comparison handler from the compare
+// config section.
+func InitCompareHandler(weatherService md.WeatherService, logger *zap.Logger) *handler.CompareHandler {
+	cfg := handler.CompareConfig{
+		MaxCities:   viper.GetInt("compare.max_cities"),
+		Concurrency: viper.GetInt("compare.concurrency"),
+	}
+	if cfg.MaxCities < 2 || cfg.Concurrency <= 0 {
+		logger.Fatal("Invalid compare config", zap.Any("config", cfg))
+	}
+	return handler.NewCompareHandler(weatherService, cfg, logger)
+}
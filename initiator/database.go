@@ -2,21 +2,107 @@ package initiator
 
 import (
 	"context"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"github.com/jackc/pgx/v5"
 )
 
-func InitDatabase(databaseURL string, logger *zap.Logger) *pgx.Conn {
+// InitDatabase opens a connection pool sized and tuned by the database config
+// section and registers its stats as Prometheus metrics.
+func InitDatabase(databaseURL string, logger *zap.Logger) *pgxpool.Pool {
 	logger.Info("Connecting to PostgreSQL")
-	conn, err := pgx.Connect(context.Background(), databaseURL)
+	cfg, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		logger.Fatal("Invalid database URL", zap.Error(err))
+	}
+
+	if v := viper.GetInt32("database.max_conns"); v > 0 {
+		cfg.MaxConns = v
+	}
+	if v := viper.GetInt32("database.min_conns"); v > 0 {
+		cfg.MinConns = v
+	}
+	if v := viper.GetDuration("database.max_conn_lifetime"); v > 0 {
+		cfg.MaxConnLifetime = v
+	}
+	if v := viper.GetDuration("database.max_conn_idle_time"); v > 0 {
+		cfg.MaxConnIdleTime = v
+	}
+	if v := viper.GetDuration("database.health_check_period"); v > 0 {
+		cfg.HealthCheckPeriod = v
+	}
+	if v := viper.GetDuration("database.statement_timeout"); v > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(v.Milliseconds(), 10)
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
 
-	if err := conn.Ping(context.Background()); err != nil {
+	if err := pool.Ping(context.Background()); err != nil {
 		logger.Fatal("Failed to ping database", zap.Error(err))
 	}
 
-	logger.Info("PostgresSQL connection established successfully")
-	return conn
-}
\ No newline at end of file
+	if err := prometheus.Register(newPoolCollector(pool)); err != nil {
+		logger.Warn("Failed to register database pool metrics", zap.Error(err))
+	}
+
+	logger.Info("PostgresSQL connection pool established successfully",
+		zap.Int32("max_conns", cfg.MaxConns),
+		zap.Int32("min_conns", cfg.MinConns))
+	return pool
+}
+
+// poolCollector exports pgxpool statistics.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	newConnsCount        *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("weather_db_pool_"+name, help, nil, nil)
+	}
+	return &poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Connections currently in use."),
+		idleConns:            desc("idle_conns", "Connections currently idle."),
+		totalConns:           desc("total_conns", "Connections currently open."),
+		maxConns:             desc("max_conns", "Maximum size of the pool."),
+		acquireCount:         desc("acquire_total", "Successful connection acquires."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		emptyAcquireCount:    desc("empty_acquire_total", "Acquires that had to wait for a connection."),
+		canceledAcquireCount: desc("canceled_acquire_total", "Acquires cancelled by their context."),
+		newConnsCount:        desc("new_conns_total", "Connections opened."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.newConnsCount, prometheus.CounterValue, float64(s.NewConnsCount()))
+}
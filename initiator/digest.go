@@ -0,0 +1,51 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/digest"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/platform/smtp"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitDigests builds the digest sender and worker from the digests config
+// section. Forecasts are taken from weather. It returns nils when digests are
+// disabled.
+func InitDigests(repo *repository.WeatherRepository, weather digest.WeatherSource, logger *zap.Logger) (*digest.Sender, *digest.Worker) {
+	if !viper.GetBool("digests.enabled") {
+		logger.Info("Email digests disabled")
+		return nil, nil
+	}
+
+	smtpCfg := smtp.Config{
+		Host:     viper.GetString("digests.smtp.host"),
+		Port:     viper.GetInt("digests.smtp.port"),
+		Username: viper.GetString("digests.smtp.username"),
+		Password: viper.GetString("digests.smtp.password"),
+		From:     viper.GetString("digests.smtp.from"),
+		TLS:      viper.GetString("digests.smtp.tls"),
+		Timeout:  viper.GetDuration("digests.smtp.timeout"),
+	}
+	if err := smtpCfg.Validate(); err != nil {
+		logger.Fatal("Invalid digests SMTP config", zap.Error(err))
+	}
+
+	cfg := digest.Config{
+		Interval:      viper.GetDuration("digests.interval"),
+		BatchSize:     viper.GetInt("digests.batch_size"),
+		Concurrency:   viper.GetInt("digests.concurrency"),
+		Timeout:       viper.GetDuration("digests.timeout"),
+		MaxAttempts:   viper.GetInt("digests.max_attempts"),
+		RetryInterval: viper.GetDuration("digests.retry_interval"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid digests config", zap.Error(err))
+	}
+	baseURL := viper.GetString("digests.base_url")
+	if baseURL == "" {
+		logger.Fatal("Invalid digests config", zap.String("base_url", baseURL))
+	}
+
+	sender := digest.NewSender(weather, smtp.NewMailer(smtpCfg, logger), baseURL, logger)
+	return sender, digest.NewWorker(repo, sender, cfg, logger)
+}
@@ -0,0 +1,25 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/graph"
+	"github.com/Orion777-cmd/weather-app/internal/handler"
+	md "github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitGraphQLHandler builds the GraphQL handler from the graphql config
+// section.
+func InitGraphQLHandler(weatherService md.WeatherService, logger *zap.Logger) *handler.GraphQLHandler {
+	cfg := graph.Config{
+		MaxDepth:       viper.GetInt("graphql.max_depth"),
+		MaxQueryLength: viper.GetInt("graphql.max_query_length"),
+		MaxComplexity:  viper.GetInt("graphql.max_complexity"),
+		MaxPlaces:      viper.GetInt("graphql.max_places"),
+		Concurrency:    viper.GetInt("graphql.concurrency"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid graphql config", zap.Error(err))
+	}
+	return handler.NewGraphQLHandler(graph.NewServer(weatherService, cfg, logger), logger)
+}
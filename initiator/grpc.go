@@ -0,0 +1,42 @@
+package initiator
+
+import (
+	"net"
+
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	md "github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/Orion777-cmd/weather-app/internal/rpc"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// InitGRPC builds the gRPC server from the grpc config section and starts
+// listening on its address. It returns nils when gRPC is disabled.
+func InitGRPC(weatherService md.WeatherService, bus *events.Bus, logger *zap.Logger) (*grpc.Server, net.Listener) {
+	if !viper.GetBool("grpc.enabled") {
+		logger.Info("gRPC server disabled")
+		return nil, nil
+	}
+
+	cfg := rpc.Config{
+		Refresh:       viper.GetDuration("grpc.refresh_interval"),
+		Buffer:        viper.GetInt("grpc.buffer"),
+		MatchRadiusKm: viper.GetFloat64("grpc.match_radius_km"),
+	}
+	if cfg.Refresh <= 0 || cfg.Buffer <= 0 || cfg.MatchRadiusKm < 0 {
+		logger.Fatal("Invalid grpc config", zap.Any("config", cfg))
+	}
+	address := viper.GetString("grpc.address")
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		logger.Fatal("Failed to listen for gRPC", zap.Error(err), zap.String("address", address))
+	}
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(rpc.LoggingInterceptor(logger)))
+	rpc.NewServer(weatherService, bus, cfg, logger).Register(server)
+	// Lets tools such as grpcurl discover the service.
+	reflection.Register(server)
+	return server, lis
+}
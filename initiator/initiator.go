@@ -1,13 +1,13 @@
 package initiator
 
 import (
+	"context"
 	"log"
 
+	"github.com/Orion777-cmd/weather-app/internal/events"
 	"github.com/Orion777-cmd/weather-app/internal/handler"
-	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/internal/openapi"
 	"github.com/Orion777-cmd/weather-app/platform/openWeatherMap"
-	dbpkg "github.com/Orion777-cmd/weather-app/internal/db"
-	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
@@ -24,21 +24,12 @@ func Init() {
 	initConfig("config", "./config", logger)
 	logger.Info("Config initialization completed")
 
-	logger.Info("Initializing database")
-	db := InitDatabase(viper.GetString("database.url"), logger)
-	if err != nil {
-		logger.Fatal("Failed to connect to database", zap.Error(err))
-	}
-
-	logger.Info("Database initialized")
-
 	logger.Info("Initializing persistence layer")
-	persistence := InitPersistence(db, logger)
+	persistence := InitPersistence(logger)
 	logger.Info("Persistence layer initialized")
 
-	// initializing database repository
-	sqlcQueries := dbpkg.New(db)
-	weatherRepo := repository.NewWeatherRepository(sqlcQueries)
+	// Fresh weather data is published here for background consumers.
+	bus := events.NewBus(viper.GetInt("stream.replay"))
 
 	//initializing platform layer
 	logger.Info("Initializing platform layer")
@@ -50,23 +41,65 @@ func Init() {
 	if weatherApi == nil {
 		logger.Fatal("Failed to initialize OpenWeatherMap API client")
 	}
+	if persistence.Postgres != nil {
+		// Prefetches go straight to the provider so they always store fresh data.
+		if scheduler := InitPrefetch(persistence.Postgres, weatherApi, bus, logger); scheduler != nil {
+			logger.Info("Starting prefetch scheduler")
+			go scheduler.Run(context.Background())
+		}
+		if evaluator, dispatcher := InitWebhooks(persistence.Postgres, bus, logger); evaluator != nil {
+			logger.Info("Starting webhook evaluator and dispatcher")
+			go evaluator.Run(context.Background())
+			go dispatcher.Run(context.Background())
+		}
+	}
+	weatherApi = InitWeatherCache(weatherApi, logger)
 	logger.Info("Platform layer initialized")
 
 	// initializing weather module
 	logger.Info("Initializing weather API client")
-	module := InitWeatherModule(persistence, weatherApi, *weatherRepo, logger)
+	module := InitWeatherModule(persistence, weatherApi, bus, logger)
 
 	logger.Info("Weather API client initialized")
 
 	// initializing handler
     logger.Info("Initializing HTTP handler")
-    weatherHandler := handler.NewWeatherHandler(module.weatherModule, logger)
+    spec, err := openapi.Load()
+    if err != nil {
+        logger.Fatal("Failed to load OpenAPI spec", zap.Error(err))
+    }
+    handlers := Handlers{
+        Weather:   handler.NewWeatherHandler(module.weatherModule, logger),
+        Batch:     InitBatchHandler(module.weatherModule, logger),
+        Compare:   InitCompareHandler(module.weatherModule, logger),
+        Stream:    InitStreamHandler(module.weatherModule, bus, logger),
+        WebSocket: InitWebSocketHandler(module.weatherModule, bus, logger),
+        GraphQL:   InitGraphQLHandler(module.weatherModule, logger),
+        Docs:      handler.NewDocsHandler(spec),
+    }
+    if module.watchedModule != nil {
+        handlers.Location = handler.NewLocationHandler(module.watchedModule, logger)
+    }
+    if module.verificationModule != nil {
+        handlers.Verification = handler.NewVerificationHandler(module.verificationModule, logger)
+    }
+    if module.subscriptionModule != nil {
+        handlers.Subscription = handler.NewSubscriptionHandler(module.subscriptionModule, logger)
+    }
+    if module.digestModule != nil {
+        handlers.Digest = handler.NewDigestHandler(module.digestModule, logger)
+    }
+    router := NewRouter(handlers, spec, InitDeprecation(logger), logger)
     logger.Info("HTTP handler initialized")
 
-    // Example Gin router setup
-    router := gin.Default()
-    router.GET("/weather", weatherHandler.GetWeather)
-    router.GET("/history", weatherHandler.GetHistory)
+    if server, lis := InitGRPC(module.weatherModule, bus, logger); server != nil {
+        logger.Info("Starting gRPC server", zap.String("address", lis.Addr().String()))
+        go func() {
+            if err := server.Serve(lis); err != nil {
+                logger.Fatal("gRPC server stopped", zap.Error(err))
+            }
+        }()
+    }
 
     // Start the server (optional: port from config)
     logger.Info("Starting HTTP server on :8080")
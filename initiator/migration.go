@@ -0,0 +1,83 @@
+package initiator
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Orion777-cmd/weather-app/internal/db/migrate"
+	"github.com/Orion777-cmd/weather-app/internal/db/migrations"
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitMigrator loads the embedded schema migrations.
+func InitMigrator(conn *pgx.Conn, logger *zap.Logger) *migrate.Migrator {
+	migrator, err := migrate.New(conn, migrations.FS, logger)
+	if err != nil {
+		logger.Fatal("Failed to load migrations", zap.Error(err))
+	}
+	return migrator
+}
+
+// Migrate runs the migrate subcommand. args is one of up, down or status.
+func Migrate(args []string) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("unable to start logger: %v", err)
+	}
+	defer logger.Sync()
+
+	if len(args) != 1 {
+		logger.Fatal("usage: migrate up|down|status")
+	}
+
+	initConfig("config", "./config", logger)
+	pool := InitDatabase(viper.GetString("database.url"), logger)
+	defer pool.Close()
+
+	ctx := context.Background()
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		logger.Fatal("Failed to acquire database connection", zap.Error(err))
+	}
+	defer conn.Release()
+	migrator := InitMigrator(conn.Conn(), logger)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			logger.Fatal("Failed to apply migrations", zap.Error(err))
+		}
+		logger.Info("Migrations applied", zap.Int("count", applied))
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			logger.Fatal("Failed to revert migration", zap.Error(err))
+		}
+		if !reverted {
+			logger.Info("No migration to revert")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Fatal("Failed to read migration status", zap.Error(err))
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%06d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		logger.Fatal("usage: migrate up|down|status", zap.String("command", args[0]))
+	}
+}
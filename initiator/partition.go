@@ -0,0 +1,28 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/partition"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitPartitionManager builds the history partition manager from the
+// partitioning config section. It returns nil when partitioning maintenance is
+// disabled.
+func InitPartitionManager(pool *pgxpool.Pool, logger *zap.Logger) *partition.Manager {
+	if !viper.GetBool("partitioning.enabled") {
+		logger.Info("Partition manager disabled")
+		return nil
+	}
+
+	cfg := partition.Config{
+		Interval:      viper.GetDuration("partitioning.interval"),
+		PremakeMonths: viper.GetInt("partitioning.premake_months"),
+		RetainMonths:  viper.GetInt("partitioning.retain_months"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid partitioning config", zap.Error(err))
+	}
+	return partition.NewManager(pool, cfg, logger)
+}
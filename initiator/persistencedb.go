@@ -1,14 +1,96 @@
 package initiator
 
 import (
+	"context"
+
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"github.com/jackc/pgx/v5"
 )
 
-type Persistence struct {}
+// Persistence holds the storage backend selected by storage.backend. DB and
+// Postgres are only set for the postgres backend; features that need them are
+// not available on the others.
+type Persistence struct {
+	Store    repository.Store
+	DB       *pgxpool.Pool
+	Postgres *repository.WeatherRepository
+}
+
+// InitPersistence opens the configured storage backend. Postgres is the
+// default.
+func InitPersistence(logger *zap.Logger) Persistence {
+	backend := viper.GetString("storage.backend")
+	logger.Info("Initializing storage backend", zap.String("backend", backend))
+
+	switch backend {
+	case "", repository.BackendPostgres:
+		return initPostgres(logger)
+	case repository.BackendSQLite:
+		path := viper.GetString("storage.sqlite.path")
+		store, err := repository.NewSQLiteRepository(context.Background(), path)
+		if err != nil {
+			logger.Fatal("Failed to open SQLite database", zap.Error(err), zap.String("path", path))
+		}
+		logger.Info("SQLite database opened", zap.String("path", path))
+		return Persistence{Store: store}
+	case repository.BackendMemory:
+		return Persistence{Store: repository.NewMemoryRepository(viper.GetInt("storage.memory.max_entries"))}
+	default:
+		logger.Fatal("Unknown storage backend", zap.String("backend", backend))
+		return Persistence{}
+	}
+}
+
+// initPostgres connects to Postgres, brings the schema up to date and starts
+// the database maintenance workers.
+func initPostgres(logger *zap.Logger) Persistence {
+	logger.Info("Initializing database")
+	db := InitDatabase(viper.GetString("database.url"), logger)
+	logger.Info("Database initialized")
+
+	if viper.GetBool("database.auto_migrate") {
+		logger.Info("Applying database migrations")
+		conn, err := db.Acquire(context.Background())
+		if err != nil {
+			logger.Fatal("Failed to acquire database connection", zap.Error(err))
+		}
+		applied, err := InitMigrator(conn.Conn(), logger).Up(context.Background())
+		conn.Release()
+		if err != nil {
+			logger.Fatal("Failed to apply migrations", zap.Error(err))
+		}
+		logger.Info("Database migrations applied", zap.Int("count", applied))
+	}
+
+	// initializing database repository
+	weatherRepo := repository.NewWeatherRepository(db)
+
+	logger.Info("Backfilling forecast snapshots from query history")
+	migrated, skipped, err := weatherRepo.BackfillSnapshots(context.Background(), viper.GetInt("database.backfill_batch_size"))
+	if err != nil {
+		logger.Fatal("Failed to backfill forecast snapshots", zap.Error(err))
+	}
+	logger.Info("Forecast snapshot backfill completed", zap.Int("migrated", migrated), zap.Int("skipped", skipped))
+
+	if manager := InitPartitionManager(db, logger); manager != nil {
+		// Make sure the current month's partition exists before serving writes.
+		if err := manager.RunOnce(context.Background()); err != nil {
+			logger.Fatal("Failed to maintain history partitions", zap.Error(err))
+		}
+		go manager.Run(context.Background())
+	}
+
+	if worker := InitRetention(weatherRepo, logger); worker != nil {
+		logger.Info("Starting retention worker")
+		go worker.Run(context.Background())
+	}
+
+	if worker := InitVerification(weatherRepo, logger); worker != nil {
+		logger.Info("Starting forecast verification worker")
+		go worker.Run(context.Background())
+	}
 
-func InitPersistence(db *pgx.Conn, logger *zap.Logger) Persistence {
-	
-	logger.Info("Persistence layer initialized")
-	return Persistence{}
-}
\ No newline at end of file
+	return Persistence{Store: weatherRepo, DB: db, Postgres: weatherRepo}
+}
@@ -0,0 +1,31 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/prefetch"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/platform"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitPrefetch builds the watched location prefetch scheduler from the
+// prefetch config section. It returns nil when prefetching is disabled.
+func InitPrefetch(repo *repository.WeatherRepository, weatherAPI platform.WeatherAPI, bus *events.Bus, logger *zap.Logger) *prefetch.Scheduler {
+	if !viper.GetBool("prefetch.enabled") {
+		logger.Info("Prefetch scheduler disabled")
+		return nil
+	}
+
+	cfg := prefetch.Config{
+		Interval:    viper.GetDuration("prefetch.interval"),
+		Concurrency: viper.GetInt("prefetch.concurrency"),
+		BatchSize:   viper.GetInt("prefetch.batch_size"),
+		Timeout:     viper.GetDuration("prefetch.timeout"),
+		Jitter:      viper.GetFloat64("prefetch.jitter"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid prefetch config", zap.Error(err))
+	}
+	return prefetch.NewScheduler(repo, weatherAPI, bus, cfg, logger)
+}
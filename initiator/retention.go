@@ -0,0 +1,29 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/internal/retention"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitRetention builds the history retention worker from the retention config
+// section. It returns nil when retention is disabled.
+func InitRetention(repo *repository.WeatherRepository, logger *zap.Logger) *retention.Worker {
+	if !viper.GetBool("retention.enabled") {
+		logger.Info("Retention worker disabled")
+		return nil
+	}
+
+	cfg := retention.Config{
+		Interval:      viper.GetDuration("retention.interval"),
+		FullDays:      viper.GetInt("retention.full_days"),
+		HardLimitDays: viper.GetInt("retention.hard_limit_days"),
+		BatchSize:     viper.GetInt("retention.batch_size"),
+		DryRun:        viper.GetBool("retention.dry_run"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid retention config", zap.Error(err))
+	}
+	return retention.NewWorker(repo, cfg, logger)
+}
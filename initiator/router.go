@@ -0,0 +1,114 @@
+package initiator
+
+import (
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/handler"
+	"github.com/Orion777-cmd/weather-app/internal/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Handlers are the HTTP handlers routed by NewRouter. Location,
+// Verification, Subscription and Digest are nil when their feature needs
+// Postgres and it is not configured.
+type Handlers struct {
+	Weather      *handler.WeatherHandler
+	Batch        *handler.BatchHandler
+	Compare      *handler.CompareHandler
+	Stream       *handler.StreamHandler
+	WebSocket    *handler.WebSocketHandler
+	GraphQL      *handler.GraphQLHandler
+	Docs         *handler.DocsHandler
+	Location     *handler.LocationHandler
+	Verification *handler.VerificationHandler
+	Subscription *handler.SubscriptionHandler
+	Digest       *handler.DigestHandler
+}
+
+// Deprecation says when the unversioned routes, the v1 routes from before
+// the API was versioned, were deprecated and when they go away.
+type Deprecation struct {
+	Since  time.Time
+	Sunset time.Time
+}
+
+// InitDeprecation reads when the unversioned routes were deprecated and
+// when they go away from the api config section.
+func InitDeprecation(logger *zap.Logger) Deprecation {
+	legacy := Deprecation{
+		Since:  viper.GetTime("api.deprecated_since"),
+		Sunset: viper.GetTime("api.sunset"),
+	}
+	if legacy.Since.IsZero() || !legacy.Sunset.After(legacy.Since) {
+		logger.Fatal("Invalid api config", zap.Time("deprecated_since", legacy.Since), zap.Time("sunset", legacy.Sunset))
+	}
+	return legacy
+}
+
+// NewRouter routes requests to h. Every route must be described in spec,
+// which requests are validated against. The v1 routes are also served
+// without a version prefix, marked as deprecated.
+func NewRouter(h Handlers, spec *openapi.Spec, legacy Deprecation, logger *zap.Logger) *gin.Engine {
+	router := gin.Default()
+	router.Use(handler.RequestID())
+	validator := spec.Validator(logger)
+
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/openapi.json", h.Docs.Spec)
+	router.GET("/docs", h.Docs.Docs)
+	router.GET("/docs/swagger-ui/:file", h.Docs.Asset)
+
+	routeV1(router.Group("/v1", validator), h)
+	// Deprecation headers come first so they are set on rejected requests too.
+	routeV1(router.Group("", handler.Deprecated(legacy.Since, legacy.Sunset, "/v1"), validator), h)
+
+	v2 := router.Group("/v2", validator)
+	v2.GET("/weather", h.Weather.GetWeatherV2)
+	v2.GET("/history", h.Weather.GetHistoryV2)
+	return router
+}
+
+func routeV1(router *gin.RouterGroup, h Handlers) {
+	router.GET("/weather", h.Weather.GetWeather)
+	router.POST("/weather/batch", h.Batch.Batch)
+	router.GET("/weather/compare", h.Compare.Compare)
+	router.GET("/weather/stream", h.Stream.Stream)
+	router.GET("/ws", h.WebSocket.Serve)
+	router.GET("/graphql", h.GraphQL.Query)
+	router.POST("/graphql", h.GraphQL.Query)
+	router.GET("/history", h.Weather.GetHistory)
+	router.GET("/stats", h.Weather.GetStats)
+	if h.Location != nil {
+		watched := router.Group("/locations/watched")
+		watched.GET("", h.Location.ListWatched)
+		watched.POST("", h.Location.CreateWatched)
+		watched.GET("/:id", h.Location.GetWatched)
+		watched.PUT("/:id", h.Location.UpdateWatched)
+		watched.DELETE("/:id", h.Location.DeleteWatched)
+	}
+	if h.Verification != nil {
+		router.GET("/verification", h.Verification.GetVerification)
+	}
+	if h.Subscription != nil {
+		subscriptions := router.Group("/subscriptions")
+		subscriptions.POST("", h.Subscription.CreateSubscription)
+		subscriptions.GET("/:id", h.Subscription.GetSubscription)
+		subscriptions.DELETE("/:id", h.Subscription.DeleteSubscription)
+		subscriptions.GET("/:id/deliveries", h.Subscription.ListDeliveries)
+	}
+	if h.Digest != nil {
+		digests := router.Group("/digests")
+		digests.POST("", h.Digest.CreateDigest)
+		digests.GET("/confirm", h.Digest.Confirm)
+		digests.POST("/confirm", h.Digest.Confirm)
+		digests.GET("/unsubscribe", h.Digest.Unsubscribe)
+		digests.POST("/unsubscribe", h.Digest.Unsubscribe)
+		digests.GET("/:id", h.Digest.GetDigest)
+		digests.DELETE("/:id", h.Digest.DeleteDigest)
+		digests.GET("/:id/deliveries", h.Digest.ListDeliveries)
+		digests.POST("/:id/send", h.Digest.SendDigest)
+	}
+}
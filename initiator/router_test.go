@@ -0,0 +1,61 @@
+package initiator
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/handler"
+	"github.com/Orion777-cmd/weather-app/internal/openapi"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+// TestRouterMatchesSpec fails when a route is missing from the OpenAPI spec
+// or a described operation is not routed.
+func TestRouterMatchesSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("loading spec: %v", err)
+	}
+	// Every optional handler is set so every route is registered.
+	router := NewRouter(Handlers{
+		Weather:      &handler.WeatherHandler{},
+		Batch:        &handler.BatchHandler{},
+		Compare:      &handler.CompareHandler{},
+		Stream:       &handler.StreamHandler{},
+		WebSocket:    &handler.WebSocketHandler{},
+		GraphQL:      &handler.GraphQLHandler{},
+		Docs:         &handler.DocsHandler{},
+		Location:     &handler.LocationHandler{},
+		Verification: &handler.VerificationHandler{},
+		Subscription: &handler.SubscriptionHandler{},
+		Digest:       &handler.DigestHandler{},
+	}, spec, Deprecation{
+		Since:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC),
+	}, zap.NewNop())
+
+	routed := make(map[string]bool)
+	for _, route := range router.Routes() {
+		routed[route.Method+" "+ginParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+	described := make(map[string]bool)
+	for _, op := range spec.Operations() {
+		described[op] = true
+	}
+
+	for route := range routed {
+		if !described[route] {
+			t.Errorf("%s is routed but not in the spec", route)
+		}
+	}
+	for op := range described {
+		if !routed[op] {
+			t.Errorf("%s is in the spec but not routed", op)
+		}
+	}
+}
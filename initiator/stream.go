@@ -0,0 +1,23 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/handler"
+	md "github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitStreamHandler builds the weather update stream handler from the stream
+// config section.
+func InitStreamHandler(weatherService md.WeatherService, bus *events.Bus, logger *zap.Logger) *handler.StreamHandler {
+	cfg := handler.StreamConfig{
+		Heartbeat: viper.GetDuration("stream.heartbeat"),
+		Refresh:   viper.GetDuration("stream.refresh_interval"),
+		Buffer:    viper.GetInt("stream.buffer"),
+	}
+	if cfg.Heartbeat <= 0 || cfg.Refresh <= 0 || cfg.Buffer <= 0 {
+		logger.Fatal("Invalid stream config", zap.Any("config", cfg))
+	}
+	return handler.NewStreamHandler(weatherService, bus, cfg, logger)
+}
@@ -0,0 +1,26 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/internal/verification"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitVerification builds the forecast verification worker from the
+// verification config section. It returns nil when verification is disabled.
+func InitVerification(repo *repository.WeatherRepository, logger *zap.Logger) *verification.Worker {
+	if !viper.GetBool("verification.enabled") {
+		logger.Info("Forecast verification disabled")
+		return nil
+	}
+
+	cfg := verification.Config{
+		Interval:   viper.GetDuration("verification.interval"),
+		WindowDays: viper.GetInt("verification.window_days"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid verification config", zap.Error(err))
+	}
+	return verification.NewWorker(repo, cfg, logger)
+}
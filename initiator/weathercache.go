@@ -0,0 +1,30 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/platform"
+	"github.com/Orion777-cmd/weather-app/platform/weathercache"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitWeatherCache wraps weatherAPI in the in-process cache configured by the
+// cache.memory section. weatherAPI is returned unchanged when the cache is
+// disabled.
+func InitWeatherCache(weatherAPI platform.WeatherAPI, logger *zap.Logger) platform.WeatherAPI {
+	if !viper.GetBool("cache.memory.enabled") {
+		logger.Info("In-process weather cache disabled")
+		return weatherAPI
+	}
+
+	cfg := weathercache.Config{
+		Capacity:  viper.GetInt("cache.memory.capacity"),
+		TTL:       viper.GetDuration("cache.memory.ttl"),
+		MaxAge:    viper.GetDuration("cache.memory.max_age"),
+		Precision: viper.GetInt("cache.memory.precision"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid in-process cache config", zap.Error(err))
+	}
+	logger.Info("In-process weather cache enabled", zap.Int("capacity", cfg.Capacity), zap.Duration("ttl", cfg.TTL), zap.Duration("max_age", cfg.MaxAge))
+	return weathercache.New(weatherAPI, cfg, logger)
+}
@@ -1,24 +1,63 @@
 package initiator
 
 import (
+	"context"
+
+	"github.com/Orion777-cmd/weather-app/internal/events"
 	md "github.com/Orion777-cmd/weather-app/internal/module"
-	"github.com/Orion777-cmd/weather-app/internal/repository"
 	"github.com/Orion777-cmd/weather-app/platform"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
+
 type WeatherAPI struct {
 	GeocodingBaseURL string
 	OneCallBaseURL   string
 }
+
 // WeatherModule implements the WeatherService interface.
 type module struct {
-	weatherModule  md.WeatherService
+	weatherModule md.WeatherService
+	// watchedModule is nil unless the storage backend is Postgres.
+	watchedModule md.WatchedLocationService
+	// verificationModule is nil unless the storage backend is Postgres.
+	verificationModule md.VerificationService
+	// subscriptionModule is nil unless the storage backend is Postgres.
+	subscriptionModule md.SubscriptionService
+	// digestModule is nil unless the storage backend is Postgres and digests
+	// are enabled.
+	digestModule md.DigestService
 }
 
 // InitWeatherModule initializes the weather module.
-func InitWeatherModule(_ Persistence, weatherAPI platform.WeatherAPI, weatherRepo repository.WeatherRepository, logger *zap.Logger) module {
+func InitWeatherModule(persistence Persistence, weatherAPI platform.WeatherAPI, bus *events.Bus, logger *zap.Logger) module {
 	logger.Info("Initializing weather module")
-	return module{
-		weatherModule: md.NewService(weatherAPI,&weatherRepo, logger),
+	var anomalies md.AnomalyDetector
+	if persistence.Postgres != nil {
+		if detector := InitAnomalyDetector(persistence.Postgres, logger); detector != nil {
+			anomalies = detector
+		}
+	}
+
+	m := module{
+		weatherModule: md.NewService(weatherAPI, persistence.Store, anomalies, bus, md.Config{
+			CacheTTL:    viper.GetDuration("cache.ttl"),
+			MaxAge:      viper.GetDuration("cache.max_age"),
+			MaxStaleAge: viper.GetDuration("cache.max_stale_age"),
+		}, logger),
+	}
+	if persistence.Postgres != nil {
+		m.watchedModule = md.NewWatchedLocationService(persistence.Postgres, logger)
+		m.verificationModule = md.NewVerificationService(persistence.Postgres, logger)
+		m.subscriptionModule = md.NewSubscriptionService(persistence.Postgres, logger)
+
+		// Digests are built from the weather service so they benefit from
+		// its cache.
+		if sender, worker := InitDigests(persistence.Postgres, m.weatherModule, logger); sender != nil {
+			m.digestModule = md.NewDigestService(persistence.Postgres, sender, logger)
+			logger.Info("Starting digest worker")
+			go worker.Run(context.Background())
+		}
 	}
-}
\ No newline at end of file
+	return m
+}
@@ -0,0 +1,37 @@
+package initiator
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/internal/webhook"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitWebhooks builds the subscription evaluator and the delivery dispatcher
+// from the webhooks config section. It returns nils when webhooks are
+// disabled.
+func InitWebhooks(repo *repository.WeatherRepository, bus *events.Bus, logger *zap.Logger) (*webhook.Evaluator, *webhook.Dispatcher) {
+	if !viper.GetBool("webhooks.enabled") {
+		logger.Info("Webhooks disabled")
+		return nil, nil
+	}
+
+	cfg := webhook.Config{
+		Interval:    viper.GetDuration("webhooks.interval"),
+		BatchSize:   viper.GetInt("webhooks.batch_size"),
+		Concurrency: viper.GetInt("webhooks.concurrency"),
+		Timeout:     viper.GetDuration("webhooks.timeout"),
+		MaxAttempts: viper.GetInt("webhooks.max_attempts"),
+		BackoffBase: viper.GetDuration("webhooks.backoff_base"),
+		BackoffMax:  viper.GetDuration("webhooks.backoff_max"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid webhooks config", zap.Error(err))
+	}
+	radiusKm := viper.GetFloat64("webhooks.match_radius_km")
+	if radiusKm < 0 {
+		logger.Fatal("Invalid webhooks config", zap.Float64("match_radius_km", radiusKm))
+	}
+	return webhook.NewEvaluator(repo, bus, radiusKm, logger), webhook.NewDispatcher(repo, cfg, logger)
+}
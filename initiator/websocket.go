@@ -0,0 +1,39 @@
+package initiator
+
+import (
+	"slices"
+
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/handler"
+	"github.com/Orion777-cmd/weather-app/internal/live"
+	md "github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// InitWebSocketHandler builds the live subscription handler from the
+// websocket config section.
+func InitWebSocketHandler(weatherService md.WeatherService, bus *events.Bus, logger *zap.Logger) *handler.WebSocketHandler {
+	cfg := live.Config{
+		MaxSubscriptions: viper.GetInt("websocket.max_subscriptions"),
+		MaxFetches:       viper.GetInt("websocket.max_fetches"),
+		SendBuffer:       viper.GetInt("websocket.send_buffer"),
+		EventBuffer:      viper.GetInt("websocket.event_buffer"),
+		MatchRadiusKm:    viper.GetFloat64("websocket.match_radius_km"),
+		MaxMessageSize:   viper.GetInt64("websocket.max_message_size"),
+		PingInterval:     viper.GetDuration("websocket.ping_interval"),
+		PongTimeout:      viper.GetDuration("websocket.pong_timeout"),
+		WriteTimeout:     viper.GetDuration("websocket.write_timeout"),
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Invalid websocket config", zap.Error(err))
+	}
+
+	var checkOrigin func(string) bool
+	if origins := viper.GetStringSlice("websocket.allowed_origins"); len(origins) > 0 {
+		checkOrigin = func(origin string) bool {
+			return slices.Contains(origins, "*") || slices.Contains(origins, origin)
+		}
+	}
+	return handler.NewWebSocketHandler(live.NewServer(weatherService, bus, cfg, logger), checkOrigin, logger)
+}
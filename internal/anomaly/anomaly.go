@@ -0,0 +1,158 @@
+// Package anomaly flags current conditions that are unusual for a location.
+// The baseline is the stored history near the location for the same part of
+// the year, and each value is scored by how many standard deviations it lies
+// from the baseline mean. Baselines are built from history stored before the
+// current day, so they are computed once per location and day.
+package anomaly
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Config controls what counts as the baseline and as unusual.
+type Config struct {
+	// WindowDays is how many days either side of today's date, in any year,
+	// the baseline draws from.
+	WindowDays int
+	// RadiusKm is how close stored queries must be to count for a location.
+	RadiusKm float64
+	// MinSamples is the number of days of history needed before any score
+	// or flag is given.
+	MinSamples int
+	// Threshold is the absolute z-score at which a value is flagged.
+	Threshold float64
+	// CacheEntries is how many location baselines are kept for the day.
+	CacheEntries int
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.WindowDays, validation.Required, validation.Min(1), validation.Max(182)),
+		validation.Field(&c.RadiusKm, validation.Required, validation.Min(0.0)),
+		validation.Field(&c.MinSamples, validation.Required, validation.Min(2)),
+		validation.Field(&c.Threshold, validation.Required, validation.Min(0.0)),
+		validation.Field(&c.CacheEntries, validation.Required, validation.Min(1)),
+	)
+}
+
+// BaselineStore summarizes stored history. *repository.WeatherRepository
+// implements it.
+type BaselineStore interface {
+	GetBaseline(ctx context.Context, at time.Time, coordinate models.Location, radiusKm float64, windowDays int) (models.Baseline, error)
+}
+
+// Detector scores responses against their location's baseline.
+type Detector struct {
+	store BaselineStore
+	cfg   Config
+
+	mu sync.Mutex
+	// day is the UTC day the cached baselines were built for.
+	day       time.Time
+	baselines map[models.Location]models.Baseline
+}
+
+func NewDetector(store BaselineStore, cfg Config) *Detector {
+	return &Detector{store: store, cfg: cfg}
+}
+
+// flag is the wording used when a value is unusually high or low. An empty
+// wording means that direction is not flagged.
+type flag struct {
+	high, low string
+}
+
+var (
+	tempFlag      = flag{high: "unusually warm for this location", low: "unusually cold for this location"}
+	precipFlag    = flag{high: "unusually wet for this location"}
+	windspeedFlag = flag{high: "unusually windy for this location", low: "unusually calm for this location"}
+)
+
+// Detect compares the current conditions in weather, its first day, with the
+// history stored before the day it was fetched. It returns nil if weather has no
+// current conditions.
+func (d *Detector) Detect(ctx context.Context, weather models.WeatherResponse) (*models.Anomalies, error) {
+	if len(weather.Days) == 0 {
+		return nil, nil
+	}
+	at := weather.FetchedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	coordinate := models.Location{Latitude: weather.Latitude, Longitude: weather.Longitude}
+	baseline, err := d.baseline(ctx, at, coordinate)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := &models.Anomalies{
+		Samples:    baseline.Samples,
+		WindowDays: d.cfg.WindowDays,
+		Flags:      []string{},
+	}
+	if baseline.Samples < d.cfg.MinSamples {
+		return anomalies, nil
+	}
+
+	current := weather.Days[0]
+	anomalies.Temp = d.score(anomalies, float64(current.Temp), baseline.TempMean, baseline.TempStddev, tempFlag)
+	anomalies.Precip = d.score(anomalies, float64(current.Precip), baseline.PrecipMean, baseline.PrecipStddev, precipFlag)
+	anomalies.Windspeed = d.score(anomalies, float64(current.Windspeed), baseline.WindspeedMean, baseline.WindspeedStddev, windspeedFlag)
+	return anomalies, nil
+}
+
+// baseline returns the baseline for coordinate on the UTC day of at, built
+// from the history stored before that day. Baselines are cached by rounded
+// coordinate until the day changes; a full cache is emptied.
+func (d *Detector) baseline(ctx context.Context, at time.Time, coordinate models.Location) (models.Baseline, error) {
+	day := at.UTC().Truncate(24 * time.Hour)
+	key := models.Location{
+		Latitude:  repository.RoundCoordinate(coordinate.Latitude),
+		Longitude: repository.RoundCoordinate(coordinate.Longitude),
+	}
+
+	d.mu.Lock()
+	if !day.Equal(d.day) || len(d.baselines) >= d.cfg.CacheEntries {
+		d.day, d.baselines = day, make(map[models.Location]models.Baseline)
+	}
+	baseline, ok := d.baselines[key]
+	d.mu.Unlock()
+	if ok {
+		return baseline, nil
+	}
+
+	baseline, err := d.store.GetBaseline(ctx, day, key, d.cfg.RadiusKm, d.cfg.WindowDays)
+	if err != nil {
+		return models.Baseline{}, err
+	}
+	d.mu.Lock()
+	if day.Equal(d.day) {
+		d.baselines[key] = baseline
+	}
+	d.mu.Unlock()
+	return baseline, nil
+}
+
+// score computes the z-score of value and adds a flag to anomalies if it is
+// past the threshold. It returns nil if the baseline has no spread.
+func (d *Detector) score(anomalies *models.Anomalies, value, mean, stddev float64, f flag) *models.AnomalyScore {
+	if stddev <= 0 {
+		return nil
+	}
+
+	z := (value - mean) / stddev
+	switch {
+	case z >= d.cfg.Threshold && f.high != "":
+		anomalies.Flags = append(anomalies.Flags, f.high)
+	case z <= -d.cfg.Threshold && f.low != "":
+		anomalies.Flags = append(anomalies.Flags, f.low)
+	}
+	return &models.AnomalyScore{Value: value, Mean: mean, Stddev: stddev, ZScore: z}
+}
@@ -0,0 +1,200 @@
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// fakeStore returns baseline for every lookup and records them.
+type fakeStore struct {
+	baseline models.Baseline
+	err      error
+	calls    []time.Time
+}
+
+func (s *fakeStore) GetBaseline(ctx context.Context, at time.Time, coordinate models.Location, radiusKm float64, windowDays int) (models.Baseline, error) {
+	s.calls = append(s.calls, at)
+	return s.baseline, s.err
+}
+
+var testConfig = Config{WindowDays: 15, RadiusKm: 25, MinSamples: 10, Threshold: 2, CacheEntries: 100}
+
+// testBaseline has a temperature of 10±2, precipitation of 1±0.5 and wind
+// speed of 20±5.
+var testBaseline = models.Baseline{
+	Samples:  30,
+	TempMean: 10, TempStddev: 2,
+	PrecipMean: 1, PrecipStddev: 0.5,
+	WindspeedMean: 20, WindspeedStddev: 5,
+}
+
+func current(temp, precip, windspeed float32) models.WeatherResponse {
+	return models.WeatherResponse{
+		Latitude:  52.52,
+		Longitude: 13.405,
+		FetchedAt: time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC),
+		Days:      []models.Weather{{Temp: temp, Precip: precip, Windspeed: windspeed}},
+	}
+}
+
+func TestDetectFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		weather  models.WeatherResponse
+		baseline models.Baseline
+		wantZ    [3]float64
+		flags    []string
+	}{
+		{
+			name:     "typical",
+			weather:  current(11, 1, 20),
+			baseline: testBaseline,
+			wantZ:    [3]float64{0.5, 0, 0},
+			flags:    []string{},
+		},
+		{
+			name:     "warm wet windy",
+			weather:  current(15, 2.5, 31),
+			baseline: testBaseline,
+			wantZ:    [3]float64{2.5, 3, 2.2},
+			flags:    []string{tempFlag.high, precipFlag.high, windspeedFlag.high},
+		},
+		{
+			name:     "cold dry calm",
+			weather:  current(5, 0, 9),
+			baseline: testBaseline,
+			wantZ:    [3]float64{-2.5, -2, -2.2},
+			// Dry is not flagged.
+			flags: []string{tempFlag.low, windspeedFlag.low},
+		},
+		{
+			name:     "at the threshold",
+			weather:  current(14, 1, 10),
+			baseline: testBaseline,
+			wantZ:    [3]float64{2, 0, -2},
+			flags:    []string{tempFlag.high, windspeedFlag.low},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector(&fakeStore{baseline: tt.baseline}, testConfig)
+			anomalies, err := d.Detect(context.Background(), tt.weather)
+			if err != nil {
+				t.Fatalf("Detect: %v", err)
+			}
+			scores := []*models.AnomalyScore{anomalies.Temp, anomalies.Precip, anomalies.Windspeed}
+			for i, score := range scores {
+				if score == nil {
+					t.Fatalf("score %d missing", i)
+				}
+				if math.Abs(score.ZScore-tt.wantZ[i]) > 1e-6 {
+					t.Errorf("score %d: z = %v, want %v", i, score.ZScore, tt.wantZ[i])
+				}
+			}
+			if !slices.Equal(anomalies.Flags, tt.flags) {
+				t.Errorf("flags = %q, want %q", anomalies.Flags, tt.flags)
+			}
+			if anomalies.Samples != tt.baseline.Samples || anomalies.WindowDays != testConfig.WindowDays {
+				t.Errorf("samples %d, window %d", anomalies.Samples, anomalies.WindowDays)
+			}
+		})
+	}
+}
+
+func TestDetectTooFewSamples(t *testing.T) {
+	baseline := testBaseline
+	baseline.Samples = testConfig.MinSamples - 1
+	d := NewDetector(&fakeStore{baseline: baseline}, testConfig)
+
+	anomalies, err := d.Detect(context.Background(), current(30, 10, 60))
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if anomalies.Temp != nil || anomalies.Precip != nil || anomalies.Windspeed != nil || len(anomalies.Flags) != 0 {
+		t.Errorf("scored with %d samples: %+v", baseline.Samples, anomalies)
+	}
+	if anomalies.Samples != baseline.Samples {
+		t.Errorf("samples = %d, want %d", anomalies.Samples, baseline.Samples)
+	}
+}
+
+func TestDetectNoSpread(t *testing.T) {
+	baseline := testBaseline
+	baseline.PrecipStddev = 0
+	d := NewDetector(&fakeStore{baseline: baseline}, testConfig)
+
+	anomalies, err := d.Detect(context.Background(), current(10, 5, 20))
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if anomalies.Precip != nil {
+		t.Errorf("precip scored against a baseline without spread: %+v", *anomalies.Precip)
+	}
+	if anomalies.Temp == nil || len(anomalies.Flags) != 0 {
+		t.Errorf("anomalies = %+v", anomalies)
+	}
+}
+
+func TestDetectNoCurrentConditions(t *testing.T) {
+	store := &fakeStore{baseline: testBaseline}
+	anomalies, err := NewDetector(store, testConfig).Detect(context.Background(), models.WeatherResponse{})
+	if err != nil || anomalies != nil {
+		t.Errorf("got %+v, %v; want nil, nil", anomalies, err)
+	}
+	if len(store.calls) != 0 {
+		t.Errorf("looked up a baseline for an empty response")
+	}
+}
+
+func TestDetectCachesBaselinePerDay(t *testing.T) {
+	store := &fakeStore{baseline: testBaseline}
+	d := NewDetector(store, testConfig)
+	ctx := context.Background()
+
+	morning := current(11, 1, 20)
+	morning.FetchedAt = time.Date(2026, 7, 1, 6, 0, 0, 0, time.UTC)
+	evening := current(12, 1, 20)
+	evening.FetchedAt = time.Date(2026, 7, 1, 22, 0, 0, 0, time.UTC)
+	// Within the rounded precision of the morning's location.
+	evening.Latitude += 0.00001
+	nextDay := current(11, 1, 20)
+	nextDay.FetchedAt = time.Date(2026, 7, 2, 1, 0, 0, 0, time.UTC)
+
+	for _, weather := range []models.WeatherResponse{morning, evening, nextDay} {
+		if _, err := d.Detect(ctx, weather); err != nil {
+			t.Fatalf("Detect: %v", err)
+		}
+	}
+
+	want := []time.Time{
+		time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 7, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if !slices.EqualFunc(store.calls, want, time.Time.Equal) {
+		t.Errorf("baselines looked up at %v, want once per day at %v", store.calls, want)
+	}
+}
+
+func TestDetectDoesNotCacheErrors(t *testing.T) {
+	store := &fakeStore{err: errors.New("connection refused")}
+	d := NewDetector(store, testConfig)
+	ctx := context.Background()
+
+	if _, err := d.Detect(ctx, current(11, 1, 20)); err == nil {
+		t.Fatal("store error not returned")
+	}
+	store.err, store.baseline = nil, testBaseline
+	anomalies, err := d.Detect(ctx, current(11, 1, 20))
+	if err != nil || anomalies.Temp == nil {
+		t.Errorf("after recovery: %+v, %v", anomalies, err)
+	}
+	if len(store.calls) != 2 {
+		t.Errorf("store called %d times, want 2", len(store.calls))
+	}
+}
@@ -0,0 +1,42 @@
+// Package apierror maps the errors services return to the status codes and
+// error bodies clients see, so every transport reports a failure the same way.
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/expr"
+)
+
+// Response returns the HTTP status and body for the kind of err. Server side
+// failures are described by their kind only, so internal details are logged
+// but not sent.
+func Response(err error) (int, models.ErrorResponse) {
+	var ambiguous *models.AmbiguousLocationError
+	switch {
+	case errors.Is(err, models.ErrInvalidRequest):
+		body := models.ErrorResponse{Code: models.CodeInvalidRequest, Error: err.Error()}
+		var exprErr *expr.Error
+		if errors.As(err, &exprErr) {
+			body.Column = exprErr.Column
+		}
+		return http.StatusBadRequest, body
+	case errors.Is(err, models.ErrLocationNotFound):
+		return http.StatusNotFound, models.ErrorResponse{Code: models.CodeLocationNotFound, Error: err.Error()}
+	case errors.As(err, &ambiguous):
+		return http.StatusMultipleChoices, models.ErrorResponse{Code: models.CodeAmbiguousLocation, Error: err.Error(), Candidates: ambiguous.Candidates}
+	case errors.Is(err, models.ErrWatchedLocationNotFound), errors.Is(err, models.ErrSubscriptionNotFound), errors.Is(err, models.ErrDigestNotFound):
+		return http.StatusNotFound, models.ErrorResponse{Code: models.CodeNotFound, Error: err.Error()}
+	case errors.Is(err, models.ErrDigestUnsubscribed), errors.Is(err, models.ErrDigestNotConfirmed):
+		return http.StatusConflict, models.ErrorResponse{Code: models.CodeConflict, Error: err.Error()}
+	case errors.Is(err, models.ErrUpstreamRateLimited):
+		return http.StatusServiceUnavailable, models.ErrorResponse{Code: models.CodeUpstreamRateLimited, Error: err.Error()}
+	case errors.Is(err, models.ErrUpstreamUnavailable):
+		return http.StatusBadGateway, models.ErrorResponse{Code: models.CodeUpstreamUnavailable, Error: models.ErrUpstreamUnavailable.Error()}
+	case errors.Is(err, models.ErrStorage):
+		return http.StatusInternalServerError, models.ErrorResponse{Code: models.CodeStorageFailure, Error: models.ErrStorage.Error()}
+	}
+	return http.StatusInternalServerError, models.ErrorResponse{Code: models.CodeInternal, Error: "internal error"}
+}
@@ -0,0 +1,35 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+func TestResponse(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{models.InvalidRequest(errors.New("bad")), http.StatusBadRequest, models.CodeInvalidRequest},
+		{fmt.Errorf("%w: %q", models.ErrLocationNotFound, "Atlantis"), http.StatusNotFound, models.CodeLocationNotFound},
+		{&models.AmbiguousLocationError{City: "Paris"}, http.StatusMultipleChoices, models.CodeAmbiguousLocation},
+		{models.ErrWatchedLocationNotFound, http.StatusNotFound, models.CodeNotFound},
+		{models.ErrSubscriptionNotFound, http.StatusNotFound, models.CodeNotFound},
+		{fmt.Errorf("get: %w", models.ErrDigestNotFound), http.StatusNotFound, models.CodeNotFound},
+		{models.ErrDigestUnsubscribed, http.StatusConflict, models.CodeConflict},
+		{models.ErrDigestNotConfirmed, http.StatusConflict, models.CodeConflict},
+		{models.ErrUpstreamUnavailable, http.StatusBadGateway, models.CodeUpstreamUnavailable},
+		{fmt.Errorf("connection refused"), http.StatusInternalServerError, models.CodeInternal},
+	}
+	for _, tt := range tests {
+		status, body := Response(tt.err)
+		if status != tt.status || body.Code != tt.code {
+			t.Errorf("%v: got %d %s, want %d %s", tt.err, status, body.Code, tt.status, tt.code)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Package compare lines up the weather of several cities so they can be read
+// side by side: daily and hourly series on common dates and hours, the
+// differences from a baseline city and rankings.
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// DateLayout is the layout of comparison dates.
+const DateLayout = "2006-01-02"
+
+// ErrNoCommonForecast is returned when no forecast from the comparison's
+// date on covers every city.
+var ErrNoCommonForecast = errors.New("no forecast covers every city")
+
+// now is the current time, replaced in tests.
+var now = time.Now
+
+// Compare compares the results fetched for cities, which must be in the same
+// order. baseline indexes the city the others are compared to; its result
+// must have weather. Cities that failed are listed with their error only.
+// An empty date means today in the baseline's time zone.
+func Compare(date string, cities []string, results []models.BatchResult, baseline int) (models.Comparison, error) {
+	base := results[baseline].Weather
+	baseLoc := location(*base)
+	if date == "" {
+		date = now().In(baseLoc).Format(DateLayout)
+	}
+	day, err := time.ParseInLocation(DateLayout, date, baseLoc)
+	if err != nil {
+		return models.Comparison{}, err
+	}
+
+	comparison := models.Comparison{
+		Date:      date,
+		Baseline:  cities[baseline],
+		Units:     models.ResponseUnits,
+		Locations: make([]models.ComparedLocation, len(cities)),
+	}
+	var daily, hourly []map[string]models.Weather
+	var compared []int
+	for i, r := range results {
+		l := &comparison.Locations[i]
+		l.City, l.Status, l.Error = cities[i], r.Status, r.Error
+		if r.Weather == nil {
+			continue
+		}
+		l.Address, l.Country, l.Timezone = r.Weather.Address, r.Weather.Country, r.Weather.Timezone
+		compared = append(compared, i)
+		daily = append(daily, days(*r.Weather))
+		hourly = append(hourly, hours(*r.Weather))
+	}
+
+	comparison.Days = common(daily, func(key string) bool { return key >= date })
+	if len(comparison.Days) == 0 {
+		return models.Comparison{}, fmt.Errorf("%w from %s", ErrNoCommonForecast, date)
+	}
+	// UTC RFC3339 keys sort in time order.
+	start, end := day.UTC().Format(time.RFC3339), day.AddDate(0, 0, 1).UTC().Format(time.RFC3339)
+	hourKeys := common(hourly, func(key string) bool { return key >= start && key < end })
+	comparison.Hours = make([]time.Time, 0, len(hourKeys))
+	for _, key := range hourKeys {
+		t, _ := time.Parse(time.RFC3339, key)
+		comparison.Hours = append(comparison.Hours, t.In(baseLoc))
+	}
+
+	baseIndex := sort.SearchInts(compared, baseline)
+	for n, i := range compared {
+		l := &comparison.Locations[i]
+		l.Daily = series(comparison.Days, daily[n], daily[baseIndex])
+		l.Hourly = series(hourKeys, hourly[n], hourly[baseIndex])
+	}
+	comparison.Rankings = rank(comparison.Locations, compared)
+	return comparison, nil
+}
+
+// location returns the time zone of weather, or UTC if it is unknown.
+func location(weather models.WeatherResponse) *time.Location {
+	loc, err := time.LoadLocation(weather.Timezone)
+	if err != nil || weather.Timezone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+// days returns the forecast days of weather by their date in its time zone.
+// The first day holds the current conditions and is left out.
+func days(weather models.WeatherResponse) map[string]models.Weather {
+	loc := location(weather)
+	out := make(map[string]models.Weather)
+	if len(weather.Days) < 2 {
+		return out
+	}
+	for _, d := range weather.Days[1:] {
+		if t, err := models.ParseDatetime(d.Datetime); err == nil {
+			out[t.In(loc).Format(DateLayout)] = d
+		}
+	}
+	return out
+}
+
+// hours returns the hourly forecast of weather by its UTC time.
+func hours(weather models.WeatherResponse) map[string]models.Weather {
+	out := make(map[string]models.Weather)
+	if len(weather.Days) == 0 {
+		return out
+	}
+	for _, h := range weather.Days[0].Hours {
+		if t, err := models.ParseDatetime(h.Datetime); err == nil {
+			out[t.UTC().Format(time.RFC3339)] = h
+		}
+	}
+	return out
+}
+
+// common returns the sorted keys that every one of sets has and keep accepts.
+func common(sets []map[string]models.Weather, keep func(string) bool) []string {
+	var keys []string
+	for key := range sets[0] {
+		if !keep(key) {
+			continue
+		}
+		shared := true
+		for _, set := range sets[1:] {
+			if _, ok := set[key]; !ok {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// series returns the values of weather at keys, with their differences from
+// base.
+func series(keys []string, weather, base map[string]models.Weather) []models.ComparedValues {
+	out := make([]models.ComparedValues, len(keys))
+	for i, key := range keys {
+		w, b := weather[key], base[key]
+		out[i] = models.ComparedValues{
+			Temp:      w.Temp,
+			Tempmin:   w.Tempmin,
+			Tempmax:   w.Tempmax,
+			Humidity:  w.Humidity,
+			Precip:    w.Precip,
+			Windspeed: w.Windspeed,
+			Delta: models.WeatherDelta{
+				Temp:      w.Temp - b.Temp,
+				Humidity:  w.Humidity - b.Humidity,
+				Precip:    w.Precip - b.Precip,
+				Windspeed: w.Windspeed - b.Windspeed,
+			},
+		}
+	}
+	return out
+}
+
+// rank orders the compared locations by their first day. Ties keep the
+// order the cities were given in.
+func rank(locations []models.ComparedLocation, compared []int) models.Rankings {
+	by := func(less func(a, b models.ComparedValues) bool) []string {
+		order := append([]int(nil), compared...)
+		sort.SliceStable(order, func(i, j int) bool {
+			return less(locations[order[i]].Daily[0], locations[order[j]].Daily[0])
+		})
+		cities := make([]string, len(order))
+		for k, i := range order {
+			cities[k] = locations[i].City
+		}
+		return cities
+	}
+	return models.Rankings{
+		Temp:      by(func(a, b models.ComparedValues) bool { return a.Temp > b.Temp }),
+		Precip:    by(func(a, b models.ComparedValues) bool { return a.Precip < b.Precip }),
+		Windspeed: by(func(a, b models.ComparedValues) bool { return a.Windspeed < b.Windspeed }),
+	}
+}
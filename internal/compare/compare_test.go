@@ -0,0 +1,270 @@
+package compare
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+func TestCommon(t *testing.T) {
+	set := func(keys ...string) map[string]models.Weather {
+		m := make(map[string]models.Weather)
+		for _, k := range keys {
+			m[k] = models.Weather{}
+		}
+		return m
+	}
+	all := func(string) bool { return true }
+	tests := []struct {
+		name string
+		sets []map[string]models.Weather
+		keep func(string) bool
+		want []string
+	}{
+		{"one set", []map[string]models.Weather{set("b", "a")}, all, []string{"a", "b"}},
+		{"intersection sorted", []map[string]models.Weather{set("c", "a", "b"), set("b", "c", "d"), set("a", "b", "c")}, all, []string{"b", "c"}},
+		{"filtered", []map[string]models.Weather{set("2026-10-19", "2026-10-20", "2026-10-21"), set("2026-10-19", "2026-10-20")}, func(k string) bool { return k >= "2026-10-20" }, []string{"2026-10-20"}},
+		{"disjoint", []map[string]models.Weather{set("a"), set("b")}, all, nil},
+		{"empty set", []map[string]models.Weather{set("a"), set()}, all, nil},
+	}
+	for _, tt := range tests {
+		if got := common(tt.sets, tt.keep); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSeries(t *testing.T) {
+	weather := map[string]models.Weather{
+		"a": {Temp: 20, Tempmin: 15, Tempmax: 24, Humidity: 60, Precip: 1, Windspeed: 3},
+		"b": {Temp: 10, Humidity: 90, Precip: 4, Windspeed: 8},
+	}
+	base := map[string]models.Weather{
+		"a": {Temp: 18, Humidity: 70, Precip: 0, Windspeed: 5},
+		"b": {Temp: 12, Humidity: 80, Precip: 1, Windspeed: 8},
+	}
+	got := series([]string{"b", "a"}, weather, base)
+	want := []models.ComparedValues{
+		{Temp: 10, Humidity: 90, Precip: 4, Windspeed: 8, Delta: models.WeatherDelta{Temp: -2, Humidity: 10, Precip: 3, Windspeed: 0}},
+		{Temp: 20, Tempmin: 15, Tempmax: 24, Humidity: 60, Precip: 1, Windspeed: 3, Delta: models.WeatherDelta{Temp: 2, Humidity: -10, Precip: 1, Windspeed: -2}},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("series:\n got %+v\nwant %+v", got, want)
+	}
+
+	// Against itself every delta is zero.
+	for _, v := range series([]string{"a", "b"}, base, base) {
+		if v.Delta != (models.WeatherDelta{}) {
+			t.Errorf("delta against itself: %+v", v.Delta)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	first := func(city string, temp, precip, wind float32) models.ComparedLocation {
+		return models.ComparedLocation{City: city, Daily: []models.ComparedValues{{Temp: temp, Precip: precip, Windspeed: wind}}}
+	}
+	tests := []struct {
+		name      string
+		locations []models.ComparedLocation
+		compared  []int
+		want      models.Rankings
+	}{
+		{
+			name:      "ordered",
+			locations: []models.ComparedLocation{first("Oslo", 5, 2, 9), first("Rome", 22, 0, 3), first("Berlin", 12, 1, 6)},
+			compared:  []int{0, 1, 2},
+			want: models.Rankings{
+				Temp:      []string{"Rome", "Berlin", "Oslo"},
+				Precip:    []string{"Rome", "Berlin", "Oslo"},
+				Windspeed: []string{"Rome", "Berlin", "Oslo"},
+			},
+		},
+		{
+			name:      "ties keep the given order",
+			locations: []models.ComparedLocation{first("Oslo", 10, 1, 4), first("Rome", 10, 1, 4), first("Berlin", 12, 1, 2)},
+			compared:  []int{0, 1, 2},
+			want: models.Rankings{
+				Temp:      []string{"Berlin", "Oslo", "Rome"},
+				Precip:    []string{"Oslo", "Rome", "Berlin"},
+				Windspeed: []string{"Berlin", "Oslo", "Rome"},
+			},
+		},
+		{
+			name:      "failed cities are left out",
+			locations: []models.ComparedLocation{first("Oslo", 5, 2, 9), {City: "Atlantis"}, first("Rome", 22, 0, 3)},
+			compared:  []int{0, 2},
+			want: models.Rankings{
+				Temp:      []string{"Rome", "Oslo"},
+				Precip:    []string{"Rome", "Oslo"},
+				Windspeed: []string{"Rome", "Oslo"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := rank(tt.locations, tt.compared)
+		if !slices.Equal(got.Temp, tt.want.Temp) || !slices.Equal(got.Precip, tt.want.Precip) || !slices.Equal(got.Windspeed, tt.want.Windspeed) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// forecast builds a response as the provider does: Days[0] holds the current
+// conditions and the hourly forecast, the rest one day each, timed at noon
+// local time. Day i has temperature temps[i], and every hour from firstHour
+// on has its UTC hour of day as temperature.
+func forecast(t *testing.T, tz, firstDay string, temps []float32, firstHour time.Time, hours int) *models.WeatherResponse {
+	t.Helper()
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day, err := time.ParseInLocation(DateLayout, firstDay, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := models.Weather{Datetime: models.FormatDatetime(firstHour)}
+	for i := range hours {
+		at := firstHour.Add(time.Duration(i) * time.Hour)
+		current.Hours = append(current.Hours, models.Weather{Datetime: models.FormatDatetime(at), Temp: float32(at.UTC().Hour())})
+	}
+	w := &models.WeatherResponse{Address: tz, Timezone: tz, Days: []models.Weather{current}}
+	for i, temp := range temps {
+		noon := day.AddDate(0, 0, i).Add(12 * time.Hour)
+		w.Days = append(w.Days, models.Weather{Datetime: models.FormatDatetime(noon), Temp: temp, Precip: float32(i)})
+	}
+	return w
+}
+
+func ok(w *models.WeatherResponse) models.BatchResult {
+	return models.BatchResult{Status: http.StatusOK, Weather: w}
+}
+
+func TestCompareTimeZones(t *testing.T) {
+	utc := func(s string) time.Time {
+		at, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+	cities := []string{"Berlin", "New York", "Tokyo"}
+	results := []models.BatchResult{
+		ok(forecast(t, "Europe/Berlin", "2026-10-19", []float32{10, 11, 12, 13}, utc("2026-10-19T00:00:00Z"), 72)),
+		ok(forecast(t, "America/New_York", "2026-10-19", []float32{15, 16, 17}, utc("2026-10-19T12:00:00Z"), 48)),
+		// Tokyo's hourly forecast ends at noon UTC on the 20th.
+		ok(forecast(t, "Asia/Tokyo", "2026-10-20", []float32{20, 21, 22}, utc("2026-10-19T20:00:00Z"), 17)),
+	}
+
+	c, err := Compare("2026-10-20", cities, results, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// New York's forecast ends on the 21st in its own time zone.
+	if want := []string{"2026-10-20", "2026-10-21"}; !slices.Equal(c.Days, want) {
+		t.Errorf("days %v, want %v", c.Days, want)
+	}
+	// The 20th in Berlin runs from 22:00 UTC on the 19th; Tokyo has hours
+	// until 12:00 UTC on the 20th.
+	if len(c.Hours) != 15 {
+		t.Fatalf("%d hours, want 15: %v", len(c.Hours), c.Hours)
+	}
+	if first, last := c.Hours[0], c.Hours[len(c.Hours)-1]; !first.Equal(utc("2026-10-19T22:00:00Z")) || !last.Equal(utc("2026-10-20T12:00:00Z")) {
+		t.Errorf("hours from %v to %v", first, last)
+	}
+	if name := c.Hours[0].Location().String(); name != "Europe/Berlin" {
+		t.Errorf("hours in %s, want the baseline's time zone", name)
+	}
+
+	for i, l := range c.Locations {
+		if len(l.Daily) != len(c.Days) || len(l.Hourly) != len(c.Hours) {
+			t.Fatalf("%s: %d days, %d hours", l.City, len(l.Daily), len(l.Hourly))
+		}
+		// Hours line up by instant, so every city has the same value.
+		for n, h := range l.Hourly {
+			if want := float32(c.Hours[n].UTC().Hour()); h.Temp != want || h.Delta.Temp != 0 {
+				t.Errorf("%s at %v: temp %v delta %v, want %v and 0", l.City, c.Hours[n], h.Temp, h.Delta.Temp, want)
+			}
+		}
+		// Days line up by each city's own date.
+		want := []float32{11, 16, 20}[i]
+		if got := l.Daily[0].Temp; got != want {
+			t.Errorf("%s on the 20th: temp %v, want %v", l.City, got, want)
+		}
+		if got := l.Daily[0].Delta.Temp; got != want-11 {
+			t.Errorf("%s on the 20th: delta %v, want %v", l.City, got, want-11)
+		}
+	}
+	if want := []string{"Tokyo", "New York", "Berlin"}; !slices.Equal(c.Rankings.Temp, want) {
+		t.Errorf("temp ranking %v, want %v", c.Rankings.Temp, want)
+	}
+}
+
+func TestCompareFailedCity(t *testing.T) {
+	start := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	notFound := &models.ErrorResponse{Code: models.CodeLocationNotFound, Error: `location not found: "Atlantis"`}
+	results := []models.BatchResult{
+		ok(forecast(t, "UTC", "2026-10-19", []float32{10, 11}, start, 48)),
+		{Status: http.StatusNotFound, Error: notFound},
+		ok(forecast(t, "UTC", "2026-10-19", []float32{20, 21}, start, 48)),
+	}
+
+	c, err := Compare("2026-10-19", []string{"Berlin", "Atlantis", "Rome"}, results, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := c.Locations[1]
+	if failed.Status != http.StatusNotFound || failed.Error != notFound || failed.Daily != nil || failed.Hourly != nil {
+		t.Errorf("failed city: %+v", failed)
+	}
+	if len(c.Days) != 2 || len(c.Hours) != 24 {
+		t.Errorf("%d days and %d hours, want 2 and 24", len(c.Days), len(c.Hours))
+	}
+	if want := []string{"Rome", "Berlin"}; !slices.Equal(c.Rankings.Temp, want) {
+		t.Errorf("temp ranking %v, want %v", c.Rankings.Temp, want)
+	}
+	if got := c.Locations[2].Daily[0].Delta.Temp; got != 10 {
+		t.Errorf("Rome delta %v, want 10", got)
+	}
+}
+
+func TestCompareNoCommonForecast(t *testing.T) {
+	start := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	results := []models.BatchResult{
+		ok(forecast(t, "UTC", "2026-10-19", []float32{10, 11, 12}, start, 24)),
+		ok(forecast(t, "UTC", "2026-10-19", []float32{20}, start, 24)),
+	}
+	for _, date := range []string{"2026-10-20", "2026-11-01"} {
+		if _, err := Compare(date, []string{"Berlin", "Rome"}, results, 0); !errors.Is(err, ErrNoCommonForecast) {
+			t.Errorf("%s: got %v, want ErrNoCommonForecast", date, err)
+		}
+	}
+}
+
+func TestCompareDefaultDate(t *testing.T) {
+	defer func(saved func() time.Time) { now = saved }(now)
+	// Already the 20th in Tokyo, still the 19th in New York.
+	now = func() time.Time { return time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC) }
+
+	start := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct{ tz, want string }{
+		{"Asia/Tokyo", "2026-10-20"},
+		{"America/New_York", "2026-10-19"},
+	} {
+		results := []models.BatchResult{
+			ok(forecast(t, tt.tz, "2026-10-18", []float32{1, 2, 3, 4}, start, 48)),
+			ok(forecast(t, tt.tz, "2026-10-18", []float32{5, 6, 7, 8}, start, 48)),
+		}
+		c, err := Compare("", []string{"A", "B"}, results, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.tz, err)
+		}
+		if c.Date != tt.want || c.Days[0] != tt.want {
+			t.Errorf("%s: date %s, first day %s, want %s", tt.tz, c.Date, c.Days[0], tt.want)
+		}
+	}
+}
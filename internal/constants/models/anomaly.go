@@ -0,0 +1,34 @@
+package models
+
+// Baseline is the typical current conditions at a location for a time of
+// year, from stored history. Samples is the number of days it is built from.
+type Baseline struct {
+	Samples         int     `json:"samples" bson:"samples"`
+	TempMean        float64 `json:"temp_mean" bson:"temp_mean"`
+	TempStddev      float64 `json:"temp_stddev" bson:"temp_stddev"`
+	PrecipMean      float64 `json:"precip_mean" bson:"precip_mean"`
+	PrecipStddev    float64 `json:"precip_stddev" bson:"precip_stddev"`
+	WindspeedMean   float64 `json:"windspeed_mean" bson:"windspeed_mean"`
+	WindspeedStddev float64 `json:"windspeed_stddev" bson:"windspeed_stddev"`
+}
+
+// AnomalyScore compares one current value against its baseline.
+type AnomalyScore struct {
+	Value  float64 `json:"value" bson:"value"`
+	Mean   float64 `json:"mean" bson:"mean"`
+	Stddev float64 `json:"stddev" bson:"stddev"`
+	ZScore float64 `json:"z_score" bson:"z_score"`
+}
+
+// Anomalies describes how the current conditions in a response compare with
+// the location's baseline. Scores and flags are only present once the
+// baseline has enough samples; a score is also left out when the baseline
+// shows no variation at all.
+type Anomalies struct {
+	Samples    int           `json:"samples" bson:"samples"`
+	WindowDays int           `json:"window_days" bson:"window_days"`
+	Temp       *AnomalyScore `json:"temp,omitempty" bson:"temp,omitempty"`
+	Precip     *AnomalyScore `json:"precip,omitempty" bson:"precip,omitempty"`
+	Windspeed  *AnomalyScore `json:"windspeed,omitempty" bson:"windspeed,omitempty"`
+	Flags      []string      `json:"flags" bson:"flags"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// BatchRequest is the body of a batch weather request. Datetime and Filter
+// apply to every location, as they would to a single request.
+type BatchRequest struct {
+	Locations []LocationQuery `json:"locations"`
+	Datetime  string          `json:"datetime,omitempty"`
+	Filter    string          `json:"filter,omitempty"`
+}
+
+// BatchResult is the outcome for one location of a batch. Status is the
+// status a single request for the location would have answered with; either
+// Weather or Error is set.
+type BatchResult struct {
+	Location LocationQuery    `json:"location"`
+	Status   int              `json:"status"`
+	Weather  *WeatherResponse `json:"weather,omitempty"`
+	Error    *ErrorResponse   `json:"error,omitempty"`
+}
+
+// BatchResponse holds the results of a batch in the order of its locations.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
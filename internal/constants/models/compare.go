@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Comparison is the weather of several cities side by side. Every series of
+// a city is aligned with Days or Hours, and its deltas are its values minus
+// the baseline city's at the same point.
+type Comparison struct {
+	Date     string `json:"date"`
+	Baseline string `json:"baseline"`
+	Units    Units  `json:"units"`
+	// Days are the dates, from Date on, with a forecast for every city.
+	Days []string `json:"days"`
+	// Hours are the hours of Date, in the baseline's time zone, with a
+	// forecast for every city.
+	Hours     []time.Time        `json:"hours"`
+	Locations []ComparedLocation `json:"locations"`
+	Rankings  Rankings           `json:"rankings"`
+}
+
+// ComparedLocation is one city of a comparison. Status and Error are those a
+// single request for the city would have answered with; a city that failed
+// has no series and is not ranked.
+type ComparedLocation struct {
+	City     string           `json:"city"`
+	Address  string           `json:"address,omitempty"`
+	Country  string           `json:"country,omitempty"`
+	Timezone string           `json:"timezone,omitempty"`
+	Status   int              `json:"status"`
+	Error    *ErrorResponse   `json:"error,omitempty"`
+	Daily    []ComparedValues `json:"daily,omitempty"`
+	Hourly   []ComparedValues `json:"hourly,omitempty"`
+}
+
+// ComparedValues are a city's weather at one point of a series.
+type ComparedValues struct {
+	Temp      float32      `json:"temp"`
+	Tempmin   float32      `json:"tempmin"`
+	Tempmax   float32      `json:"tempmax"`
+	Humidity  float32      `json:"humidity"`
+	Precip    float32      `json:"precip"`
+	Windspeed float32      `json:"windspeed"`
+	Delta     WeatherDelta `json:"delta"`
+}
+
+// WeatherDelta is the difference between two cities' weather.
+type WeatherDelta struct {
+	Temp      float32 `json:"temp"`
+	Humidity  float32 `json:"humidity"`
+	Precip    float32 `json:"precip"`
+	Windspeed float32 `json:"windspeed"`
+}
+
+// Rankings order the compared cities by their weather on the comparison's
+// date: warmest, driest and calmest first.
+type Rankings struct {
+	Temp      []string `json:"temp"`
+	Precip    []string `json:"precip"`
+	Windspeed []string `json:"windspeed"`
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Digest frequencies.
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// MaxDigestLocations is how many locations one digest may cover.
+const MaxDigestLocations = 10
+
+// Digest delivery states.
+const (
+	DigestSent   = "sent"
+	DigestFailed = "failed"
+)
+
+// SendTimeLayout is the layout of DigestSchedule.SendTime.
+const SendTimeLayout = "15:04"
+
+var (
+	// ErrDigestNotFound is returned when no digest subscription has the
+	// requested ID, unsubscribe token or confirmation token.
+	ErrDigestNotFound = errors.New("digest subscription not found")
+	// ErrDigestUnsubscribed is returned when sending a digest that was
+	// unsubscribed.
+	ErrDigestUnsubscribed = errors.New("digest subscription is unsubscribed")
+	// ErrDigestNotConfirmed is returned when sending a digest whose address
+	// has not been confirmed yet.
+	ErrDigestNotConfirmed = errors.New("digest subscription is not confirmed; follow the link in the confirmation email first")
+)
+
+// weekdays maps DigestSchedule.Weekday names to days.
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// DigestSchedule says when a digest is sent: every day, or on Weekday for
+// weekly digests, at SendTime ("15:04") local time in Timezone, an IANA zone
+// name such as "Europe/Berlin".
+type DigestSchedule struct {
+	Frequency string `json:"frequency" bson:"frequency"`
+	Weekday   string `json:"weekday,omitempty" bson:"weekday,omitempty"`
+	SendTime  string `json:"send_time" bson:"send_time"`
+	Timezone  string `json:"timezone" bson:"timezone"`
+}
+
+// DigestSubscription is a registered email digest. UnsubscribeToken is only
+// returned when the digest is created; it is also part of every email.
+// Nothing is sent until the owner of Email confirms it with the link in the
+// confirmation email, which sets ConfirmedAt.
+type DigestSubscription struct {
+	ID        int32           `json:"id" bson:"id"`
+	Email     string          `json:"email" bson:"email"`
+	Locations []LocationQuery `json:"locations" bson:"locations"`
+	DigestSchedule
+	NextSendAt       time.Time  `json:"next_send_at" bson:"next_send_at"`
+	LastSentAt       *time.Time `json:"last_sent_at,omitempty" bson:"last_sent_at,omitempty"`
+	LastError        string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	ConfirmedAt      *time.Time `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty"`
+	UnsubscribedAt   *time.Time `json:"unsubscribed_at,omitempty" bson:"unsubscribed_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at" bson:"created_at"`
+	UnsubscribeToken string     `json:"unsubscribe_token,omitempty" bson:"-"`
+	// Attempts is how many sends of the slot at NextSendAt have failed.
+	Attempts int `json:"-" bson:"-"`
+}
+
+// DigestInput is the body of a digest registration.
+type DigestInput struct {
+	Email     string          `json:"email" bson:"email"`
+	Locations []LocationQuery `json:"locations" bson:"locations"`
+	DigestSchedule
+}
+
+// DigestDelivery is the outcome of one scheduled digest.
+type DigestDelivery struct {
+	ScheduledFor time.Time `json:"scheduled_for" bson:"scheduled_for"`
+	Status       string    `json:"status" bson:"status"`
+	Attempts     int       `json:"attempts" bson:"attempts"`
+	Error        string    `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
+
+func (s DigestSchedule) Validate() error {
+	err := validation.ValidateStruct(&s,
+		validation.Field(&s.Frequency, validation.Required, validation.In(DigestDaily, DigestWeekly)),
+		validation.Field(&s.SendTime, validation.Required),
+		validation.Field(&s.Timezone, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := time.Parse(SendTimeLayout, s.SendTime); err != nil {
+		return fmt.Errorf("send_time must be HH:MM, got %q", s.SendTime)
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "Local" {
+		return fmt.Errorf("unknown timezone %q", s.Timezone)
+	}
+	_, isDay := weekdays[strings.ToLower(s.Weekday)]
+	switch {
+	case s.Frequency == DigestWeekly && !isDay:
+		return errors.New("weekly digests need a weekday, such as monday")
+	case s.Frequency == DigestDaily && s.Weekday != "":
+		return errors.New("weekday is only used by weekly digests")
+	}
+	return nil
+}
+
+// Day returns the weekday of a weekly schedule.
+func (s DigestSchedule) Day() (time.Weekday, bool) {
+	day, ok := weekdays[strings.ToLower(s.Weekday)]
+	return day, ok && s.Frequency == DigestWeekly
+}
+
+// Next returns the first send time of a valid schedule after t.
+func (s DigestSchedule) Next(after time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	clock, err := time.Parse(SendTimeLayout, s.SendTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Days are stepped in local wall-clock time so the send time holds
+	// across daylight saving changes.
+	local := after.In(loc)
+	y, m, d := local.Date()
+	next := time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, loc)
+	if day, weekly := s.Day(); weekly {
+		d += (int(day) - int(next.Weekday()) + 7) % 7
+		next = time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, loc)
+		for !next.After(after) {
+			d += 7
+			next = time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, loc)
+		}
+		return next, nil
+	}
+	for !next.After(after) {
+		d++
+		next = time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, loc)
+	}
+	return next, nil
+}
+
+func (d DigestInput) Validate() error {
+	if _, err := mail.ParseAddress(d.Email); err != nil || len(d.Email) > 254 || strings.ContainsAny(d.Email, "<>") {
+		return fmt.Errorf("invalid email address %q", d.Email)
+	}
+	if len(d.Locations) == 0 || len(d.Locations) > MaxDigestLocations {
+		return fmt.Errorf("a digest needs between 1 and %d locations", MaxDigestLocations)
+	}
+	for i, l := range d.Locations {
+		if err := l.Validate(); err != nil {
+			return fmt.Errorf("locations[%d]: %w", i, err)
+		}
+	}
+	return d.DigestSchedule.Validate()
+}
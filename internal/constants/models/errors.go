@@ -0,0 +1,109 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Error codes are the machine-readable code of an ErrorResponse. They are
+// part of the API and must not change.
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeLocationNotFound    = "location_not_found"
+	CodeAmbiguousLocation   = "ambiguous_location"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	CodeUpstreamRateLimited = "upstream_rate_limited"
+	CodeStorageFailure      = "storage_failure"
+	CodeInternal            = "internal_error"
+)
+
+// The kinds of failure a weather request can end in. Errors are matched
+// against them with errors.Is.
+var (
+	// ErrInvalidRequest marks errors caused by the request itself.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrLocationNotFound is returned when a city cannot be geocoded.
+	ErrLocationNotFound = errors.New("location not found")
+	// ErrAmbiguousLocation is returned, as an *AmbiguousLocationError, when
+	// a city name matches several places and the request set
+	// WeatherRequest.RejectAmbiguous.
+	ErrAmbiguousLocation = errors.New("ambiguous location")
+	// ErrUpstreamUnavailable is returned when the weather provider cannot
+	// be reached or answers with an error or an unreadable response.
+	ErrUpstreamUnavailable = errors.New("weather provider unavailable")
+	// ErrUpstreamRateLimited is returned, as a *RateLimitedError, when the
+	// weather provider rejects requests for exceeding its rate limit.
+	ErrUpstreamRateLimited = errors.New("weather provider rate limit exceeded")
+	// ErrStorage is returned when stored data cannot be read.
+	ErrStorage = errors.New("storage failure")
+)
+
+// RequestIDHeader carries the ID of a request, which ErrorResponse repeats
+// so failures can be matched with the server's logs.
+const RequestIDHeader = "X-Request-ID"
+
+// ErrorResponse is the body of an error answer.
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+	// Column locates an error in a filter expression.
+	Column int `json:"column,omitempty"`
+	// Candidates are the places an ambiguous city name matches.
+	Candidates []Place `json:"candidates,omitempty"`
+}
+
+type invalidRequestError struct {
+	err error
+}
+
+// InvalidRequest marks err as caused by the request, keeping its message.
+func InvalidRequest(err error) error {
+	if err == nil || errors.Is(err, ErrInvalidRequest) {
+		return err
+	}
+	return invalidRequestError{err: err}
+}
+
+func (e invalidRequestError) Error() string {
+	return e.err.Error()
+}
+
+func (e invalidRequestError) Unwrap() []error {
+	return []error{ErrInvalidRequest, e.err}
+}
+
+// AmbiguousLocationError lists the places a city name matches. Clients can
+// ask again by coordinate or with a qualified name such as "London,GB".
+type AmbiguousLocationError struct {
+	City       string
+	Candidates []Place
+}
+
+func (e *AmbiguousLocationError) Error() string {
+	return fmt.Sprintf("%q matches %d places", e.City, len(e.Candidates))
+}
+
+func (e *AmbiguousLocationError) Is(target error) bool {
+	return target == ErrAmbiguousLocation
+}
+
+// RateLimitedError is returned when the weather provider rate limits us.
+// RetryAfter is zero when the provider did not say when to retry.
+type RateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v, retry after %v", ErrUpstreamRateLimited, e.RetryAfter)
+	}
+	return ErrUpstreamRateLimited.Error()
+}
+
+func (e *RateLimitedError) Is(target error) bool {
+	return target == ErrUpstreamRateLimited
+}
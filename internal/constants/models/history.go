@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	// DefaultHistoryLimit is the page size used when none is requested.
+	DefaultHistoryLimit = 20
+	// MaxHistoryLimit caps the page size of a single history request.
+	MaxHistoryLimit = 100
+)
+
+// ErrInvalidCursor is returned when a history cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// HistoryFilter narrows down the stored weather queries returned by GetHistory.
+type HistoryFilter struct {
+	City       string    `json:"city" bson:"city"`
+	Coordinate *Location `json:"coordinate,omitempty" bson:"coordinate,omitempty"`
+	RadiusKm   float64   `json:"radius_km" bson:"radius_km"`
+	From       time.Time `json:"from" bson:"from"`
+	To         time.Time `json:"to" bson:"to"`
+	Cursor     string    `json:"cursor" bson:"cursor"`
+	Limit      int       `json:"limit" bson:"limit"`
+	Summary    bool      `json:"summary" bson:"summary"`
+}
+
+// HistorySummary is the condensed view of a stored response, built from its
+// current conditions.
+type HistorySummary struct {
+	Days      int     `json:"days" bson:"days"`
+	Temp      float32 `json:"temp" bson:"temp"`
+	Tempmin   float32 `json:"tempmin" bson:"tempmin"`
+	Tempmax   float32 `json:"tempmax" bson:"tempmax"`
+	Humidity  float32 `json:"humidity" bson:"humidity"`
+	Precip    float32 `json:"precip" bson:"precip"`
+	Windspeed float32 `json:"windspeed" bson:"windspeed"`
+}
+
+// HistoryEntry is a single stored weather query. Exactly one of Weather and
+// Summary is set, depending on HistoryFilter.Summary.
+type HistoryEntry struct {
+	ID        int32            `json:"id" bson:"id"`
+	City      string           `json:"city" bson:"city"`
+	Latitude  *float64         `json:"latitude,omitempty" bson:"latitude,omitempty"`
+	Longitude *float64         `json:"longitude,omitempty" bson:"longitude,omitempty"`
+	QueryTime time.Time        `json:"query_time" bson:"query_time"`
+	Weather   *WeatherResponse `json:"weather,omitempty" bson:"weather,omitempty"`
+	Summary   *HistorySummary  `json:"summary,omitempty" bson:"summary,omitempty"`
+}
+
+// HistoryPage is one page of history results. NextCursor is empty on the last page.
+type HistoryPage struct {
+	Items      []HistoryEntry `json:"items" bson:"items"`
+	NextCursor string         `json:"next_cursor,omitempty" bson:"next_cursor,omitempty"`
+}
+
+func (f HistoryFilter) Validate() error {
+	err := validation.ValidateStruct(&f,
+		validation.Field(&f.Limit, validation.Min(0), validation.Max(MaxHistoryLimit)),
+		validation.Field(&f.RadiusKm, validation.Min(0.0)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if f.RadiusKm > 0 && f.Coordinate == nil {
+		return errors.New("radius requires a coordinate")
+	}
+	if f.Coordinate != nil && f.RadiusKm == 0 {
+		return errors.New("coordinate requires a radius")
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
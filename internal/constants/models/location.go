@@ -0,0 +1,70 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LocationQuery names a location by city or coordinate, as digests and live
+// subscriptions do. Exactly one of City and Coordinate is set.
+type LocationQuery struct {
+	City       string    `json:"city,omitempty" bson:"city,omitempty"`
+	Coordinate *Location `json:"coordinate,omitempty" bson:"coordinate,omitempty"`
+}
+
+// DistanceKm is the great-circle distance between l and to, using the same
+// haversine formula as the history queries.
+func (l Location) DistanceKm(to Location) float64 {
+	const earthRadiusKm = 6371
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(to.Latitude - l.Latitude)
+	dLon := rad(to.Longitude - l.Longitude)
+	h := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(rad(l.Latitude))*math.Cos(rad(to.Latitude))*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// Request builds the weather request for l.
+func (l LocationQuery) Request(datetime string) WeatherRequest {
+	rq := WeatherRequest{City: l.City, DateTime: datetime}
+	if l.Coordinate != nil {
+		rq.Coordinate = *l.Coordinate
+	}
+	return rq
+}
+
+func (l LocationQuery) Validate() error {
+	if (l.City != "") == (l.Coordinate != nil) {
+		return errors.New("either city or coordinate must be provided, but not both")
+	}
+	if len(l.City) > 100 {
+		return errors.New("city must be at most 100 characters")
+	}
+	if c := l.Coordinate; c != nil {
+		if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+			return errors.New("coordinate out of range")
+		}
+	}
+	return nil
+}
+
+// Label names l for display.
+func (l LocationQuery) Label() string {
+	if l.City != "" {
+		return l.City
+	}
+	return fmt.Sprintf("%.4f, %.4f", l.Coordinate.Latitude, l.Coordinate.Longitude)
+}
+
+// Matches reports whether a response fetched for city is for l. Cities match
+// by name, as requested or as the provider names it, and coordinates when the
+// response is within radiusKm.
+func (l LocationQuery) Matches(city string, weather WeatherResponse, radiusKm float64) bool {
+	if l.City != "" {
+		return strings.EqualFold(city, l.City) || strings.EqualFold(weather.Address, l.City)
+	}
+	at := Location{Latitude: weather.Latitude, Longitude: weather.Longitude}
+	return l.Coordinate.DistanceKm(at) <= radiusKm
+}
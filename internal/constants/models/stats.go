@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// StorageStats summarizes what the storage backend holds. The oldest and
+// newest query times are absent while nothing is stored.
+type StorageStats struct {
+	Backend     string     `json:"backend" bson:"backend"`
+	Queries     int64      `json:"queries" bson:"queries"`
+	Cities      int64      `json:"cities" bson:"cities"`
+	Locations   int64      `json:"locations" bson:"locations"`
+	OldestQuery *time.Time `json:"oldest_query,omitempty" bson:"oldest_query,omitempty"`
+	NewestQuery *time.Time `json:"newest_query,omitempty" bson:"newest_query,omitempty"`
+}
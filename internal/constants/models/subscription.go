@@ -0,0 +1,149 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// MaxSubscriptionDays is how many forecast days a subscription may watch.
+const MaxSubscriptionDays = 7
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+var (
+	// ErrSubscriptionNotFound is returned when no subscription has the
+	// requested ID.
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrInvalidCondition is returned when a subscription condition cannot be
+	// compiled.
+	ErrInvalidCondition = errors.New("invalid condition")
+	// ErrCallbackNotPublic is returned when a callback URL points at this
+	// host or at a private, link-local or otherwise non-public address.
+	ErrCallbackNotPublic = errors.New("callback_url must point to a public address")
+)
+
+// nonPublicPrefixes are special-purpose ranges that netip does not classify
+// as private, loopback or link-local but that must not receive webhooks.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// PublicAddr reports whether addr may receive webhooks: a global unicast
+// address outside the private and special-purpose ranges. It is checked when
+// a subscription is created and again whenever a delivery connects, since a
+// host name can resolve to a different address later.
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Subscription asks for a webhook call whenever Condition holds on any of the
+// next Days forecast days for a location. Exactly one of City and Coordinate
+// is set. Secret signs the webhook payloads and is only returned when the
+// subscription is created.
+type Subscription struct {
+	ID          int32     `json:"id" bson:"id"`
+	City        string    `json:"city,omitempty" bson:"city,omitempty"`
+	Coordinate  *Location `json:"coordinate,omitempty" bson:"coordinate,omitempty"`
+	Condition   string    `json:"condition" bson:"condition"`
+	Days        int       `json:"days" bson:"days"`
+	CallbackURL string    `json:"callback_url" bson:"callback_url"`
+	Secret      string    `json:"secret,omitempty" bson:"-"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+}
+
+// SubscriptionInput is the body of a subscription request.
+type SubscriptionInput struct {
+	City        string    `json:"city" bson:"city"`
+	Coordinate  *Location `json:"coordinate" bson:"coordinate"`
+	Condition   string    `json:"condition" bson:"condition"`
+	Days        int       `json:"days" bson:"days"`
+	CallbackURL string    `json:"callback_url" bson:"callback_url"`
+}
+
+// WebhookDelivery is one notification for a subscription and its delivery
+// state.
+type WebhookDelivery struct {
+	ID             int64           `json:"id" bson:"id"`
+	SubscriptionID int32           `json:"subscription_id" bson:"subscription_id"`
+	EventKey       string          `json:"event_key" bson:"event_key"`
+	Status         string          `json:"status" bson:"status"`
+	Attempts       int             `json:"attempts" bson:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" bson:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" bson:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at" bson:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+	Payload        json.RawMessage `json:"payload" bson:"payload"`
+}
+
+// WebhookPayload is the JSON body posted to a subscription's callback URL.
+type WebhookPayload struct {
+	SubscriptionID int32     `json:"subscription_id" bson:"subscription_id"`
+	EventKey       string    `json:"event_key" bson:"event_key"`
+	Condition      string    `json:"condition" bson:"condition"`
+	Address        string    `json:"address" bson:"address"`
+	Country        string    `json:"country" bson:"country"`
+	Latitude       float64   `json:"latitude" bson:"latitude"`
+	Longitude      float64   `json:"longitude" bson:"longitude"`
+	Day            Weather   `json:"day" bson:"day"`
+	FetchedAt      time.Time `json:"fetched_at" bson:"fetched_at"`
+}
+
+func (s SubscriptionInput) Validate() error {
+	err := validation.ValidateStruct(&s,
+		validation.Field(&s.City, validation.Length(0, 100)),
+		validation.Field(&s.Condition, validation.Required),
+		validation.Field(&s.Days, validation.Required, validation.Min(1), validation.Max(MaxSubscriptionDays)),
+		validation.Field(&s.CallbackURL, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+
+	if (s.City != "") == (s.Coordinate != nil) {
+		return errors.New("either city or coordinate must be provided, but not both")
+	}
+	if c := s.Coordinate; c != nil {
+		if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+			return errors.New("coordinate out of range")
+		}
+	}
+	u, err := url.Parse(s.CallbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("callback_url must be an absolute http or https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrCallbackNotPublic
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !PublicAddr(addr) {
+		return ErrCallbackNotPublic
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestSubscriptionCallbackURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{url: "https://hooks.example.com/weather"},
+		{url: "http://93.184.215.14:8080/hook"},
+		{url: "https://[2606:4700::1111]/hook"},
+		{url: "http://localhost:8080/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://LOCALHOST./hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://api.localhost/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://127.0.0.1/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://[::1]/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://169.254.169.254/latest/meta-data/", wantErr: ErrCallbackNotPublic},
+		{url: "http://[fe80::1]/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://10.0.0.5/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://172.16.3.4/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://192.168.1.1/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://[fd00::1]/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://[::ffff:10.0.0.1]/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://100.64.0.1/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://0.0.0.0/hook", wantErr: ErrCallbackNotPublic},
+		{url: "http://224.0.0.1/hook", wantErr: ErrCallbackNotPublic},
+	}
+	for _, tt := range tests {
+		in := SubscriptionInput{City: "Berlin", Condition: "temp > 30", Days: 1, CallbackURL: tt.url}
+		err := in.Validate()
+		if tt.wantErr == nil && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.url, err)
+		}
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got %v, want %v", tt.url, err, tt.wantErr)
+		}
+	}
+
+	for _, url := range []string{"ftp://example.com/hook", "/relative", "https://"} {
+		in := SubscriptionInput{City: "Berlin", Condition: "temp > 30", Days: 1, CallbackURL: url}
+		if err := in.Validate(); err == nil {
+			t.Errorf("%s: accepted", url)
+		}
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"8.8.8.8":         true,
+		"2001:4860::8888": true,
+		"127.0.0.1":       false,
+		"169.254.169.254": false,
+		"10.1.2.3":        false,
+		"198.18.0.1":      false,
+		"64:ff9b::a00:1":  false,
+		"::":              false,
+	} {
+		if got := PublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("PublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// VerificationFields are the forecast fields scored by verification.
+var VerificationFields = []string{"temp", "tempmin", "tempmax", "humidity", "windspeed"}
+
+// MaxVerificationLeadDays is the longest forecast lead time that is scored.
+const MaxVerificationLeadDays = 7
+
+// VerificationScore is the accuracy of one provider's forecasts of one field
+// at one lead time, in the field's units. Bias is the mean of forecast minus
+// observed, so a positive bias means the provider forecasts too high.
+type VerificationScore struct {
+	Provider   string    `json:"provider" bson:"provider"`
+	Field      string    `json:"field" bson:"field"`
+	LeadDays   int       `json:"lead_days" bson:"lead_days"`
+	Samples    int       `json:"samples" bson:"samples"`
+	MAE        float64   `json:"mae" bson:"mae"`
+	Bias       float64   `json:"bias" bson:"bias"`
+	RMSE       float64   `json:"rmse" bson:"rmse"`
+	ComputedAt time.Time `json:"computed_at" bson:"computed_at"`
+}
+
+// VerificationFilter narrows down the scores returned by GetVerification. Zero
+// values match everything.
+type VerificationFilter struct {
+	Provider string `json:"provider" bson:"provider"`
+	Field    string `json:"field" bson:"field"`
+	LeadDays int    `json:"lead_days" bson:"lead_days"`
+}
+
+func (f VerificationFilter) Validate() error {
+	fields := make([]interface{}, len(VerificationFields))
+	for i, field := range VerificationFields {
+		fields[i] = field
+	}
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.Field, validation.In(fields...)),
+		validation.Field(&f.LeadDays, validation.Min(1), validation.Max(MaxVerificationLeadDays)),
+	)
+}
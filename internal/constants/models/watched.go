@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// MinWatchInterval is the shortest prefetch interval a watched location may
+// ask for.
+const MinWatchInterval = time.Minute
+
+// ErrWatchedLocationNotFound is returned when no watched location has the
+// requested ID.
+var ErrWatchedLocationNotFound = errors.New("watched location not found")
+
+// WatchedLocation is a location whose weather is fetched and stored in the
+// background every Interval seconds. Exactly one of City and Coordinate is set.
+type WatchedLocation struct {
+	ID              int32      `json:"id" bson:"id"`
+	Name            string     `json:"name" bson:"name"`
+	City            string     `json:"city,omitempty" bson:"city,omitempty"`
+	Coordinate      *Location  `json:"coordinate,omitempty" bson:"coordinate,omitempty"`
+	IntervalSeconds int        `json:"interval_seconds" bson:"interval_seconds"`
+	NextFetchAt     time.Time  `json:"next_fetch_at" bson:"next_fetch_at"`
+	LastFetchedAt   *time.Time `json:"last_fetched_at,omitempty" bson:"last_fetched_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt       time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" bson:"updated_at"`
+}
+
+// WatchedLocationInput is the writable part of a WatchedLocation, as accepted
+// when creating or replacing one.
+type WatchedLocationInput struct {
+	Name            string    `json:"name" bson:"name"`
+	City            string    `json:"city" bson:"city"`
+	Coordinate      *Location `json:"coordinate" bson:"coordinate"`
+	IntervalSeconds int       `json:"interval_seconds" bson:"interval_seconds"`
+}
+
+// Interval returns the prefetch interval.
+func (w WatchedLocation) Interval() time.Duration {
+	return time.Duration(w.IntervalSeconds) * time.Second
+}
+
+// Request builds the weather request the prefetcher makes for w.
+func (w WatchedLocation) Request(datetime string) WeatherRequest {
+	rq := WeatherRequest{City: w.City, DateTime: datetime}
+	if w.Coordinate != nil {
+		rq.Coordinate = *w.Coordinate
+	}
+	return rq
+}
+
+func (w WatchedLocationInput) Validate() error {
+	err := validation.ValidateStruct(&w,
+		validation.Field(&w.Name, validation.Length(0, 100)),
+		validation.Field(&w.City, validation.Length(0, 100)),
+		validation.Field(&w.IntervalSeconds, validation.Required, validation.Min(int(MinWatchInterval.Seconds()))),
+	)
+	if err != nil {
+		return err
+	}
+
+	if (w.City != "") == (w.Coordinate != nil) {
+		return errors.New("either city or coordinate must be provided, but not both")
+	}
+	if c := w.Coordinate; c != nil {
+		if c.Latitude < -90 || c.Latitude > 90 || c.Longitude < -180 || c.Longitude > 180 {
+			return errors.New("coordinate out of range")
+		}
+	}
+	return nil
+}
@@ -2,18 +2,56 @@ package models
 
 import (
 	"errors"
+	"strings"
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 )
 
+// DatetimeLayout is the layout of Weather.Datetime.
+const DatetimeLayout = "2006-01-02 15:04:05"
+
 type Location struct {
 	Latitude float64 `json:"latitude" bson:"latitude"`
 	Longitude float64 `json:"longitude" bson:"longitude"`
 }
 
+// Place is a geocoded location.
+type Place struct {
+	Name       string   `json:"name" bson:"name"`
+	State      string   `json:"state,omitempty" bson:"state,omitempty"`
+	Country    string   `json:"country" bson:"country"`
+	Coordinate Location `json:"coordinate" bson:"coordinate"`
+	// Candidates are the distinct places the geocoded name matched, best
+	// first, when there was more than one. The place itself is the first.
+	Candidates []Place `json:"-" bson:"-"`
+}
+
+// Ambiguous returns an *AmbiguousLocationError if city, the name p was
+// geocoded from, matched several places and was not qualified with a state
+// or country.
+func (p Place) Ambiguous(city string) error {
+	if len(p.Candidates) > 1 && !strings.Contains(city, ",") {
+		return &AmbiguousLocationError{City: city, Candidates: p.Candidates}
+	}
+	return nil
+}
+
+// ErrInvalidFilter is returned when a weather request's filter expression
+// cannot be compiled.
+var ErrInvalidFilter = errors.New("invalid filter")
+
 type WeatherRequest struct {
-	City string `json:"location" bson:"location"`
+	City string `json:"city" bson:"city"`
 	Coordinate Location `json:"location" bson:"location"`
 	DateTime string `json:"datetime" bson:"datetime"`
+	// Filter is an optional expression selecting the days and hours
+	// returned, see package expr. It does not affect what is fetched or stored.
+	Filter string `json:"filter,omitempty" bson:"-"`
+	// RejectAmbiguous fails a city that matches several places with an
+	// *AmbiguousLocationError instead of using the best match. Only the /v2
+	// routes set it.
+	RejectAmbiguous bool `json:"-" bson:"-"`
 }
 
 type Weather struct {
@@ -30,7 +68,41 @@ type Weather struct {
 }
 
 type WeatherResponse struct {
-	Days []Weather `json:"days" bson:"days"`
+	Address   string    `json:"address" bson:"address"`
+	Country   string    `json:"country" bson:"country"`
+	Latitude  float64   `json:"latitude" bson:"latitude"`
+	Longitude float64   `json:"longitude" bson:"longitude"`
+	Timezone  string    `json:"timezone" bson:"timezone"`
+	Source    string    `json:"source" bson:"source"`
+	Days      []Weather `json:"days" bson:"days"`
+
+	// FetchedAt is when the data was retrieved from the provider. Origin,
+	// Age and Stale describe how this particular response was served and are
+	// not stored. Stale is set when the data is past its cache TTL, either
+	// while it is being refreshed or because the provider could not be reached.
+	FetchedAt time.Time `json:"fetched_at" bson:"fetched_at"`
+	Origin    string    `json:"origin,omitempty" bson:"-"`
+	Age       int64     `json:"age" bson:"-"`
+	Stale     bool      `json:"stale" bson:"-"`
+	// Anomalies compares the current conditions with the location's
+	// history. It is computed per request and not stored.
+	Anomalies *Anomalies `json:"anomalies,omitempty" bson:"-"`
+}
+
+// Values of WeatherResponse.Origin.
+const (
+	OriginProvider = "provider"
+	OriginCache    = "cache"
+)
+
+// ParseDatetime parses a Weather.Datetime value in the server's local time zone.
+func ParseDatetime(s string) (time.Time, error) {
+	return time.ParseInLocation(DatetimeLayout, s, time.Local)
+}
+
+// FormatDatetime formats t as a Weather.Datetime value.
+func FormatDatetime(t time.Time) string {
+	return t.In(time.Local).Format(DatetimeLayout)
 }
 
 func (w WeatherRequest) Validate() error {
@@ -0,0 +1,156 @@
+package models
+
+import "time"
+
+// Units names the units of the values in a response.
+type Units struct {
+	Temp      string `json:"temp" bson:"temp"`
+	Humidity  string `json:"humidity" bson:"humidity"`
+	Precip    string `json:"precip" bson:"precip"`
+	Snow      string `json:"snow" bson:"snow"`
+	Snowdepth string `json:"snowdepth" bson:"snowdepth"`
+	Windspeed string `json:"windspeed" bson:"windspeed"`
+}
+
+// ResponseUnits are the units providers' data is converted to.
+var ResponseUnits = Units{
+	Temp:      "celsius",
+	Humidity:  "percent",
+	Precip:    "mm",
+	Snow:      "mm",
+	Snowdepth: "cm",
+	Windspeed: "m/s",
+}
+
+// WeatherV2 is Weather as served by the v2 API, with Time in the location's
+// time zone instead of a server-local Datetime string.
+type WeatherV2 struct {
+	Time      time.Time   `json:"time" bson:"time"`
+	Temp      float32     `json:"temp" bson:"temp"`
+	Tempmin   float32     `json:"tempmin" bson:"tempmin"`
+	Tempmax   float32     `json:"tempmax" bson:"tempmax"`
+	Humidity  float32     `json:"humidity" bson:"humidity"`
+	Precip    float32     `json:"precip" bson:"precip"`
+	Snow      float32     `json:"snow" bson:"snow"`
+	Snowdepth float32     `json:"snowdepth" bson:"snowdepth"`
+	Windspeed float32     `json:"windspeed" bson:"windspeed"`
+	Hours     []WeatherV2 `json:"hours" bson:"hours"`
+}
+
+// Source says where the data in a v2 response came from and how it was
+// served.
+type Source struct {
+	Provider   string    `json:"provider" bson:"provider"`
+	Origin     string    `json:"origin" bson:"origin"`
+	FetchedAt  time.Time `json:"fetched_at" bson:"fetched_at"`
+	AgeSeconds int64     `json:"age_seconds" bson:"age_seconds"`
+	Stale      bool      `json:"stale" bson:"stale"`
+}
+
+// WeatherResponseV2 is WeatherResponse as served by the v2 API. The first
+// day holds the current conditions, as in WeatherResponse.
+type WeatherResponseV2 struct {
+	Address   string      `json:"address" bson:"address"`
+	Country   string      `json:"country" bson:"country"`
+	Latitude  float64     `json:"latitude" bson:"latitude"`
+	Longitude float64     `json:"longitude" bson:"longitude"`
+	Timezone  string      `json:"timezone" bson:"timezone"`
+	Units     Units       `json:"units" bson:"units"`
+	Source    Source      `json:"source" bson:"source"`
+	Days      []WeatherV2 `json:"days" bson:"days"`
+	Anomalies *Anomalies  `json:"anomalies,omitempty" bson:"anomalies,omitempty"`
+}
+
+// HistoryEntryV2 is HistoryEntry as served by the v2 API.
+type HistoryEntryV2 struct {
+	ID        int32              `json:"id" bson:"id"`
+	City      string             `json:"city" bson:"city"`
+	Latitude  *float64           `json:"latitude,omitempty" bson:"latitude,omitempty"`
+	Longitude *float64           `json:"longitude,omitempty" bson:"longitude,omitempty"`
+	QueryTime time.Time          `json:"query_time" bson:"query_time"`
+	Weather   *WeatherResponseV2 `json:"weather,omitempty" bson:"weather,omitempty"`
+	Summary   *HistorySummary    `json:"summary,omitempty" bson:"summary,omitempty"`
+}
+
+// HistoryPageV2 is HistoryPage as served by the v2 API. Units also apply to
+// the summaries.
+type HistoryPageV2 struct {
+	Items      []HistoryEntryV2 `json:"items" bson:"items"`
+	NextCursor string           `json:"next_cursor,omitempty" bson:"next_cursor,omitempty"`
+	Units      Units            `json:"units" bson:"units"`
+}
+
+// V2 converts w to the v2 model.
+func (w WeatherResponse) V2() WeatherResponseV2 {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil || w.Timezone == "" {
+		loc = time.UTC
+	}
+	return WeatherResponseV2{
+		Address:   w.Address,
+		Country:   w.Country,
+		Latitude:  w.Latitude,
+		Longitude: w.Longitude,
+		Timezone:  w.Timezone,
+		Units:     ResponseUnits,
+		Source: Source{
+			Provider:   w.Source,
+			Origin:     w.Origin,
+			FetchedAt:  w.FetchedAt,
+			AgeSeconds: w.Age,
+			Stale:      w.Stale,
+		},
+		Days:      weatherV2(w.Days, loc),
+		Anomalies: w.Anomalies,
+	}
+}
+
+func weatherV2(days []Weather, loc *time.Location) []WeatherV2 {
+	out := make([]WeatherV2, 0, len(days))
+	for _, d := range days {
+		// Datetime is in server-local time; unparsable values are left at
+		// the zero time.
+		t, _ := ParseDatetime(d.Datetime)
+		if !t.IsZero() {
+			t = t.In(loc)
+		}
+		out = append(out, WeatherV2{
+			Time:      t,
+			Temp:      d.Temp,
+			Tempmin:   d.Tempmin,
+			Tempmax:   d.Tempmax,
+			Humidity:  d.Humidity,
+			Precip:    d.Precip,
+			Snow:      d.Snow,
+			Snowdepth: d.Snowdepth,
+			Windspeed: d.Windspeed,
+			Hours:     weatherV2(d.Hours, loc),
+		})
+	}
+	return out
+}
+
+// V2 converts p to the v2 model.
+func (p HistoryPage) V2() HistoryPageV2 {
+	out := HistoryPageV2{
+		Items:      make([]HistoryEntryV2, 0, len(p.Items)),
+		NextCursor: p.NextCursor,
+		Units:      ResponseUnits,
+	}
+	for _, e := range p.Items {
+		entry := HistoryEntryV2{
+			ID:        e.ID,
+			City:      e.City,
+			Latitude:  e.Latitude,
+			Longitude: e.Longitude,
+			QueryTime: e.QueryTime,
+			Summary:   e.Summary,
+		}
+		if e.Weather != nil {
+			weather := e.Weather.V2()
+			entry.Weather = &weather
+		}
+		out.Items = append(out.Items, entry)
+	}
+	return out
+}
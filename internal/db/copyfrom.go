@@ -0,0 +1,95 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: copyfrom.go
+
+package db
+
+import (
+	"context"
+)
+
+// iteratorForInsertDailyValues implements pgx.CopyFromSource.
+type iteratorForInsertDailyValues struct {
+	rows                 []InsertDailyValuesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertDailyValues) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertDailyValues) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].SnapshotID,
+		r.rows[0].Position,
+		r.rows[0].ValidAt,
+		r.rows[0].Temp,
+		r.rows[0].Tempmin,
+		r.rows[0].Tempmax,
+		r.rows[0].Humidity,
+		r.rows[0].Precip,
+		r.rows[0].Snow,
+		r.rows[0].Snowdepth,
+		r.rows[0].Windspeed,
+	}, nil
+}
+
+func (r iteratorForInsertDailyValues) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertDailyValues(ctx context.Context, arg []InsertDailyValuesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"daily_values"}, []string{"snapshot_id", "position", "valid_at", "temp", "tempmin", "tempmax", "humidity", "precip", "snow", "snowdepth", "windspeed"}, &iteratorForInsertDailyValues{rows: arg})
+}
+
+// iteratorForInsertHourlyValues implements pgx.CopyFromSource.
+type iteratorForInsertHourlyValues struct {
+	rows                 []InsertHourlyValuesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertHourlyValues) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertHourlyValues) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].SnapshotID,
+		r.rows[0].DayPosition,
+		r.rows[0].Position,
+		r.rows[0].ValidAt,
+		r.rows[0].Temp,
+		r.rows[0].Tempmin,
+		r.rows[0].Tempmax,
+		r.rows[0].Humidity,
+		r.rows[0].Precip,
+		r.rows[0].Snow,
+		r.rows[0].Snowdepth,
+		r.rows[0].Windspeed,
+	}, nil
+}
+
+func (r iteratorForInsertHourlyValues) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertHourlyValues(ctx context.Context, arg []InsertHourlyValuesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"hourly_values"}, []string{"snapshot_id", "day_position", "position", "valid_at", "temp", "tempmin", "tempmax", "humidity", "precip", "snow", "snowdepth", "windspeed"}, &iteratorForInsertHourlyValues{rows: arg})
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_weather_query_history_query_time_id;
+
+ALTER TABLE weather_query_history DROP COLUMN IF EXISTS longitude;
+ALTER TABLE weather_query_history DROP COLUMN IF EXISTS latitude;
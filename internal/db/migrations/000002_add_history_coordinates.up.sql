@@ -0,0 +1,4 @@
+ALTER TABLE weather_query_history ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION;
+ALTER TABLE weather_query_history ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION;
+
+CREATE INDEX IF NOT EXISTS idx_weather_query_history_query_time_id ON weather_query_history (query_time DESC, id DESC);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS hourly_values;
+DROP TABLE IF EXISTS daily_values;
+DROP TABLE IF EXISTS forecast_snapshots;
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,59 @@
+CREATE TABLE locations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    country VARCHAR(100) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (latitude, longitude)
+);
+
+CREATE INDEX idx_locations_name ON locations (name);
+
+CREATE TABLE forecast_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    location_id INTEGER NOT NULL REFERENCES locations (id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    history_id INTEGER UNIQUE
+);
+
+CREATE INDEX idx_forecast_snapshots_location_fetched_at ON forecast_snapshots (location_id, fetched_at DESC);
+CREATE INDEX idx_forecast_snapshots_fetched_at ON forecast_snapshots (fetched_at);
+
+CREATE TABLE daily_values (
+    snapshot_id BIGINT NOT NULL REFERENCES forecast_snapshots (id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,
+    valid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    temp REAL NOT NULL,
+    tempmin REAL NOT NULL,
+    tempmax REAL NOT NULL,
+    humidity REAL NOT NULL,
+    precip REAL NOT NULL,
+    snow REAL NOT NULL,
+    snowdepth REAL NOT NULL,
+    windspeed REAL NOT NULL,
+    PRIMARY KEY (snapshot_id, position)
+);
+
+CREATE INDEX idx_daily_values_valid_at ON daily_values (valid_at);
+
+CREATE TABLE hourly_values (
+    snapshot_id BIGINT NOT NULL,
+    day_position SMALLINT NOT NULL,
+    position SMALLINT NOT NULL,
+    valid_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    temp REAL NOT NULL,
+    tempmin REAL NOT NULL,
+    tempmax REAL NOT NULL,
+    humidity REAL NOT NULL,
+    precip REAL NOT NULL,
+    snow REAL NOT NULL,
+    snowdepth REAL NOT NULL,
+    windspeed REAL NOT NULL,
+    PRIMARY KEY (snapshot_id, day_position, position),
+    FOREIGN KEY (snapshot_id, day_position) REFERENCES daily_values (snapshot_id, position) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_hourly_values_valid_at ON hourly_values (valid_at);
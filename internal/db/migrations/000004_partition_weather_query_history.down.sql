@@ -0,0 +1,29 @@
+ALTER TABLE weather_query_history RENAME TO weather_query_history_partitioned;
+ALTER SEQUENCE weather_query_history_id_seq OWNED BY NONE;
+
+DROP INDEX IF EXISTS idx_weather_query_history_city;
+DROP INDEX IF EXISTS idx_weather_query_history_query_time;
+DROP INDEX IF EXISTS idx_weather_query_history_query_time_id;
+ALTER TABLE weather_query_history_partitioned RENAME CONSTRAINT weather_query_history_pkey TO weather_query_history_partitioned_pkey;
+
+CREATE TABLE weather_query_history (
+    id INTEGER PRIMARY KEY DEFAULT nextval('weather_query_history_id_seq'),
+    city VARCHAR(100) NOT NULL,
+    query_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    weather_data JSONB NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION
+);
+
+ALTER SEQUENCE weather_query_history_id_seq OWNED BY weather_query_history.id;
+
+CREATE INDEX idx_weather_query_history_city ON weather_query_history (city);
+CREATE INDEX idx_weather_query_history_query_time ON weather_query_history (query_time);
+CREATE INDEX idx_weather_query_history_query_time_id ON weather_query_history (query_time DESC, id DESC);
+
+INSERT INTO weather_query_history (id, city, query_time, weather_data, latitude, longitude)
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history_partitioned;
+
+-- Dropping the parent drops every partition with it.
+DROP TABLE weather_query_history_partitioned;
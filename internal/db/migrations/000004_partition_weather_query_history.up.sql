@@ -0,0 +1,56 @@
+-- Convert weather_query_history into a table range-partitioned by month on
+-- query_time. Existing rows are copied into monthly partitions covering their
+-- time range; the application creates future partitions from then on.
+
+ALTER TABLE weather_query_history RENAME TO weather_query_history_legacy;
+ALTER TABLE weather_query_history_legacy RENAME CONSTRAINT weather_query_history_pkey TO weather_query_history_legacy_pkey;
+ALTER SEQUENCE weather_query_history_id_seq OWNED BY NONE;
+
+DROP INDEX IF EXISTS idx_weather_query_history_city;
+DROP INDEX IF EXISTS idx_weather_query_history_query_time;
+DROP INDEX IF EXISTS idx_weather_query_history_query_time_id;
+
+CREATE TABLE weather_query_history (
+    id INTEGER NOT NULL DEFAULT nextval('weather_query_history_id_seq'),
+    city VARCHAR(100) NOT NULL,
+    query_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    weather_data JSONB NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    PRIMARY KEY (id, query_time)
+) PARTITION BY RANGE (query_time);
+
+ALTER SEQUENCE weather_query_history_id_seq OWNED BY weather_query_history.id;
+
+CREATE INDEX idx_weather_query_history_city ON weather_query_history (city);
+CREATE INDEX idx_weather_query_history_query_time ON weather_query_history (query_time);
+CREATE INDEX idx_weather_query_history_query_time_id ON weather_query_history (query_time DESC, id DESC);
+
+-- Partitions are named weather_query_history_pYYYY_MM and bounded by UTC
+-- month starts, matching what the partition manager creates.
+DO $$
+DECLARE
+    month_start TIMESTAMP;
+    last_month TIMESTAMP := date_trunc('month', now() AT TIME ZONE 'UTC') + INTERVAL '3 months';
+BEGIN
+    SELECT date_trunc('month', COALESCE(min(query_time), now()) AT TIME ZONE 'UTC')
+    INTO month_start
+    FROM weather_query_history_legacy;
+
+    WHILE month_start <= last_month LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF weather_query_history FOR VALUES FROM (%L) TO (%L)',
+            'weather_query_history_p' || to_char(month_start, 'YYYY_MM'),
+            month_start AT TIME ZONE 'UTC',
+            (month_start + INTERVAL '1 month') AT TIME ZONE 'UTC'
+        );
+        month_start := month_start + INTERVAL '1 month';
+    END LOOP;
+END
+$$;
+
+INSERT INTO weather_query_history (id, city, query_time, weather_data, latitude, longitude)
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history_legacy;
+
+DROP TABLE weather_query_history_legacy;
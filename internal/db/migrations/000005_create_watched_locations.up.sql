@@ -0,0 +1,20 @@
+-- Locations whose weather is prefetched on a schedule. Each row names either a
+-- city or a coordinate. next_fetch_at is when the row is next due; schedulers
+-- claim due rows by pushing it forward, so several instances can share the
+-- table.
+CREATE TABLE watched_locations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    interval_seconds INTEGER NOT NULL CHECK (interval_seconds > 0),
+    next_fetch_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_fetched_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((city <> '') <> (latitude IS NOT NULL AND longitude IS NOT NULL))
+);
+
+CREATE INDEX idx_watched_locations_next_fetch_at ON watched_locations (next_fetch_at);
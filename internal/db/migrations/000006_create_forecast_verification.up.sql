@@ -0,0 +1,13 @@
+-- Forecast accuracy per provider, field and lead time, as last computed by
+-- the verification job. The job replaces the whole table on every run.
+CREATE TABLE forecast_verification (
+    provider VARCHAR(50) NOT NULL,
+    field VARCHAR(20) NOT NULL,
+    lead_days SMALLINT NOT NULL,
+    samples INTEGER NOT NULL,
+    mae DOUBLE PRECISION NOT NULL,
+    bias DOUBLE PRECISION NOT NULL,
+    rmse DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (provider, field, lead_days)
+);
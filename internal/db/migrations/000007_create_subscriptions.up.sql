@@ -0,0 +1,36 @@
+-- Webhook subscriptions: a location, a condition on its forecast and a
+-- callback URL. Each row names either a city or a coordinate.
+CREATE TABLE subscriptions (
+    id SERIAL PRIMARY KEY,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    condition TEXT NOT NULL,
+    days SMALLINT NOT NULL CHECK (days BETWEEN 1 AND 7),
+    callback_url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((city <> '') <> (latitude IS NOT NULL AND longitude IS NOT NULL))
+);
+
+CREATE INDEX idx_subscriptions_city ON subscriptions (lower(city)) WHERE city <> '';
+
+-- One row per notification. event_key identifies what the notification is
+-- about, so the same event is never queued twice for a subscription. Failed
+-- deliveries are retried with backoff until they are delivered or dead.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    event_key VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (subscription_id, event_key)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
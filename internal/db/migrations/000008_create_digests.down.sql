@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS digest_deliveries;
+DROP TABLE IF EXISTS digest_subscriptions;
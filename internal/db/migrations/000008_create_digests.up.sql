@@ -0,0 +1,36 @@
+-- Email forecast digests. Each row sends a digest for its locations daily or
+-- weekly at send_time in timezone. next_send_at is the next scheduled send;
+-- due_at is when the row is next worked on, which is next_send_at unless a
+-- send is being retried or is claimed by a worker.
+CREATE TABLE digest_subscriptions (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(254) NOT NULL,
+    locations JSONB NOT NULL,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    weekday SMALLINT CHECK (weekday BETWEEN 0 AND 6),
+    send_time TIME NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+    next_send_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_sent_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    unsubscribed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((frequency = 'weekly') = (weekday IS NOT NULL))
+);
+
+CREATE INDEX idx_digest_subscriptions_due ON digest_subscriptions (due_at) WHERE unsubscribed_at IS NULL;
+
+-- The outcome of each scheduled digest, one row per subscription and slot.
+CREATE TABLE digest_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    digest_id INTEGER NOT NULL REFERENCES digest_subscriptions (id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('sent', 'failed')),
+    attempts INTEGER NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (digest_id, scheduled_for)
+);
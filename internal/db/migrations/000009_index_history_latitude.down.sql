@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_weather_query_history_latitude;
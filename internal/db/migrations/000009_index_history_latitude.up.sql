@@ -0,0 +1,4 @@
+-- Anomaly baselines look up the history near a point; the latitude band they
+-- filter on first is served by this index.
+CREATE INDEX idx_weather_query_history_latitude ON weather_query_history (latitude)
+    WHERE latitude IS NOT NULL AND longitude IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_digest_subscriptions_due;
+CREATE INDEX idx_digest_subscriptions_due ON digest_subscriptions (due_at) WHERE unsubscribed_at IS NULL;
+
+ALTER TABLE digest_subscriptions
+    DROP COLUMN IF EXISTS confirmed_at,
+    DROP COLUMN IF EXISTS confirm_token;
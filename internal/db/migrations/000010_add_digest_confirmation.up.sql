@@ -0,0 +1,12 @@
+-- Digests are only sent once the owner of the address follows the link in a
+-- confirmation email, which carries confirm_token. Digests created before
+-- confirmation was required count as confirmed when they were created.
+ALTER TABLE digest_subscriptions
+    ADD COLUMN confirm_token VARCHAR(64) UNIQUE,
+    ADD COLUMN confirmed_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE digest_subscriptions SET confirmed_at = created_at;
+
+DROP INDEX IF EXISTS idx_digest_subscriptions_due;
+CREATE INDEX idx_digest_subscriptions_due ON digest_subscriptions (due_at)
+    WHERE unsubscribed_at IS NULL AND confirmed_at IS NOT NULL;
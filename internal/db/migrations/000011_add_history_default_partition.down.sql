@@ -0,0 +1,27 @@
+-- Rows in the default partition are moved into monthly partitions, created as
+-- needed, before it is dropped.
+ALTER TABLE weather_query_history DETACH PARTITION weather_query_history_default;
+
+DO $$
+DECLARE
+    month_start TIMESTAMP;
+BEGIN
+    FOR month_start IN
+        SELECT DISTINCT date_trunc('month', query_time AT TIME ZONE 'UTC')
+        FROM weather_query_history_default
+    LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF weather_query_history FOR VALUES FROM (%L) TO (%L)',
+            'weather_query_history_p' || to_char(month_start, 'YYYY_MM'),
+            month_start AT TIME ZONE 'UTC',
+            (month_start + INTERVAL '1 month') AT TIME ZONE 'UTC'
+        );
+    END LOOP;
+END
+$$;
+
+INSERT INTO weather_query_history (id, city, query_time, weather_data, latitude, longitude)
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history_default;
+
+DROP TABLE weather_query_history_default;
@@ -0,0 +1,6 @@
+-- Queries made outside every monthly partition, because the partition manager
+-- is disabled or has not run for a while, land in the default partition
+-- instead of failing. The manager moves them into the month's partition when
+-- it creates it.
+CREATE TABLE IF NOT EXISTS weather_query_history_default
+    PARTITION OF weather_query_history DEFAULT;
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations that define the
+// database schema. The same files are read by sqlc for code generation.
+package migrations
+
+import "embed"
+
+// FS holds the NNNNNN_name.up.sql and NNNNNN_name.down.sql migration files.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -8,9 +8,148 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type DailyValue struct {
+	SnapshotID int64              `db:"snapshot_id" json:"snapshot_id"`
+	Position   int16              `db:"position" json:"position"`
+	ValidAt    pgtype.Timestamptz `db:"valid_at" json:"valid_at"`
+	Temp       float32            `db:"temp" json:"temp"`
+	Tempmin    float32            `db:"tempmin" json:"tempmin"`
+	Tempmax    float32            `db:"tempmax" json:"tempmax"`
+	Humidity   float32            `db:"humidity" json:"humidity"`
+	Precip     float32            `db:"precip" json:"precip"`
+	Snow       float32            `db:"snow" json:"snow"`
+	Snowdepth  float32            `db:"snowdepth" json:"snowdepth"`
+	Windspeed  float32            `db:"windspeed" json:"windspeed"`
+}
+
+type DigestDelivery struct {
+	ID           int64              `db:"id" json:"id"`
+	DigestID     int32              `db:"digest_id" json:"digest_id"`
+	ScheduledFor pgtype.Timestamptz `db:"scheduled_for" json:"scheduled_for"`
+	Status       string             `db:"status" json:"status"`
+	Attempts     int32              `db:"attempts" json:"attempts"`
+	Error        string             `db:"error" json:"error"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type DigestSubscription struct {
+	ID               int32              `db:"id" json:"id"`
+	Email            string             `db:"email" json:"email"`
+	Locations        []byte             `db:"locations" json:"locations"`
+	Frequency        string             `db:"frequency" json:"frequency"`
+	Weekday          pgtype.Int2        `db:"weekday" json:"weekday"`
+	SendTime         pgtype.Time        `db:"send_time" json:"send_time"`
+	Timezone         string             `db:"timezone" json:"timezone"`
+	UnsubscribeToken string             `db:"unsubscribe_token" json:"unsubscribe_token"`
+	NextSendAt       pgtype.Timestamptz `db:"next_send_at" json:"next_send_at"`
+	DueAt            pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	Attempts         int32              `db:"attempts" json:"attempts"`
+	LastSentAt       pgtype.Timestamptz `db:"last_sent_at" json:"last_sent_at"`
+	LastError        string             `db:"last_error" json:"last_error"`
+	UnsubscribedAt   pgtype.Timestamptz `db:"unsubscribed_at" json:"unsubscribed_at"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ConfirmToken     pgtype.Text        `db:"confirm_token" json:"confirm_token"`
+	ConfirmedAt      pgtype.Timestamptz `db:"confirmed_at" json:"confirmed_at"`
+}
+
+type ForecastSnapshot struct {
+	ID         int64              `db:"id" json:"id"`
+	LocationID int32              `db:"location_id" json:"location_id"`
+	Provider   string             `db:"provider" json:"provider"`
+	FetchedAt  pgtype.Timestamptz `db:"fetched_at" json:"fetched_at"`
+	HistoryID  pgtype.Int4        `db:"history_id" json:"history_id"`
+}
+
+type ForecastVerification struct {
+	Provider   string             `db:"provider" json:"provider"`
+	Field      string             `db:"field" json:"field"`
+	LeadDays   int16              `db:"lead_days" json:"lead_days"`
+	Samples    int32              `db:"samples" json:"samples"`
+	Mae        float64            `db:"mae" json:"mae"`
+	Bias       float64            `db:"bias" json:"bias"`
+	Rmse       float64            `db:"rmse" json:"rmse"`
+	ComputedAt pgtype.Timestamptz `db:"computed_at" json:"computed_at"`
+}
+
+type HourlyValue struct {
+	SnapshotID  int64              `db:"snapshot_id" json:"snapshot_id"`
+	DayPosition int16              `db:"day_position" json:"day_position"`
+	Position    int16              `db:"position" json:"position"`
+	ValidAt     pgtype.Timestamptz `db:"valid_at" json:"valid_at"`
+	Temp        float32            `db:"temp" json:"temp"`
+	Tempmin     float32            `db:"tempmin" json:"tempmin"`
+	Tempmax     float32            `db:"tempmax" json:"tempmax"`
+	Humidity    float32            `db:"humidity" json:"humidity"`
+	Precip      float32            `db:"precip" json:"precip"`
+	Snow        float32            `db:"snow" json:"snow"`
+	Snowdepth   float32            `db:"snowdepth" json:"snowdepth"`
+	Windspeed   float32            `db:"windspeed" json:"windspeed"`
+}
+
+type Location struct {
+	ID        int32              `db:"id" json:"id"`
+	Name      string             `db:"name" json:"name"`
+	Country   string             `db:"country" json:"country"`
+	Latitude  float64            `db:"latitude" json:"latitude"`
+	Longitude float64            `db:"longitude" json:"longitude"`
+	Timezone  string             `db:"timezone" json:"timezone"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type Subscription struct {
+	ID          int32              `db:"id" json:"id"`
+	City        string             `db:"city" json:"city"`
+	Latitude    pgtype.Float8      `db:"latitude" json:"latitude"`
+	Longitude   pgtype.Float8      `db:"longitude" json:"longitude"`
+	Condition   string             `db:"condition" json:"condition"`
+	Days        int16              `db:"days" json:"days"`
+	CallbackUrl string             `db:"callback_url" json:"callback_url"`
+	Secret      string             `db:"secret" json:"secret"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type WatchedLocation struct {
+	ID              int32              `db:"id" json:"id"`
+	Name            string             `db:"name" json:"name"`
+	City            string             `db:"city" json:"city"`
+	Latitude        pgtype.Float8      `db:"latitude" json:"latitude"`
+	Longitude       pgtype.Float8      `db:"longitude" json:"longitude"`
+	IntervalSeconds int32              `db:"interval_seconds" json:"interval_seconds"`
+	NextFetchAt     pgtype.Timestamptz `db:"next_fetch_at" json:"next_fetch_at"`
+	LastFetchedAt   pgtype.Timestamptz `db:"last_fetched_at" json:"last_fetched_at"`
+	LastError       string             `db:"last_error" json:"last_error"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type WeatherQueryHistory struct {
 	ID          int32              `db:"id" json:"id"`
 	City        string             `db:"city" json:"city"`
 	QueryTime   pgtype.Timestamptz `db:"query_time" json:"query_time"`
 	WeatherData []byte             `db:"weather_data" json:"weather_data"`
+	Latitude    pgtype.Float8      `db:"latitude" json:"latitude"`
+	Longitude   pgtype.Float8      `db:"longitude" json:"longitude"`
+}
+
+type WeatherQueryHistoryDefault struct {
+	ID          int32              `db:"id" json:"id"`
+	City        string             `db:"city" json:"city"`
+	QueryTime   pgtype.Timestamptz `db:"query_time" json:"query_time"`
+	WeatherData []byte             `db:"weather_data" json:"weather_data"`
+	Latitude    pgtype.Float8      `db:"latitude" json:"latitude"`
+	Longitude   pgtype.Float8      `db:"longitude" json:"longitude"`
+}
+
+type WebhookDelivery struct {
+	ID             int64              `db:"id" json:"id"`
+	SubscriptionID int32              `db:"subscription_id" json:"subscription_id"`
+	EventKey       string             `db:"event_key" json:"event_key"`
+	Payload        []byte             `db:"payload" json:"payload"`
+	Status         string             `db:"status" json:"status"`
+	Attempts       int32              `db:"attempts" json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode pgtype.Int4        `db:"last_status_code" json:"last_status_code"`
+	LastError      string             `db:"last_error" json:"last_error"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	DeliveredAt    pgtype.Timestamptz `db:"delivered_at" json:"delivered_at"`
 }
@@ -6,12 +6,94 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// Moves a digest on to its next slot after the current one was sent or given
+	// up on.
+	AdvanceDigest(ctx context.Context, arg AdvanceDigestParams) error
+	// Pushes next_attempt_at of up to batch_size due deliveries forward by the
+	// lease and returns them with their subscription's callback.
+	ClaimDueDeliveries(ctx context.Context, arg ClaimDueDeliveriesParams) ([]ClaimDueDeliveriesRow, error)
+	// Pushes due_at of up to batch_size due digests forward by the lease and
+	// returns them. next_send_at still holds the slot being sent. Unconfirmed
+	// digests are never due; one whose first slot passed before it was confirmed
+	// is sent right away.
+	ClaimDueDigests(ctx context.Context, arg ClaimDueDigestsParams) ([]DigestSubscription, error)
+	// Pushes next_fetch_at of up to batch_size due rows forward by the lease and
+	// returns them. Rows locked by another scheduler are skipped, and a claim that
+	// is never recorded expires with the lease.
+	ClaimDueWatchedLocations(ctx context.Context, arg ClaimDueWatchedLocationsParams) ([]WatchedLocation, error)
+	// Pairs every daily forecast up to max_lead_days ahead, made since the given
+	// time minus max_lead_days, with the current conditions observed at the same
+	// location on the day it was for, in the location's time zone, and stores the
+	// error statistics. Observations are averaged per location and day; tempmin
+	// and tempmax are scored against the lowest and highest observed temperature.
+	ComputeForecastVerification(ctx context.Context, arg ComputeForecastVerificationParams) (int64, error)
+	// Confirms the digest with the given token. Confirming twice keeps the first
+	// time.
+	ConfirmDigest(ctx context.Context, confirmToken pgtype.Text) (DigestSubscription, error)
+	CountDownsampleHistory(ctx context.Context, queryTime pgtype.Timestamptz) (int64, error)
+	CountDownsampleSnapshots(ctx context.Context, fetchedAt pgtype.Timestamptz) (int64, error)
+	CountExpiredHistory(ctx context.Context, queryTime pgtype.Timestamptz) (int64, error)
+	CountExpiredSnapshots(ctx context.Context, fetchedAt pgtype.Timestamptz) (int64, error)
+	CreateDigestSubscription(ctx context.Context, arg CreateDigestSubscriptionParams) (DigestSubscription, error)
+	CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error)
+	CreateWatchedLocation(ctx context.Context, arg CreateWatchedLocationParams) (WatchedLocation, error)
+	DeleteDigestSubscription(ctx context.Context, id int32) (int64, error)
+	DeleteExpiredHistory(ctx context.Context, arg DeleteExpiredHistoryParams) (int64, error)
+	DeleteExpiredSnapshots(ctx context.Context, arg DeleteExpiredSnapshotsParams) (int64, error)
+	DeleteForecastVerification(ctx context.Context) error
+	DeleteSubscription(ctx context.Context, id int32) (int64, error)
+	DeleteWatchedLocation(ctx context.Context, id int32) (int64, error)
+	DownsampleHistory(ctx context.Context, arg DownsampleHistoryParams) (int64, error)
+	DownsampleSnapshots(ctx context.Context, arg DownsampleSnapshotsParams) (int64, error)
+	// Queues a notification unless one for the same event already exists.
+	EnqueueDelivery(ctx context.Context, arg EnqueueDeliveryParams) (int64, error)
+	GetDigestSubscription(ctx context.Context, id int32) (DigestSubscription, error)
+	GetLatestCoordinatesForCity(ctx context.Context, city string) (GetLatestCoordinatesForCityRow, error)
+	GetLatestSnapshot(ctx context.Context, locationID int32) (ForecastSnapshot, error)
+	GetLocationByCoordinates(ctx context.Context, arg GetLocationByCoordinatesParams) (Location, error)
 	GetRecentWeather(ctx context.Context, arg GetRecentWeatherParams) ([]WeatherQueryHistory, error)
+	GetStorageStats(ctx context.Context) (GetStorageStatsRow, error)
+	GetSubscription(ctx context.Context, id int32) (Subscription, error)
+	GetWatchedLocation(ctx context.Context, id int32) (WatchedLocation, error)
+	// Summarizes the current conditions stored within radius_km of a point on
+	// days of the year within window_days of the given time, in any year, before
+	// the given time. Queries are averaged per day first so that a busy day
+	// counts once; samples is the number of days.
+	GetWeatherBaseline(ctx context.Context, arg GetWeatherBaselineParams) (GetWeatherBaselineRow, error)
 	GetWeatherByLocation(ctx context.Context, city string) (WeatherQueryHistory, error)
+	InsertDailyValues(ctx context.Context, arg []InsertDailyValuesParams) (int64, error)
+	InsertDigestDelivery(ctx context.Context, arg InsertDigestDeliveryParams) error
+	InsertForecastSnapshot(ctx context.Context, arg InsertForecastSnapshotParams) (ForecastSnapshot, error)
+	InsertHourlyValues(ctx context.Context, arg []InsertHourlyValuesParams) (int64, error)
 	InsertWeatherQuery(ctx context.Context, arg InsertWeatherQueryParams) (WeatherQueryHistory, error)
+	ListDailyValues(ctx context.Context, snapshotID int64) ([]DailyValue, error)
+	ListDeliveries(ctx context.Context, arg ListDeliveriesParams) ([]WebhookDelivery, error)
+	ListDigestDeliveries(ctx context.Context, arg ListDigestDeliveriesParams) ([]DigestDelivery, error)
+	ListForecastVerification(ctx context.Context, arg ListForecastVerificationParams) ([]ForecastVerification, error)
+	ListHourlyValues(ctx context.Context, snapshotID int64) ([]HourlyValue, error)
+	// Subscriptions for the named city, or with a coordinate within radius_km of
+	// the given point.
+	ListSubscriptionsForLocation(ctx context.Context, arg ListSubscriptionsForLocationParams) ([]Subscription, error)
+	ListUnmigratedHistory(ctx context.Context, arg ListUnmigratedHistoryParams) ([]WeatherQueryHistory, error)
+	ListWatchedLocations(ctx context.Context) ([]WatchedLocation, error)
+	ListWeatherHistory(ctx context.Context, arg ListWeatherHistoryParams) ([]WeatherQueryHistory, error)
+	ListWeatherHistorySummaries(ctx context.Context, arg ListWeatherHistorySummariesParams) ([]ListWeatherHistorySummariesRow, error)
+	MarkDeliveryDelivered(ctx context.Context, arg MarkDeliveryDeliveredParams) error
+	// Records a failed attempt. The delivery is retried after retry_seconds, or
+	// moves to the dead state if dead is set.
+	MarkDeliveryFailed(ctx context.Context, arg MarkDeliveryFailedParams) error
+	RecordWatchedFetch(ctx context.Context, arg RecordWatchedFetchParams) error
+	RetryDigest(ctx context.Context, arg RetryDigestParams) error
+	// Stops the digest with the given token. Unsubscribing twice keeps the first
+	// time.
+	UnsubscribeDigest(ctx context.Context, unsubscribeToken string) (DigestSubscription, error)
+	UpdateWatchedLocation(ctx context.Context, arg UpdateWatchedLocationParams) (WatchedLocation, error)
+	UpsertLocation(ctx context.Context, arg UpsertLocationParams) (Location, error)
 }
 
 var _ Querier = (*Queries)(nil)
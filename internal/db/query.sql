@@ -1,17 +1,516 @@
 -- name: GetWeatherByLocation :one
-SELECT id, city, query_time, weather_data
+SELECT id, city, query_time, weather_data, latitude, longitude
 FROM weather_query_history
 WHERE city = $1
 ORDER BY query_time DESC
 LIMIT 1;
 
 -- name: InsertWeatherQuery :one
-INSERT INTO weather_query_history (city, weather_data)
-VALUES ($1, $2::jsonb)
-RETURNING id, city, query_time, weather_data;
+INSERT INTO weather_query_history (city, weather_data, latitude, longitude)
+VALUES ($1, $2::jsonb, $3, $4)
+RETURNING id, city, query_time, weather_data, latitude, longitude;
 
 -- name: GetRecentWeather :many
-SELECT id, city, query_time, weather_data
+SELECT id, city, query_time, weather_data, latitude, longitude
 FROM weather_query_history
 WHERE city = $1 AND query_time >= $2
-ORDER BY query_time DESC;
\ No newline at end of file
+ORDER BY query_time DESC;
+
+-- name: ListWeatherHistory :many
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE (sqlc.narg('city')::text IS NULL OR city = sqlc.narg('city')::text)
+  AND (sqlc.narg('radius_km')::float8 IS NULL OR (
+        latitude IS NOT NULL AND longitude IS NOT NULL AND
+        2 * 6371 * asin(sqrt(
+            power(sin(radians(latitude - sqlc.narg('latitude')::float8) / 2), 2) +
+            cos(radians(sqlc.narg('latitude')::float8)) * cos(radians(latitude)) *
+            power(sin(radians(longitude - sqlc.narg('longitude')::float8) / 2), 2)
+        )) <= sqlc.narg('radius_km')::float8))
+  AND (sqlc.narg('from_time')::timestamptz IS NULL OR query_time >= sqlc.narg('from_time')::timestamptz)
+  AND (sqlc.narg('to_time')::timestamptz IS NULL OR query_time < sqlc.narg('to_time')::timestamptz)
+  AND (sqlc.narg('cursor_time')::timestamptz IS NULL
+       OR (query_time, id) < (sqlc.narg('cursor_time')::timestamptz, sqlc.narg('cursor_id')::int))
+ORDER BY query_time DESC, id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListWeatherHistorySummaries :many
+SELECT id, city, query_time, latitude, longitude,
+       COALESCE(jsonb_array_length(weather_data->'days'), 0)::int AS days,
+       COALESCE((weather_data->'days'->0->>'temp')::real, 0)::real AS temp,
+       COALESCE((weather_data->'days'->0->>'tempmin')::real, 0)::real AS tempmin,
+       COALESCE((weather_data->'days'->0->>'tempmax')::real, 0)::real AS tempmax,
+       COALESCE((weather_data->'days'->0->>'humidity')::real, 0)::real AS humidity,
+       COALESCE((weather_data->'days'->0->>'precip')::real, 0)::real AS precip,
+       COALESCE((weather_data->'days'->0->>'windspeed')::real, 0)::real AS windspeed
+FROM weather_query_history
+WHERE (sqlc.narg('city')::text IS NULL OR city = sqlc.narg('city')::text)
+  AND (sqlc.narg('radius_km')::float8 IS NULL OR (
+        latitude IS NOT NULL AND longitude IS NOT NULL AND
+        2 * 6371 * asin(sqrt(
+            power(sin(radians(latitude - sqlc.narg('latitude')::float8) / 2), 2) +
+            cos(radians(sqlc.narg('latitude')::float8)) * cos(radians(latitude)) *
+            power(sin(radians(longitude - sqlc.narg('longitude')::float8) / 2), 2)
+        )) <= sqlc.narg('radius_km')::float8))
+  AND (sqlc.narg('from_time')::timestamptz IS NULL OR query_time >= sqlc.narg('from_time')::timestamptz)
+  AND (sqlc.narg('to_time')::timestamptz IS NULL OR query_time < sqlc.narg('to_time')::timestamptz)
+  AND (sqlc.narg('cursor_time')::timestamptz IS NULL
+       OR (query_time, id) < (sqlc.narg('cursor_time')::timestamptz, sqlc.narg('cursor_id')::int))
+ORDER BY query_time DESC, id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: UpsertLocation :one
+INSERT INTO locations (name, country, latitude, longitude, timezone)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (latitude, longitude) DO UPDATE
+SET name = COALESCE(NULLIF(EXCLUDED.name, ''), locations.name),
+    country = COALESCE(NULLIF(EXCLUDED.country, ''), locations.country),
+    timezone = COALESCE(NULLIF(EXCLUDED.timezone, ''), locations.timezone)
+RETURNING id, name, country, latitude, longitude, timezone, created_at;
+
+-- name: GetLocationByCoordinates :one
+SELECT id, name, country, latitude, longitude, timezone, created_at
+FROM locations
+WHERE latitude = $1 AND longitude = $2;
+
+-- name: InsertForecastSnapshot :one
+INSERT INTO forecast_snapshots (location_id, provider, fetched_at, history_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, location_id, provider, fetched_at, history_id;
+
+-- name: InsertDailyValues :copyfrom
+INSERT INTO daily_values (snapshot_id, position, valid_at, temp, tempmin, tempmax, humidity, precip, snow, snowdepth, windspeed)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: InsertHourlyValues :copyfrom
+INSERT INTO hourly_values (snapshot_id, day_position, position, valid_at, temp, tempmin, tempmax, humidity, precip, snow, snowdepth, windspeed)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetLatestSnapshot :one
+SELECT id, location_id, provider, fetched_at, history_id
+FROM forecast_snapshots
+WHERE location_id = $1
+ORDER BY fetched_at DESC
+LIMIT 1;
+
+-- name: ListDailyValues :many
+SELECT snapshot_id, position, valid_at, temp, tempmin, tempmax, humidity, precip, snow, snowdepth, windspeed
+FROM daily_values
+WHERE snapshot_id = $1
+ORDER BY position;
+
+-- name: ListHourlyValues :many
+SELECT snapshot_id, day_position, position, valid_at, temp, tempmin, tempmax, humidity, precip, snow, snowdepth, windspeed
+FROM hourly_values
+WHERE snapshot_id = $1
+ORDER BY day_position, position;
+
+-- name: ListUnmigratedHistory :many
+SELECT h.id, h.city, h.query_time, h.weather_data, h.latitude, h.longitude
+FROM weather_query_history h
+WHERE h.id > sqlc.arg('after_id')
+  AND NOT EXISTS (SELECT 1 FROM forecast_snapshots s WHERE s.history_id = h.id)
+ORDER BY h.id
+LIMIT sqlc.arg('batch_size');
+
+-- name: GetLatestCoordinatesForCity :one
+SELECT latitude::float8 AS latitude, longitude::float8 AS longitude
+FROM weather_query_history
+WHERE city = $1 AND latitude IS NOT NULL AND longitude IS NOT NULL
+ORDER BY query_time DESC
+LIMIT 1;
+
+-- name: CountExpiredSnapshots :one
+SELECT count(*) FROM forecast_snapshots WHERE fetched_at < $1;
+
+-- name: DeleteExpiredSnapshots :execrows
+DELETE FROM forecast_snapshots
+WHERE id IN (
+    SELECT fs.id FROM forecast_snapshots fs
+    WHERE fs.fetched_at < sqlc.arg('before')
+    LIMIT sqlc.arg('batch_size')
+);
+
+-- name: CountDownsampleSnapshots :one
+SELECT count(*) FROM (
+    SELECT row_number() OVER (
+        PARTITION BY fs.location_id, date_trunc('day', fs.fetched_at)
+        ORDER BY fs.fetched_at DESC, fs.id DESC
+    ) AS rn
+    FROM forecast_snapshots fs
+    WHERE fs.fetched_at < $1
+) ranked
+WHERE rn > 1;
+
+-- name: DownsampleSnapshots :execrows
+DELETE FROM forecast_snapshots
+WHERE id IN (
+    SELECT id FROM (
+        SELECT fs.id, row_number() OVER (
+            PARTITION BY fs.location_id, date_trunc('day', fs.fetched_at)
+            ORDER BY fs.fetched_at DESC, fs.id DESC
+        ) AS rn
+        FROM forecast_snapshots fs
+        WHERE fs.fetched_at < sqlc.arg('before')
+    ) ranked
+    WHERE rn > 1
+    LIMIT sqlc.arg('batch_size')
+);
+
+-- name: CountExpiredHistory :one
+SELECT count(*) FROM weather_query_history WHERE query_time < $1;
+
+-- name: DeleteExpiredHistory :execrows
+DELETE FROM weather_query_history
+WHERE id IN (
+    SELECT h.id FROM weather_query_history h
+    WHERE h.query_time < sqlc.arg('before')
+    LIMIT sqlc.arg('batch_size')
+);
+
+-- name: CountDownsampleHistory :one
+SELECT count(*) FROM (
+    SELECT row_number() OVER (
+        PARTITION BY CASE WHEN h.latitude IS NULL OR h.longitude IS NULL THEN h.city END,
+            round(h.latitude::numeric, 4), round(h.longitude::numeric, 4),
+            date_trunc('day', h.query_time)
+        ORDER BY h.query_time DESC, h.id DESC
+    ) AS rn
+    FROM weather_query_history h
+    WHERE h.query_time < $1
+) ranked
+WHERE rn > 1;
+
+-- name: DownsampleHistory :execrows
+DELETE FROM weather_query_history
+WHERE id IN (
+    SELECT id FROM (
+        SELECT h.id, row_number() OVER (
+            PARTITION BY CASE WHEN h.latitude IS NULL OR h.longitude IS NULL THEN h.city END,
+                round(h.latitude::numeric, 4), round(h.longitude::numeric, 4),
+                date_trunc('day', h.query_time)
+            ORDER BY h.query_time DESC, h.id DESC
+        ) AS rn
+        FROM weather_query_history h
+        WHERE h.query_time < sqlc.arg('before')
+    ) ranked
+    WHERE rn > 1
+    LIMIT sqlc.arg('batch_size')
+);
+
+-- name: GetStorageStats :one
+SELECT COUNT(*) AS queries,
+       COUNT(DISTINCT city) AS cities,
+       (SELECT COUNT(*) FROM locations) AS locations,
+       MIN(query_time)::timestamptz AS oldest_query,
+       MAX(query_time)::timestamptz AS newest_query
+FROM weather_query_history;
+
+-- name: ListWatchedLocations :many
+SELECT * FROM watched_locations
+ORDER BY id;
+
+-- name: GetWatchedLocation :one
+SELECT * FROM watched_locations
+WHERE id = $1;
+
+-- name: CreateWatchedLocation :one
+INSERT INTO watched_locations (name, city, latitude, longitude, interval_seconds)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateWatchedLocation :one
+UPDATE watched_locations
+SET name = $2,
+    city = $3,
+    latitude = $4,
+    longitude = $5,
+    interval_seconds = $6,
+    next_fetch_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWatchedLocation :execrows
+DELETE FROM watched_locations
+WHERE id = $1;
+
+-- name: ClaimDueWatchedLocations :many
+-- Pushes next_fetch_at of up to batch_size due rows forward by the lease and
+-- returns them. Rows locked by another scheduler are skipped, and a claim that
+-- is never recorded expires with the lease.
+UPDATE watched_locations w
+SET next_fetch_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('lease_seconds')::float8)
+WHERE w.id IN (
+    SELECT d.id FROM watched_locations d
+    WHERE d.next_fetch_at <= CURRENT_TIMESTAMP
+    ORDER BY d.next_fetch_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED)
+RETURNING w.*;
+
+-- name: RecordWatchedFetch :exec
+UPDATE watched_locations
+SET last_fetched_at = CASE WHEN sqlc.arg('succeeded')::bool THEN CURRENT_TIMESTAMP ELSE last_fetched_at END,
+    last_error = sqlc.arg('last_error'),
+    next_fetch_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('delay_seconds')::float8)
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteForecastVerification :exec
+DELETE FROM forecast_verification;
+
+-- name: ComputeForecastVerification :execrows
+-- Pairs every daily forecast up to max_lead_days ahead, made since the given
+-- time minus max_lead_days, with the current conditions observed at the same
+-- location on the day it was for, in the location's time zone, and stores the
+-- error statistics. Observations are averaged per location and day; tempmin
+-- and tempmax are scored against the lowest and highest observed temperature.
+WITH observations AS (
+    SELECT fs.location_id,
+           (fs.fetched_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date AS day,
+           avg(dv.temp) AS temp,
+           min(dv.temp) AS tempmin,
+           max(dv.temp) AS tempmax,
+           avg(dv.humidity) AS humidity,
+           avg(dv.windspeed) AS windspeed
+    FROM forecast_snapshots fs
+    JOIN locations l ON l.id = fs.location_id
+    JOIN daily_values dv ON dv.snapshot_id = fs.id AND dv.position = 0
+    WHERE fs.fetched_at >= sqlc.arg('since')::timestamptz
+    GROUP BY fs.location_id, day
+), forecasts AS (
+    SELECT fs.location_id,
+           fs.provider,
+           (dv.valid_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date AS day,
+           (dv.valid_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date
+             - (fs.fetched_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date AS lead_days,
+           dv.temp, dv.tempmin, dv.tempmax, dv.humidity, dv.windspeed
+    FROM forecast_snapshots fs
+    JOIN locations l ON l.id = fs.location_id
+    JOIN daily_values dv ON dv.snapshot_id = fs.id AND dv.position > 0
+    WHERE fs.fetched_at >= sqlc.arg('since')::timestamptz - make_interval(days => sqlc.arg('max_lead_days')::int)
+), pairs AS (
+    SELECT f.provider,
+           f.lead_days,
+           f.temp - o.temp AS temp,
+           f.tempmin - o.tempmin AS tempmin,
+           f.tempmax - o.tempmax AS tempmax,
+           f.humidity - o.humidity AS humidity,
+           f.windspeed - o.windspeed AS windspeed
+    FROM forecasts f
+    JOIN observations o ON o.location_id = f.location_id AND o.day = f.day
+    WHERE f.lead_days BETWEEN 1 AND sqlc.arg('max_lead_days')::int
+), errors AS (
+    SELECT p.provider, p.lead_days, 'temp' AS field, p.temp AS error FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'tempmin', p.tempmin FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'tempmax', p.tempmax FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'humidity', p.humidity FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'windspeed', p.windspeed FROM pairs p
+)
+INSERT INTO forecast_verification (provider, field, lead_days, samples, mae, bias, rmse, computed_at)
+SELECT provider, field, lead_days, count(*), avg(abs(error)), avg(error), sqrt(avg(error * error)), CURRENT_TIMESTAMP
+FROM errors
+GROUP BY provider, field, lead_days;
+
+-- name: ListForecastVerification :many
+SELECT provider, field, lead_days, samples, mae, bias, rmse, computed_at
+FROM forecast_verification
+WHERE (sqlc.narg('provider')::text IS NULL OR provider = sqlc.narg('provider')::text)
+  AND (sqlc.narg('field')::text IS NULL OR field = sqlc.narg('field')::text)
+  AND (sqlc.narg('lead_days')::int IS NULL OR lead_days = sqlc.narg('lead_days')::int)
+ORDER BY provider, field, lead_days;
+
+-- name: GetWeatherBaseline :one
+-- Summarizes the current conditions stored within radius_km of a point on
+-- days of the year within window_days of the given time, in any year, before
+-- the given time. Queries are averaged per day first so that a busy day
+-- counts once; samples is the number of days.
+WITH daily AS (
+    SELECT date_trunc('day', h.query_time) AS day,
+           avg((h.weather_data->'days'->0->>'temp')::float8) AS temp,
+           avg((h.weather_data->'days'->0->>'precip')::float8) AS precip,
+           avg((h.weather_data->'days'->0->>'windspeed')::float8) AS windspeed
+    FROM weather_query_history h
+    WHERE h.query_time < sqlc.arg('at')::timestamptz
+      AND h.latitude IS NOT NULL AND h.longitude IS NOT NULL
+      -- A degree of latitude is 111.195 km on the sphere below, so this band
+      -- holds every match and lets idx_weather_query_history_latitude narrow
+      -- the scan before the distance is computed.
+      AND h.latitude BETWEEN sqlc.arg('latitude')::float8 - sqlc.arg('radius_km')::float8 / 111.195
+                         AND sqlc.arg('latitude')::float8 + sqlc.arg('radius_km')::float8 / 111.195
+      AND 2 * 6371 * asin(sqrt(
+            power(sin(radians(h.latitude - sqlc.arg('latitude')::float8) / 2), 2) +
+            cos(radians(sqlc.arg('latitude')::float8)) * cos(radians(h.latitude)) *
+            power(sin(radians(h.longitude - sqlc.arg('longitude')::float8) / 2), 2)
+          )) <= sqlc.arg('radius_km')::float8
+      AND least(
+            abs(extract(doy FROM h.query_time) - extract(doy FROM sqlc.arg('at')::timestamptz)),
+            365 - abs(extract(doy FROM h.query_time) - extract(doy FROM sqlc.arg('at')::timestamptz))
+          ) <= sqlc.arg('window_days')::int
+    GROUP BY day
+)
+SELECT count(*)::int AS samples,
+       COALESCE(avg(d.temp), 0)::float8 AS temp_mean,
+       COALESCE(stddev_samp(d.temp), 0)::float8 AS temp_stddev,
+       COALESCE(avg(d.precip), 0)::float8 AS precip_mean,
+       COALESCE(stddev_samp(d.precip), 0)::float8 AS precip_stddev,
+       COALESCE(avg(d.windspeed), 0)::float8 AS windspeed_mean,
+       COALESCE(stddev_samp(d.windspeed), 0)::float8 AS windspeed_stddev
+FROM daily d;
+
+-- name: CreateSubscription :one
+INSERT INTO subscriptions (city, latitude, longitude, condition, days, callback_url, secret)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetSubscription :one
+SELECT * FROM subscriptions
+WHERE id = $1;
+
+-- name: DeleteSubscription :execrows
+DELETE FROM subscriptions
+WHERE id = $1;
+
+-- name: ListSubscriptionsForLocation :many
+-- Subscriptions for the named city, or with a coordinate within radius_km of
+-- the given point.
+SELECT * FROM subscriptions s
+WHERE (s.city <> '' AND lower(s.city) = ANY(sqlc.arg('cities')::text[]))
+   OR (s.latitude IS NOT NULL AND s.longitude IS NOT NULL AND
+       2 * 6371 * asin(sqrt(
+           power(sin(radians(s.latitude - sqlc.arg('latitude')::float8) / 2), 2) +
+           cos(radians(sqlc.arg('latitude')::float8)) * cos(radians(s.latitude)) *
+           power(sin(radians(s.longitude - sqlc.arg('longitude')::float8) / 2), 2)
+       )) <= sqlc.arg('radius_km')::float8)
+ORDER BY s.id;
+
+-- name: EnqueueDelivery :execrows
+-- Queues a notification unless one for the same event already exists.
+INSERT INTO webhook_deliveries (subscription_id, event_key, payload)
+VALUES ($1, $2, $3)
+ON CONFLICT (subscription_id, event_key) DO NOTHING;
+
+-- name: ClaimDueDeliveries :many
+-- Pushes next_attempt_at of up to batch_size due deliveries forward by the
+-- lease and returns them with their subscription's callback.
+UPDATE webhook_deliveries d
+SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('lease_seconds')::float8)
+FROM subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+    SELECT w.id FROM webhook_deliveries w
+    WHERE w.status = 'pending' AND w.next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY w.next_attempt_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED)
+RETURNING d.id, d.subscription_id, d.event_key, d.payload, d.attempts, s.callback_url, s.secret;
+
+-- name: MarkDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = '',
+    delivered_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: MarkDeliveryFailed :exec
+-- Records a failed attempt. The delivery is retried after retry_seconds, or
+-- moves to the dead state if dead is set.
+UPDATE webhook_deliveries
+SET status = CASE WHEN sqlc.arg('dead')::bool THEN 'dead' ELSE 'pending' END,
+    attempts = attempts + 1,
+    last_status_code = sqlc.narg('status_code'),
+    last_error = sqlc.arg('last_error'),
+    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('retry_seconds')::float8)
+WHERE id = sqlc.arg('id');
+
+-- name: ListDeliveries :many
+SELECT id, subscription_id, event_key, payload, status, attempts, next_attempt_at,
+       last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: CreateDigestSubscription :one
+INSERT INTO digest_subscriptions (email, locations, frequency, weekday, send_time, timezone, unsubscribe_token, confirm_token, next_send_at, due_at)
+VALUES (sqlc.arg('email'), sqlc.arg('locations'), sqlc.arg('frequency'), sqlc.narg('weekday'), sqlc.arg('send_time'),
+        sqlc.arg('timezone'), sqlc.arg('unsubscribe_token'), sqlc.arg('confirm_token'), sqlc.arg('next_send_at'), sqlc.arg('next_send_at'))
+RETURNING *;
+
+-- name: GetDigestSubscription :one
+SELECT * FROM digest_subscriptions
+WHERE id = $1;
+
+-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE id = $1;
+
+-- name: UnsubscribeDigest :one
+-- Stops the digest with the given token. Unsubscribing twice keeps the first
+-- time.
+UPDATE digest_subscriptions
+SET unsubscribed_at = COALESCE(unsubscribed_at, CURRENT_TIMESTAMP)
+WHERE unsubscribe_token = $1
+RETURNING *;
+
+-- name: ConfirmDigest :one
+-- Confirms the digest with the given token. Confirming twice keeps the first
+-- time.
+UPDATE digest_subscriptions
+SET confirmed_at = COALESCE(confirmed_at, CURRENT_TIMESTAMP)
+WHERE confirm_token = $1
+RETURNING *;
+
+-- name: ClaimDueDigests :many
+-- Pushes due_at of up to batch_size due digests forward by the lease and
+-- returns them. next_send_at still holds the slot being sent. Unconfirmed
+-- digests are never due; one whose first slot passed before it was confirmed
+-- is sent right away.
+UPDATE digest_subscriptions s
+SET due_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('lease_seconds')::float8)
+WHERE s.id IN (
+    SELECT d.id FROM digest_subscriptions d
+    WHERE d.unsubscribed_at IS NULL AND d.confirmed_at IS NOT NULL AND d.due_at <= CURRENT_TIMESTAMP
+    ORDER BY d.due_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED)
+RETURNING s.*;
+
+-- name: InsertDigestDelivery :exec
+INSERT INTO digest_deliveries (digest_id, scheduled_for, status, attempts, error)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (digest_id, scheduled_for) DO UPDATE
+SET status = EXCLUDED.status,
+    attempts = EXCLUDED.attempts,
+    error = EXCLUDED.error,
+    created_at = CURRENT_TIMESTAMP;
+
+-- name: AdvanceDigest :exec
+-- Moves a digest on to its next slot after the current one was sent or given
+-- up on.
+UPDATE digest_subscriptions
+SET next_send_at = sqlc.arg('next_send_at'),
+    due_at = sqlc.arg('next_send_at'),
+    attempts = 0,
+    last_sent_at = CASE WHEN sqlc.arg('sent')::bool THEN CURRENT_TIMESTAMP ELSE last_sent_at END,
+    last_error = sqlc.arg('last_error')
+WHERE id = sqlc.arg('id');
+
+-- name: RetryDigest :exec
+UPDATE digest_subscriptions
+SET attempts = attempts + 1,
+    due_at = CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('retry_seconds')::float8),
+    last_error = sqlc.arg('last_error')
+WHERE id = sqlc.arg('id');
+
+-- name: ListDigestDeliveries :many
+SELECT * FROM digest_deliveries
+WHERE digest_id = $1
+ORDER BY scheduled_for DESC
+LIMIT $2;
@@ -11,20 +11,1498 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const advanceDigest = `-- name: AdvanceDigest :exec
+UPDATE digest_subscriptions
+SET next_send_at = $1,
+    due_at = $1,
+    attempts = 0,
+    last_sent_at = CASE WHEN $2::bool THEN CURRENT_TIMESTAMP ELSE last_sent_at END,
+    last_error = $3
+WHERE id = $4
+`
+
+type AdvanceDigestParams struct {
+	NextSendAt pgtype.Timestamptz `db:"next_send_at" json:"next_send_at"`
+	Sent       bool               `db:"sent" json:"sent"`
+	LastError  string             `db:"last_error" json:"last_error"`
+	ID         int32              `db:"id" json:"id"`
+}
+
+// Moves a digest on to its next slot after the current one was sent or given
+// up on.
+func (q *Queries) AdvanceDigest(ctx context.Context, arg AdvanceDigestParams) error {
+	_, err := q.db.Exec(ctx, advanceDigest,
+		arg.NextSendAt,
+		arg.Sent,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
+const claimDueDeliveries = `-- name: ClaimDueDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $1::float8)
+FROM subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+    SELECT w.id FROM webhook_deliveries w
+    WHERE w.status = 'pending' AND w.next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY w.next_attempt_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED)
+RETURNING d.id, d.subscription_id, d.event_key, d.payload, d.attempts, s.callback_url, s.secret
+`
+
+type ClaimDueDeliveriesParams struct {
+	LeaseSeconds float64 `db:"lease_seconds" json:"lease_seconds"`
+	BatchSize    int32   `db:"batch_size" json:"batch_size"`
+}
+
+type ClaimDueDeliveriesRow struct {
+	ID             int64  `db:"id" json:"id"`
+	SubscriptionID int32  `db:"subscription_id" json:"subscription_id"`
+	EventKey       string `db:"event_key" json:"event_key"`
+	Payload        []byte `db:"payload" json:"payload"`
+	Attempts       int32  `db:"attempts" json:"attempts"`
+	CallbackUrl    string `db:"callback_url" json:"callback_url"`
+	Secret         string `db:"secret" json:"secret"`
+}
+
+// Pushes next_attempt_at of up to batch_size due deliveries forward by the
+// lease and returns them with their subscription's callback.
+func (q *Queries) ClaimDueDeliveries(ctx context.Context, arg ClaimDueDeliveriesParams) ([]ClaimDueDeliveriesRow, error) {
+	rows, err := q.db.Query(ctx, claimDueDeliveries, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimDueDeliveriesRow
+	for rows.Next() {
+		var i ClaimDueDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventKey,
+			&i.Payload,
+			&i.Attempts,
+			&i.CallbackUrl,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimDueDigests = `-- name: ClaimDueDigests :many
+UPDATE digest_subscriptions s
+SET due_at = CURRENT_TIMESTAMP + make_interval(secs => $1::float8)
+WHERE s.id IN (
+    SELECT d.id FROM digest_subscriptions d
+    WHERE d.unsubscribed_at IS NULL AND d.confirmed_at IS NOT NULL AND d.due_at <= CURRENT_TIMESTAMP
+    ORDER BY d.due_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED)
+RETURNING s.id, s.email, s.locations, s.frequency, s.weekday, s.send_time, s.timezone, s.unsubscribe_token, s.next_send_at, s.due_at, s.attempts, s.last_sent_at, s.last_error, s.unsubscribed_at, s.created_at, s.confirm_token, s.confirmed_at
+`
+
+type ClaimDueDigestsParams struct {
+	LeaseSeconds float64 `db:"lease_seconds" json:"lease_seconds"`
+	BatchSize    int32   `db:"batch_size" json:"batch_size"`
+}
+
+// Pushes due_at of up to batch_size due digests forward by the lease and
+// returns them. next_send_at still holds the slot being sent. Unconfirmed
+// digests are never due; one whose first slot passed before it was confirmed
+// is sent right away.
+func (q *Queries) ClaimDueDigests(ctx context.Context, arg ClaimDueDigestsParams) ([]DigestSubscription, error) {
+	rows, err := q.db.Query(ctx, claimDueDigests, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DigestSubscription
+	for rows.Next() {
+		var i DigestSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Locations,
+			&i.Frequency,
+			&i.Weekday,
+			&i.SendTime,
+			&i.Timezone,
+			&i.UnsubscribeToken,
+			&i.NextSendAt,
+			&i.DueAt,
+			&i.Attempts,
+			&i.LastSentAt,
+			&i.LastError,
+			&i.UnsubscribedAt,
+			&i.CreatedAt,
+			&i.ConfirmToken,
+			&i.ConfirmedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimDueWatchedLocations = `-- name: ClaimDueWatchedLocations :many
+UPDATE watched_locations w
+SET next_fetch_at = CURRENT_TIMESTAMP + make_interval(secs => $1::float8)
+WHERE w.id IN (
+    SELECT d.id FROM watched_locations d
+    WHERE d.next_fetch_at <= CURRENT_TIMESTAMP
+    ORDER BY d.next_fetch_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED)
+RETURNING w.id, w.name, w.city, w.latitude, w.longitude, w.interval_seconds, w.next_fetch_at, w.last_fetched_at, w.last_error, w.created_at, w.updated_at
+`
+
+type ClaimDueWatchedLocationsParams struct {
+	LeaseSeconds float64 `db:"lease_seconds" json:"lease_seconds"`
+	BatchSize    int32   `db:"batch_size" json:"batch_size"`
+}
+
+// Pushes next_fetch_at of up to batch_size due rows forward by the lease and
+// returns them. Rows locked by another scheduler are skipped, and a claim that
+// is never recorded expires with the lease.
+func (q *Queries) ClaimDueWatchedLocations(ctx context.Context, arg ClaimDueWatchedLocationsParams) ([]WatchedLocation, error) {
+	rows, err := q.db.Query(ctx, claimDueWatchedLocations, arg.LeaseSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WatchedLocation
+	for rows.Next() {
+		var i WatchedLocation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.City,
+			&i.Latitude,
+			&i.Longitude,
+			&i.IntervalSeconds,
+			&i.NextFetchAt,
+			&i.LastFetchedAt,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const computeForecastVerification = `-- name: ComputeForecastVerification :execrows
+WITH observations AS (
+    SELECT fs.location_id,
+           (fs.fetched_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date AS day,
+           avg(dv.temp) AS temp,
+           min(dv.temp) AS tempmin,
+           max(dv.temp) AS tempmax,
+           avg(dv.humidity) AS humidity,
+           avg(dv.windspeed) AS windspeed
+    FROM forecast_snapshots fs
+    JOIN locations l ON l.id = fs.location_id
+    JOIN daily_values dv ON dv.snapshot_id = fs.id AND dv.position = 0
+    WHERE fs.fetched_at >= $1::timestamptz
+    GROUP BY fs.location_id, day
+), forecasts AS (
+    SELECT fs.location_id,
+           fs.provider,
+           (dv.valid_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date AS day,
+           (dv.valid_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date
+             - (fs.fetched_at AT TIME ZONE COALESCE(NULLIF(l.timezone, ''), 'UTC'))::date AS lead_days,
+           dv.temp, dv.tempmin, dv.tempmax, dv.humidity, dv.windspeed
+    FROM forecast_snapshots fs
+    JOIN locations l ON l.id = fs.location_id
+    JOIN daily_values dv ON dv.snapshot_id = fs.id AND dv.position > 0
+    WHERE fs.fetched_at >= $1::timestamptz - make_interval(days => $2::int)
+), pairs AS (
+    SELECT f.provider,
+           f.lead_days,
+           f.temp - o.temp AS temp,
+           f.tempmin - o.tempmin AS tempmin,
+           f.tempmax - o.tempmax AS tempmax,
+           f.humidity - o.humidity AS humidity,
+           f.windspeed - o.windspeed AS windspeed
+    FROM forecasts f
+    JOIN observations o ON o.location_id = f.location_id AND o.day = f.day
+    WHERE f.lead_days BETWEEN 1 AND $2::int
+), errors AS (
+    SELECT p.provider, p.lead_days, 'temp' AS field, p.temp AS error FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'tempmin', p.tempmin FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'tempmax', p.tempmax FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'humidity', p.humidity FROM pairs p
+    UNION ALL
+    SELECT p.provider, p.lead_days, 'windspeed', p.windspeed FROM pairs p
+)
+INSERT INTO forecast_verification (provider, field, lead_days, samples, mae, bias, rmse, computed_at)
+SELECT provider, field, lead_days, count(*), avg(abs(error)), avg(error), sqrt(avg(error * error)), CURRENT_TIMESTAMP
+FROM errors
+GROUP BY provider, field, lead_days
+`
+
+type ComputeForecastVerificationParams struct {
+	Since       pgtype.Timestamptz `db:"since" json:"since"`
+	MaxLeadDays int32              `db:"max_lead_days" json:"max_lead_days"`
+}
+
+// Pairs every daily forecast up to max_lead_days ahead, made since the given
+// time minus max_lead_days, with the current conditions observed at the same
+// location on the day it was for, in the location's time zone, and stores the
+// error statistics. Observations are averaged per location and day; tempmin
+// and tempmax are scored against the lowest and highest observed temperature.
+func (q *Queries) ComputeForecastVerification(ctx context.Context, arg ComputeForecastVerificationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, computeForecastVerification, arg.Since, arg.MaxLeadDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const confirmDigest = `-- name: ConfirmDigest :one
+UPDATE digest_subscriptions
+SET confirmed_at = COALESCE(confirmed_at, CURRENT_TIMESTAMP)
+WHERE confirm_token = $1
+RETURNING id, email, locations, frequency, weekday, send_time, timezone, unsubscribe_token, next_send_at, due_at, attempts, last_sent_at, last_error, unsubscribed_at, created_at, confirm_token, confirmed_at
+`
+
+// Confirms the digest with the given token. Confirming twice keeps the first
+// time.
+func (q *Queries) ConfirmDigest(ctx context.Context, confirmToken pgtype.Text) (DigestSubscription, error) {
+	row := q.db.QueryRow(ctx, confirmDigest, confirmToken)
+	var i DigestSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Locations,
+		&i.Frequency,
+		&i.Weekday,
+		&i.SendTime,
+		&i.Timezone,
+		&i.UnsubscribeToken,
+		&i.NextSendAt,
+		&i.DueAt,
+		&i.Attempts,
+		&i.LastSentAt,
+		&i.LastError,
+		&i.UnsubscribedAt,
+		&i.CreatedAt,
+		&i.ConfirmToken,
+		&i.ConfirmedAt,
+	)
+	return i, err
+}
+
+const countDownsampleHistory = `-- name: CountDownsampleHistory :one
+SELECT count(*) FROM (
+    SELECT row_number() OVER (
+        PARTITION BY CASE WHEN h.latitude IS NULL OR h.longitude IS NULL THEN h.city END,
+            round(h.latitude::numeric, 4), round(h.longitude::numeric, 4),
+            date_trunc('day', h.query_time)
+        ORDER BY h.query_time DESC, h.id DESC
+    ) AS rn
+    FROM weather_query_history h
+    WHERE h.query_time < $1
+) ranked
+WHERE rn > 1
+`
+
+func (q *Queries) CountDownsampleHistory(ctx context.Context, queryTime pgtype.Timestamptz) (int64, error) {
+	row := q.db.QueryRow(ctx, countDownsampleHistory, queryTime)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countDownsampleSnapshots = `-- name: CountDownsampleSnapshots :one
+SELECT count(*) FROM (
+    SELECT row_number() OVER (
+        PARTITION BY fs.location_id, date_trunc('day', fs.fetched_at)
+        ORDER BY fs.fetched_at DESC, fs.id DESC
+    ) AS rn
+    FROM forecast_snapshots fs
+    WHERE fs.fetched_at < $1
+) ranked
+WHERE rn > 1
+`
+
+func (q *Queries) CountDownsampleSnapshots(ctx context.Context, fetchedAt pgtype.Timestamptz) (int64, error) {
+	row := q.db.QueryRow(ctx, countDownsampleSnapshots, fetchedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countExpiredHistory = `-- name: CountExpiredHistory :one
+SELECT count(*) FROM weather_query_history WHERE query_time < $1
+`
+
+func (q *Queries) CountExpiredHistory(ctx context.Context, queryTime pgtype.Timestamptz) (int64, error) {
+	row := q.db.QueryRow(ctx, countExpiredHistory, queryTime)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countExpiredSnapshots = `-- name: CountExpiredSnapshots :one
+SELECT count(*) FROM forecast_snapshots WHERE fetched_at < $1
+`
+
+func (q *Queries) CountExpiredSnapshots(ctx context.Context, fetchedAt pgtype.Timestamptz) (int64, error) {
+	row := q.db.QueryRow(ctx, countExpiredSnapshots, fetchedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createDigestSubscription = `-- name: CreateDigestSubscription :one
+INSERT INTO digest_subscriptions (email, locations, frequency, weekday, send_time, timezone, unsubscribe_token, confirm_token, next_send_at, due_at)
+VALUES ($1, $2, $3, $4, $5,
+        $6, $7, $8, $9, $9)
+RETURNING id, email, locations, frequency, weekday, send_time, timezone, unsubscribe_token, next_send_at, due_at, attempts, last_sent_at, last_error, unsubscribed_at, created_at, confirm_token, confirmed_at
+`
+
+type CreateDigestSubscriptionParams struct {
+	Email            string             `db:"email" json:"email"`
+	Locations        []byte             `db:"locations" json:"locations"`
+	Frequency        string             `db:"frequency" json:"frequency"`
+	Weekday          pgtype.Int2        `db:"weekday" json:"weekday"`
+	SendTime         pgtype.Time        `db:"send_time" json:"send_time"`
+	Timezone         string             `db:"timezone" json:"timezone"`
+	UnsubscribeToken string             `db:"unsubscribe_token" json:"unsubscribe_token"`
+	ConfirmToken     pgtype.Text        `db:"confirm_token" json:"confirm_token"`
+	NextSendAt       pgtype.Timestamptz `db:"next_send_at" json:"next_send_at"`
+}
+
+func (q *Queries) CreateDigestSubscription(ctx context.Context, arg CreateDigestSubscriptionParams) (DigestSubscription, error) {
+	row := q.db.QueryRow(ctx, createDigestSubscription,
+		arg.Email,
+		arg.Locations,
+		arg.Frequency,
+		arg.Weekday,
+		arg.SendTime,
+		arg.Timezone,
+		arg.UnsubscribeToken,
+		arg.ConfirmToken,
+		arg.NextSendAt,
+	)
+	var i DigestSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Locations,
+		&i.Frequency,
+		&i.Weekday,
+		&i.SendTime,
+		&i.Timezone,
+		&i.UnsubscribeToken,
+		&i.NextSendAt,
+		&i.DueAt,
+		&i.Attempts,
+		&i.LastSentAt,
+		&i.LastError,
+		&i.UnsubscribedAt,
+		&i.CreatedAt,
+		&i.ConfirmToken,
+		&i.ConfirmedAt,
+	)
+	return i, err
+}
+
+const createSubscription = `-- name: CreateSubscription :one
+INSERT INTO subscriptions (city, latitude, longitude, condition, days, callback_url, secret)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, city, latitude, longitude, condition, days, callback_url, secret, created_at
+`
+
+type CreateSubscriptionParams struct {
+	City        string        `db:"city" json:"city"`
+	Latitude    pgtype.Float8 `db:"latitude" json:"latitude"`
+	Longitude   pgtype.Float8 `db:"longitude" json:"longitude"`
+	Condition   string        `db:"condition" json:"condition"`
+	Days        int16         `db:"days" json:"days"`
+	CallbackUrl string        `db:"callback_url" json:"callback_url"`
+	Secret      string        `db:"secret" json:"secret"`
+}
+
+func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRow(ctx, createSubscription,
+		arg.City,
+		arg.Latitude,
+		arg.Longitude,
+		arg.Condition,
+		arg.Days,
+		arg.CallbackUrl,
+		arg.Secret,
+	)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.City,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Condition,
+		&i.Days,
+		&i.CallbackUrl,
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWatchedLocation = `-- name: CreateWatchedLocation :one
+INSERT INTO watched_locations (name, city, latitude, longitude, interval_seconds)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, city, latitude, longitude, interval_seconds, next_fetch_at, last_fetched_at, last_error, created_at, updated_at
+`
+
+type CreateWatchedLocationParams struct {
+	Name            string        `db:"name" json:"name"`
+	City            string        `db:"city" json:"city"`
+	Latitude        pgtype.Float8 `db:"latitude" json:"latitude"`
+	Longitude       pgtype.Float8 `db:"longitude" json:"longitude"`
+	IntervalSeconds int32         `db:"interval_seconds" json:"interval_seconds"`
+}
+
+func (q *Queries) CreateWatchedLocation(ctx context.Context, arg CreateWatchedLocationParams) (WatchedLocation, error) {
+	row := q.db.QueryRow(ctx, createWatchedLocation,
+		arg.Name,
+		arg.City,
+		arg.Latitude,
+		arg.Longitude,
+		arg.IntervalSeconds,
+	)
+	var i WatchedLocation
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.City,
+		&i.Latitude,
+		&i.Longitude,
+		&i.IntervalSeconds,
+		&i.NextFetchAt,
+		&i.LastFetchedAt,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteDigestSubscription = `-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) DeleteDigestSubscription(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDigestSubscription, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredHistory = `-- name: DeleteExpiredHistory :execrows
+DELETE FROM weather_query_history
+WHERE id IN (
+    SELECT h.id FROM weather_query_history h
+    WHERE h.query_time < $1
+    LIMIT $2
+)
+`
+
+type DeleteExpiredHistoryParams struct {
+	Before    pgtype.Timestamptz `db:"before" json:"before"`
+	BatchSize int32              `db:"batch_size" json:"batch_size"`
+}
+
+func (q *Queries) DeleteExpiredHistory(ctx context.Context, arg DeleteExpiredHistoryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredHistory, arg.Before, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredSnapshots = `-- name: DeleteExpiredSnapshots :execrows
+DELETE FROM forecast_snapshots
+WHERE id IN (
+    SELECT fs.id FROM forecast_snapshots fs
+    WHERE fs.fetched_at < $1
+    LIMIT $2
+)
+`
+
+type DeleteExpiredSnapshotsParams struct {
+	Before    pgtype.Timestamptz `db:"before" json:"before"`
+	BatchSize int32              `db:"batch_size" json:"batch_size"`
+}
+
+func (q *Queries) DeleteExpiredSnapshots(ctx context.Context, arg DeleteExpiredSnapshotsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredSnapshots, arg.Before, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteForecastVerification = `-- name: DeleteForecastVerification :exec
+DELETE FROM forecast_verification
+`
+
+func (q *Queries) DeleteForecastVerification(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteForecastVerification)
+	return err
+}
+
+const deleteSubscription = `-- name: DeleteSubscription :execrows
+DELETE FROM subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) DeleteSubscription(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSubscription, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteWatchedLocation = `-- name: DeleteWatchedLocation :execrows
+DELETE FROM watched_locations
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWatchedLocation(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWatchedLocation, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const downsampleHistory = `-- name: DownsampleHistory :execrows
+DELETE FROM weather_query_history
+WHERE id IN (
+    SELECT id FROM (
+        SELECT h.id, row_number() OVER (
+            PARTITION BY CASE WHEN h.latitude IS NULL OR h.longitude IS NULL THEN h.city END,
+                round(h.latitude::numeric, 4), round(h.longitude::numeric, 4),
+                date_trunc('day', h.query_time)
+            ORDER BY h.query_time DESC, h.id DESC
+        ) AS rn
+        FROM weather_query_history h
+        WHERE h.query_time < $1
+    ) ranked
+    WHERE rn > 1
+    LIMIT $2
+)
+`
+
+type DownsampleHistoryParams struct {
+	Before    pgtype.Timestamptz `db:"before" json:"before"`
+	BatchSize int32              `db:"batch_size" json:"batch_size"`
+}
+
+func (q *Queries) DownsampleHistory(ctx context.Context, arg DownsampleHistoryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, downsampleHistory, arg.Before, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const downsampleSnapshots = `-- name: DownsampleSnapshots :execrows
+DELETE FROM forecast_snapshots
+WHERE id IN (
+    SELECT id FROM (
+        SELECT fs.id, row_number() OVER (
+            PARTITION BY fs.location_id, date_trunc('day', fs.fetched_at)
+            ORDER BY fs.fetched_at DESC, fs.id DESC
+        ) AS rn
+        FROM forecast_snapshots fs
+        WHERE fs.fetched_at < $1
+    ) ranked
+    WHERE rn > 1
+    LIMIT $2
+)
+`
+
+type DownsampleSnapshotsParams struct {
+	Before    pgtype.Timestamptz `db:"before" json:"before"`
+	BatchSize int32              `db:"batch_size" json:"batch_size"`
+}
+
+func (q *Queries) DownsampleSnapshots(ctx context.Context, arg DownsampleSnapshotsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, downsampleSnapshots, arg.Before, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const enqueueDelivery = `-- name: EnqueueDelivery :execrows
+INSERT INTO webhook_deliveries (subscription_id, event_key, payload)
+VALUES ($1, $2, $3)
+ON CONFLICT (subscription_id, event_key) DO NOTHING
+`
+
+type EnqueueDeliveryParams struct {
+	SubscriptionID int32  `db:"subscription_id" json:"subscription_id"`
+	EventKey       string `db:"event_key" json:"event_key"`
+	Payload        []byte `db:"payload" json:"payload"`
+}
+
+// Queues a notification unless one for the same event already exists.
+func (q *Queries) EnqueueDelivery(ctx context.Context, arg EnqueueDeliveryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, enqueueDelivery, arg.SubscriptionID, arg.EventKey, arg.Payload)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getDigestSubscription = `-- name: GetDigestSubscription :one
+SELECT id, email, locations, frequency, weekday, send_time, timezone, unsubscribe_token, next_send_at, due_at, attempts, last_sent_at, last_error, unsubscribed_at, created_at, confirm_token, confirmed_at FROM digest_subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) GetDigestSubscription(ctx context.Context, id int32) (DigestSubscription, error) {
+	row := q.db.QueryRow(ctx, getDigestSubscription, id)
+	var i DigestSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Locations,
+		&i.Frequency,
+		&i.Weekday,
+		&i.SendTime,
+		&i.Timezone,
+		&i.UnsubscribeToken,
+		&i.NextSendAt,
+		&i.DueAt,
+		&i.Attempts,
+		&i.LastSentAt,
+		&i.LastError,
+		&i.UnsubscribedAt,
+		&i.CreatedAt,
+		&i.ConfirmToken,
+		&i.ConfirmedAt,
+	)
+	return i, err
+}
+
+const getLatestCoordinatesForCity = `-- name: GetLatestCoordinatesForCity :one
+SELECT latitude::float8 AS latitude, longitude::float8 AS longitude
+FROM weather_query_history
+WHERE city = $1 AND latitude IS NOT NULL AND longitude IS NOT NULL
+ORDER BY query_time DESC
+LIMIT 1
+`
+
+type GetLatestCoordinatesForCityRow struct {
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+}
+
+func (q *Queries) GetLatestCoordinatesForCity(ctx context.Context, city string) (GetLatestCoordinatesForCityRow, error) {
+	row := q.db.QueryRow(ctx, getLatestCoordinatesForCity, city)
+	var i GetLatestCoordinatesForCityRow
+	err := row.Scan(&i.Latitude, &i.Longitude)
+	return i, err
+}
+
+const getLatestSnapshot = `-- name: GetLatestSnapshot :one
+SELECT id, location_id, provider, fetched_at, history_id
+FROM forecast_snapshots
+WHERE location_id = $1
+ORDER BY fetched_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestSnapshot(ctx context.Context, locationID int32) (ForecastSnapshot, error) {
+	row := q.db.QueryRow(ctx, getLatestSnapshot, locationID)
+	var i ForecastSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.LocationID,
+		&i.Provider,
+		&i.FetchedAt,
+		&i.HistoryID,
+	)
+	return i, err
+}
+
+const getLocationByCoordinates = `-- name: GetLocationByCoordinates :one
+SELECT id, name, country, latitude, longitude, timezone, created_at
+FROM locations
+WHERE latitude = $1 AND longitude = $2
+`
+
+type GetLocationByCoordinatesParams struct {
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+}
+
+func (q *Queries) GetLocationByCoordinates(ctx context.Context, arg GetLocationByCoordinatesParams) (Location, error) {
+	row := q.db.QueryRow(ctx, getLocationByCoordinates, arg.Latitude, arg.Longitude)
+	var i Location
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Country,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Timezone,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getRecentWeather = `-- name: GetRecentWeather :many
-SELECT id, city, query_time, weather_data
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE city = $1 AND query_time >= $2
+ORDER BY query_time DESC
+`
+
+type GetRecentWeatherParams struct {
+	City      string             `db:"city" json:"city"`
+	QueryTime pgtype.Timestamptz `db:"query_time" json:"query_time"`
+}
+
+func (q *Queries) GetRecentWeather(ctx context.Context, arg GetRecentWeatherParams) ([]WeatherQueryHistory, error) {
+	rows, err := q.db.Query(ctx, getRecentWeather, arg.City, arg.QueryTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WeatherQueryHistory
+	for rows.Next() {
+		var i WeatherQueryHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.City,
+			&i.QueryTime,
+			&i.WeatherData,
+			&i.Latitude,
+			&i.Longitude,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStorageStats = `-- name: GetStorageStats :one
+SELECT COUNT(*) AS queries,
+       COUNT(DISTINCT city) AS cities,
+       (SELECT COUNT(*) FROM locations) AS locations,
+       MIN(query_time)::timestamptz AS oldest_query,
+       MAX(query_time)::timestamptz AS newest_query
+FROM weather_query_history
+`
+
+type GetStorageStatsRow struct {
+	Queries     int64              `db:"queries" json:"queries"`
+	Cities      int64              `db:"cities" json:"cities"`
+	Locations   int64              `db:"locations" json:"locations"`
+	OldestQuery pgtype.Timestamptz `db:"oldest_query" json:"oldest_query"`
+	NewestQuery pgtype.Timestamptz `db:"newest_query" json:"newest_query"`
+}
+
+func (q *Queries) GetStorageStats(ctx context.Context) (GetStorageStatsRow, error) {
+	row := q.db.QueryRow(ctx, getStorageStats)
+	var i GetStorageStatsRow
+	err := row.Scan(
+		&i.Queries,
+		&i.Cities,
+		&i.Locations,
+		&i.OldestQuery,
+		&i.NewestQuery,
+	)
+	return i, err
+}
+
+const getSubscription = `-- name: GetSubscription :one
+SELECT id, city, latitude, longitude, condition, days, callback_url, secret, created_at FROM subscriptions
+WHERE id = $1
+`
+
+func (q *Queries) GetSubscription(ctx context.Context, id int32) (Subscription, error) {
+	row := q.db.QueryRow(ctx, getSubscription, id)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.City,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Condition,
+		&i.Days,
+		&i.CallbackUrl,
+		&i.Secret,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWatchedLocation = `-- name: GetWatchedLocation :one
+SELECT id, name, city, latitude, longitude, interval_seconds, next_fetch_at, last_fetched_at, last_error, created_at, updated_at FROM watched_locations
+WHERE id = $1
+`
+
+func (q *Queries) GetWatchedLocation(ctx context.Context, id int32) (WatchedLocation, error) {
+	row := q.db.QueryRow(ctx, getWatchedLocation, id)
+	var i WatchedLocation
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.City,
+		&i.Latitude,
+		&i.Longitude,
+		&i.IntervalSeconds,
+		&i.NextFetchAt,
+		&i.LastFetchedAt,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWeatherBaseline = `-- name: GetWeatherBaseline :one
+WITH daily AS (
+    SELECT date_trunc('day', h.query_time) AS day,
+           avg((h.weather_data->'days'->0->>'temp')::float8) AS temp,
+           avg((h.weather_data->'days'->0->>'precip')::float8) AS precip,
+           avg((h.weather_data->'days'->0->>'windspeed')::float8) AS windspeed
+    FROM weather_query_history h
+    WHERE h.query_time < $1::timestamptz
+      AND h.latitude IS NOT NULL AND h.longitude IS NOT NULL
+      -- A degree of latitude is 111.195 km on the sphere below, so this band
+      -- holds every match and lets idx_weather_query_history_latitude narrow
+      -- the scan before the distance is computed.
+      AND h.latitude BETWEEN $2::float8 - $3::float8 / 111.195
+                         AND $2::float8 + $3::float8 / 111.195
+      AND 2 * 6371 * asin(sqrt(
+            power(sin(radians(h.latitude - $2::float8) / 2), 2) +
+            cos(radians($2::float8)) * cos(radians(h.latitude)) *
+            power(sin(radians(h.longitude - $4::float8) / 2), 2)
+          )) <= $3::float8
+      AND least(
+            abs(extract(doy FROM h.query_time) - extract(doy FROM $1::timestamptz)),
+            365 - abs(extract(doy FROM h.query_time) - extract(doy FROM $1::timestamptz))
+          ) <= $5::int
+    GROUP BY day
+)
+SELECT count(*)::int AS samples,
+       COALESCE(avg(d.temp), 0)::float8 AS temp_mean,
+       COALESCE(stddev_samp(d.temp), 0)::float8 AS temp_stddev,
+       COALESCE(avg(d.precip), 0)::float8 AS precip_mean,
+       COALESCE(stddev_samp(d.precip), 0)::float8 AS precip_stddev,
+       COALESCE(avg(d.windspeed), 0)::float8 AS windspeed_mean,
+       COALESCE(stddev_samp(d.windspeed), 0)::float8 AS windspeed_stddev
+FROM daily d
+`
+
+type GetWeatherBaselineParams struct {
+	At         pgtype.Timestamptz `db:"at" json:"at"`
+	Latitude   float64            `db:"latitude" json:"latitude"`
+	RadiusKm   float64            `db:"radius_km" json:"radius_km"`
+	Longitude  float64            `db:"longitude" json:"longitude"`
+	WindowDays int32              `db:"window_days" json:"window_days"`
+}
+
+type GetWeatherBaselineRow struct {
+	Samples         int32   `db:"samples" json:"samples"`
+	TempMean        float64 `db:"temp_mean" json:"temp_mean"`
+	TempStddev      float64 `db:"temp_stddev" json:"temp_stddev"`
+	PrecipMean      float64 `db:"precip_mean" json:"precip_mean"`
+	PrecipStddev    float64 `db:"precip_stddev" json:"precip_stddev"`
+	WindspeedMean   float64 `db:"windspeed_mean" json:"windspeed_mean"`
+	WindspeedStddev float64 `db:"windspeed_stddev" json:"windspeed_stddev"`
+}
+
+// Summarizes the current conditions stored within radius_km of a point on
+// days of the year within window_days of the given time, in any year, before
+// the given time. Queries are averaged per day first so that a busy day
+// counts once; samples is the number of days.
+func (q *Queries) GetWeatherBaseline(ctx context.Context, arg GetWeatherBaselineParams) (GetWeatherBaselineRow, error) {
+	row := q.db.QueryRow(ctx, getWeatherBaseline,
+		arg.At,
+		arg.Latitude,
+		arg.RadiusKm,
+		arg.Longitude,
+		arg.WindowDays,
+	)
+	var i GetWeatherBaselineRow
+	err := row.Scan(
+		&i.Samples,
+		&i.TempMean,
+		&i.TempStddev,
+		&i.PrecipMean,
+		&i.PrecipStddev,
+		&i.WindspeedMean,
+		&i.WindspeedStddev,
+	)
+	return i, err
+}
+
+const getWeatherByLocation = `-- name: GetWeatherByLocation :one
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE city = $1
+ORDER BY query_time DESC
+LIMIT 1
+`
+
+func (q *Queries) GetWeatherByLocation(ctx context.Context, city string) (WeatherQueryHistory, error) {
+	row := q.db.QueryRow(ctx, getWeatherByLocation, city)
+	var i WeatherQueryHistory
+	err := row.Scan(
+		&i.ID,
+		&i.City,
+		&i.QueryTime,
+		&i.WeatherData,
+		&i.Latitude,
+		&i.Longitude,
+	)
+	return i, err
+}
+
+type InsertDailyValuesParams struct {
+	SnapshotID int64              `db:"snapshot_id" json:"snapshot_id"`
+	Position   int16              `db:"position" json:"position"`
+	ValidAt    pgtype.Timestamptz `db:"valid_at" json:"valid_at"`
+	Temp       float32            `db:"temp" json:"temp"`
+	Tempmin    float32            `db:"tempmin" json:"tempmin"`
+	Tempmax    float32            `db:"tempmax" json:"tempmax"`
+	Humidity   float32            `db:"humidity" json:"humidity"`
+	Precip     float32            `db:"precip" json:"precip"`
+	Snow       float32            `db:"snow" json:"snow"`
+	Snowdepth  float32            `db:"snowdepth" json:"snowdepth"`
+	Windspeed  float32            `db:"windspeed" json:"windspeed"`
+}
+
+const insertDigestDelivery = `-- name: InsertDigestDelivery :exec
+INSERT INTO digest_deliveries (digest_id, scheduled_for, status, attempts, error)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (digest_id, scheduled_for) DO UPDATE
+SET status = EXCLUDED.status,
+    attempts = EXCLUDED.attempts,
+    error = EXCLUDED.error,
+    created_at = CURRENT_TIMESTAMP
+`
+
+type InsertDigestDeliveryParams struct {
+	DigestID     int32              `db:"digest_id" json:"digest_id"`
+	ScheduledFor pgtype.Timestamptz `db:"scheduled_for" json:"scheduled_for"`
+	Status       string             `db:"status" json:"status"`
+	Attempts     int32              `db:"attempts" json:"attempts"`
+	Error        string             `db:"error" json:"error"`
+}
+
+func (q *Queries) InsertDigestDelivery(ctx context.Context, arg InsertDigestDeliveryParams) error {
+	_, err := q.db.Exec(ctx, insertDigestDelivery,
+		arg.DigestID,
+		arg.ScheduledFor,
+		arg.Status,
+		arg.Attempts,
+		arg.Error,
+	)
+	return err
+}
+
+const insertForecastSnapshot = `-- name: InsertForecastSnapshot :one
+INSERT INTO forecast_snapshots (location_id, provider, fetched_at, history_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, location_id, provider, fetched_at, history_id
+`
+
+type InsertForecastSnapshotParams struct {
+	LocationID int32              `db:"location_id" json:"location_id"`
+	Provider   string             `db:"provider" json:"provider"`
+	FetchedAt  pgtype.Timestamptz `db:"fetched_at" json:"fetched_at"`
+	HistoryID  pgtype.Int4        `db:"history_id" json:"history_id"`
+}
+
+func (q *Queries) InsertForecastSnapshot(ctx context.Context, arg InsertForecastSnapshotParams) (ForecastSnapshot, error) {
+	row := q.db.QueryRow(ctx, insertForecastSnapshot,
+		arg.LocationID,
+		arg.Provider,
+		arg.FetchedAt,
+		arg.HistoryID,
+	)
+	var i ForecastSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.LocationID,
+		&i.Provider,
+		&i.FetchedAt,
+		&i.HistoryID,
+	)
+	return i, err
+}
+
+type InsertHourlyValuesParams struct {
+	SnapshotID  int64              `db:"snapshot_id" json:"snapshot_id"`
+	DayPosition int16              `db:"day_position" json:"day_position"`
+	Position    int16              `db:"position" json:"position"`
+	ValidAt     pgtype.Timestamptz `db:"valid_at" json:"valid_at"`
+	Temp        float32            `db:"temp" json:"temp"`
+	Tempmin     float32            `db:"tempmin" json:"tempmin"`
+	Tempmax     float32            `db:"tempmax" json:"tempmax"`
+	Humidity    float32            `db:"humidity" json:"humidity"`
+	Precip      float32            `db:"precip" json:"precip"`
+	Snow        float32            `db:"snow" json:"snow"`
+	Snowdepth   float32            `db:"snowdepth" json:"snowdepth"`
+	Windspeed   float32            `db:"windspeed" json:"windspeed"`
+}
+
+const insertWeatherQuery = `-- name: InsertWeatherQuery :one
+INSERT INTO weather_query_history (city, weather_data, latitude, longitude)
+VALUES ($1, $2::jsonb, $3, $4)
+RETURNING id, city, query_time, weather_data, latitude, longitude
+`
+
+type InsertWeatherQueryParams struct {
+	City      string        `db:"city" json:"city"`
+	Column2   []byte        `db:"column_2" json:"column_2"`
+	Latitude  pgtype.Float8 `db:"latitude" json:"latitude"`
+	Longitude pgtype.Float8 `db:"longitude" json:"longitude"`
+}
+
+func (q *Queries) InsertWeatherQuery(ctx context.Context, arg InsertWeatherQueryParams) (WeatherQueryHistory, error) {
+	row := q.db.QueryRow(ctx, insertWeatherQuery,
+		arg.City,
+		arg.Column2,
+		arg.Latitude,
+		arg.Longitude,
+	)
+	var i WeatherQueryHistory
+	err := row.Scan(
+		&i.ID,
+		&i.City,
+		&i.QueryTime,
+		&i.WeatherData,
+		&i.Latitude,
+		&i.Longitude,
+	)
+	return i, err
+}
+
+const listDailyValues = `-- name: ListDailyValues :many
+SELECT snapshot_id, position, valid_at, temp, tempmin, tempmax, humidity, precip, snow, snowdepth, windspeed
+FROM daily_values
+WHERE snapshot_id = $1
+ORDER BY position
+`
+
+func (q *Queries) ListDailyValues(ctx context.Context, snapshotID int64) ([]DailyValue, error) {
+	rows, err := q.db.Query(ctx, listDailyValues, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DailyValue
+	for rows.Next() {
+		var i DailyValue
+		if err := rows.Scan(
+			&i.SnapshotID,
+			&i.Position,
+			&i.ValidAt,
+			&i.Temp,
+			&i.Tempmin,
+			&i.Tempmax,
+			&i.Humidity,
+			&i.Precip,
+			&i.Snow,
+			&i.Snowdepth,
+			&i.Windspeed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeliveries = `-- name: ListDeliveries :many
+SELECT id, subscription_id, event_key, payload, status, attempts, next_attempt_at,
+       last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+`
+
+type ListDeliveriesParams struct {
+	SubscriptionID int32 `db:"subscription_id" json:"subscription_id"`
+	Limit          int32 `db:"limit" json:"limit"`
+}
+
+func (q *Queries) ListDeliveries(ctx context.Context, arg ListDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.Query(ctx, listDeliveries, arg.SubscriptionID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubscriptionID,
+			&i.EventKey,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.NextAttemptAt,
+			&i.LastStatusCode,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDigestDeliveries = `-- name: ListDigestDeliveries :many
+SELECT id, digest_id, scheduled_for, status, attempts, error, created_at FROM digest_deliveries
+WHERE digest_id = $1
+ORDER BY scheduled_for DESC
+LIMIT $2
+`
+
+type ListDigestDeliveriesParams struct {
+	DigestID int32 `db:"digest_id" json:"digest_id"`
+	Limit    int32 `db:"limit" json:"limit"`
+}
+
+func (q *Queries) ListDigestDeliveries(ctx context.Context, arg ListDigestDeliveriesParams) ([]DigestDelivery, error) {
+	rows, err := q.db.Query(ctx, listDigestDeliveries, arg.DigestID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DigestDelivery
+	for rows.Next() {
+		var i DigestDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.DigestID,
+			&i.ScheduledFor,
+			&i.Status,
+			&i.Attempts,
+			&i.Error,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listForecastVerification = `-- name: ListForecastVerification :many
+SELECT provider, field, lead_days, samples, mae, bias, rmse, computed_at
+FROM forecast_verification
+WHERE ($1::text IS NULL OR provider = $1::text)
+  AND ($2::text IS NULL OR field = $2::text)
+  AND ($3::int IS NULL OR lead_days = $3::int)
+ORDER BY provider, field, lead_days
+`
+
+type ListForecastVerificationParams struct {
+	Provider pgtype.Text `db:"provider" json:"provider"`
+	Field    pgtype.Text `db:"field" json:"field"`
+	LeadDays pgtype.Int4 `db:"lead_days" json:"lead_days"`
+}
+
+func (q *Queries) ListForecastVerification(ctx context.Context, arg ListForecastVerificationParams) ([]ForecastVerification, error) {
+	rows, err := q.db.Query(ctx, listForecastVerification, arg.Provider, arg.Field, arg.LeadDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ForecastVerification
+	for rows.Next() {
+		var i ForecastVerification
+		if err := rows.Scan(
+			&i.Provider,
+			&i.Field,
+			&i.LeadDays,
+			&i.Samples,
+			&i.Mae,
+			&i.Bias,
+			&i.Rmse,
+			&i.ComputedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHourlyValues = `-- name: ListHourlyValues :many
+SELECT snapshot_id, day_position, position, valid_at, temp, tempmin, tempmax, humidity, precip, snow, snowdepth, windspeed
+FROM hourly_values
+WHERE snapshot_id = $1
+ORDER BY day_position, position
+`
+
+func (q *Queries) ListHourlyValues(ctx context.Context, snapshotID int64) ([]HourlyValue, error) {
+	rows, err := q.db.Query(ctx, listHourlyValues, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HourlyValue
+	for rows.Next() {
+		var i HourlyValue
+		if err := rows.Scan(
+			&i.SnapshotID,
+			&i.DayPosition,
+			&i.Position,
+			&i.ValidAt,
+			&i.Temp,
+			&i.Tempmin,
+			&i.Tempmax,
+			&i.Humidity,
+			&i.Precip,
+			&i.Snow,
+			&i.Snowdepth,
+			&i.Windspeed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubscriptionsForLocation = `-- name: ListSubscriptionsForLocation :many
+SELECT id, city, latitude, longitude, condition, days, callback_url, secret, created_at FROM subscriptions s
+WHERE (s.city <> '' AND lower(s.city) = ANY($1::text[]))
+   OR (s.latitude IS NOT NULL AND s.longitude IS NOT NULL AND
+       2 * 6371 * asin(sqrt(
+           power(sin(radians(s.latitude - $2::float8) / 2), 2) +
+           cos(radians($2::float8)) * cos(radians(s.latitude)) *
+           power(sin(radians(s.longitude - $3::float8) / 2), 2)
+       )) <= $4::float8)
+ORDER BY s.id
+`
+
+type ListSubscriptionsForLocationParams struct {
+	Cities    []string `db:"cities" json:"cities"`
+	Latitude  float64  `db:"latitude" json:"latitude"`
+	Longitude float64  `db:"longitude" json:"longitude"`
+	RadiusKm  float64  `db:"radius_km" json:"radius_km"`
+}
+
+// Subscriptions for the named city, or with a coordinate within radius_km of
+// the given point.
+func (q *Queries) ListSubscriptionsForLocation(ctx context.Context, arg ListSubscriptionsForLocationParams) ([]Subscription, error) {
+	rows, err := q.db.Query(ctx, listSubscriptionsForLocation,
+		arg.Cities,
+		arg.Latitude,
+		arg.Longitude,
+		arg.RadiusKm,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.City,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Condition,
+			&i.Days,
+			&i.CallbackUrl,
+			&i.Secret,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnmigratedHistory = `-- name: ListUnmigratedHistory :many
+SELECT h.id, h.city, h.query_time, h.weather_data, h.latitude, h.longitude
+FROM weather_query_history h
+WHERE h.id > $1
+  AND NOT EXISTS (SELECT 1 FROM forecast_snapshots s WHERE s.history_id = h.id)
+ORDER BY h.id
+LIMIT $2
+`
+
+type ListUnmigratedHistoryParams struct {
+	AfterID   int32 `db:"after_id" json:"after_id"`
+	BatchSize int32 `db:"batch_size" json:"batch_size"`
+}
+
+func (q *Queries) ListUnmigratedHistory(ctx context.Context, arg ListUnmigratedHistoryParams) ([]WeatherQueryHistory, error) {
+	rows, err := q.db.Query(ctx, listUnmigratedHistory, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WeatherQueryHistory
+	for rows.Next() {
+		var i WeatherQueryHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.City,
+			&i.QueryTime,
+			&i.WeatherData,
+			&i.Latitude,
+			&i.Longitude,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWatchedLocations = `-- name: ListWatchedLocations :many
+SELECT id, name, city, latitude, longitude, interval_seconds, next_fetch_at, last_fetched_at, last_error, created_at, updated_at FROM watched_locations
+ORDER BY id
+`
+
+func (q *Queries) ListWatchedLocations(ctx context.Context) ([]WatchedLocation, error) {
+	rows, err := q.db.Query(ctx, listWatchedLocations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WatchedLocation
+	for rows.Next() {
+		var i WatchedLocation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.City,
+			&i.Latitude,
+			&i.Longitude,
+			&i.IntervalSeconds,
+			&i.NextFetchAt,
+			&i.LastFetchedAt,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWeatherHistory = `-- name: ListWeatherHistory :many
+SELECT id, city, query_time, weather_data, latitude, longitude
 FROM weather_query_history
-WHERE city = $1 AND query_time >= $2
-ORDER BY query_time DESC
+WHERE ($1::text IS NULL OR city = $1::text)
+  AND ($2::float8 IS NULL OR (
+        latitude IS NOT NULL AND longitude IS NOT NULL AND
+        2 * 6371 * asin(sqrt(
+            power(sin(radians(latitude - $3::float8) / 2), 2) +
+            cos(radians($3::float8)) * cos(radians(latitude)) *
+            power(sin(radians(longitude - $4::float8) / 2), 2)
+        )) <= $2::float8))
+  AND ($5::timestamptz IS NULL OR query_time >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR query_time < $6::timestamptz)
+  AND ($7::timestamptz IS NULL
+       OR (query_time, id) < ($7::timestamptz, $8::int))
+ORDER BY query_time DESC, id DESC
+LIMIT $9
 `
 
-type GetRecentWeatherParams struct {
-	City      string             `db:"city" json:"city"`
-	QueryTime pgtype.Timestamptz `db:"query_time" json:"query_time"`
+type ListWeatherHistoryParams struct {
+	City       pgtype.Text        `db:"city" json:"city"`
+	RadiusKm   pgtype.Float8      `db:"radius_km" json:"radius_km"`
+	Latitude   pgtype.Float8      `db:"latitude" json:"latitude"`
+	Longitude  pgtype.Float8      `db:"longitude" json:"longitude"`
+	FromTime   pgtype.Timestamptz `db:"from_time" json:"from_time"`
+	ToTime     pgtype.Timestamptz `db:"to_time" json:"to_time"`
+	CursorTime pgtype.Timestamptz `db:"cursor_time" json:"cursor_time"`
+	CursorID   pgtype.Int4        `db:"cursor_id" json:"cursor_id"`
+	PageSize   int32              `db:"page_size" json:"page_size"`
 }
 
-func (q *Queries) GetRecentWeather(ctx context.Context, arg GetRecentWeatherParams) ([]WeatherQueryHistory, error) {
-	rows, err := q.db.Query(ctx, getRecentWeather, arg.City, arg.QueryTime)
+func (q *Queries) ListWeatherHistory(ctx context.Context, arg ListWeatherHistoryParams) ([]WeatherQueryHistory, error) {
+	rows, err := q.db.Query(ctx, listWeatherHistory,
+		arg.City,
+		arg.RadiusKm,
+		arg.Latitude,
+		arg.Longitude,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +1515,8 @@ func (q *Queries) GetRecentWeather(ctx context.Context, arg GetRecentWeatherPara
 			&i.City,
 			&i.QueryTime,
 			&i.WeatherData,
+			&i.Latitude,
+			&i.Longitude,
 		); err != nil {
 			return nil, err
 		}
@@ -48,45 +1528,314 @@ func (q *Queries) GetRecentWeather(ctx context.Context, arg GetRecentWeatherPara
 	return items, nil
 }
 
-const getWeatherByLocation = `-- name: GetWeatherByLocation :one
-SELECT id, city, query_time, weather_data
+const listWeatherHistorySummaries = `-- name: ListWeatherHistorySummaries :many
+SELECT id, city, query_time, latitude, longitude,
+       COALESCE(jsonb_array_length(weather_data->'days'), 0)::int AS days,
+       COALESCE((weather_data->'days'->0->>'temp')::real, 0)::real AS temp,
+       COALESCE((weather_data->'days'->0->>'tempmin')::real, 0)::real AS tempmin,
+       COALESCE((weather_data->'days'->0->>'tempmax')::real, 0)::real AS tempmax,
+       COALESCE((weather_data->'days'->0->>'humidity')::real, 0)::real AS humidity,
+       COALESCE((weather_data->'days'->0->>'precip')::real, 0)::real AS precip,
+       COALESCE((weather_data->'days'->0->>'windspeed')::real, 0)::real AS windspeed
 FROM weather_query_history
-WHERE city = $1
-ORDER BY query_time DESC
-LIMIT 1
+WHERE ($1::text IS NULL OR city = $1::text)
+  AND ($2::float8 IS NULL OR (
+        latitude IS NOT NULL AND longitude IS NOT NULL AND
+        2 * 6371 * asin(sqrt(
+            power(sin(radians(latitude - $3::float8) / 2), 2) +
+            cos(radians($3::float8)) * cos(radians(latitude)) *
+            power(sin(radians(longitude - $4::float8) / 2), 2)
+        )) <= $2::float8))
+  AND ($5::timestamptz IS NULL OR query_time >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR query_time < $6::timestamptz)
+  AND ($7::timestamptz IS NULL
+       OR (query_time, id) < ($7::timestamptz, $8::int))
+ORDER BY query_time DESC, id DESC
+LIMIT $9
 `
 
-func (q *Queries) GetWeatherByLocation(ctx context.Context, city string) (WeatherQueryHistory, error) {
-	row := q.db.QueryRow(ctx, getWeatherByLocation, city)
-	var i WeatherQueryHistory
+type ListWeatherHistorySummariesParams struct {
+	City       pgtype.Text        `db:"city" json:"city"`
+	RadiusKm   pgtype.Float8      `db:"radius_km" json:"radius_km"`
+	Latitude   pgtype.Float8      `db:"latitude" json:"latitude"`
+	Longitude  pgtype.Float8      `db:"longitude" json:"longitude"`
+	FromTime   pgtype.Timestamptz `db:"from_time" json:"from_time"`
+	ToTime     pgtype.Timestamptz `db:"to_time" json:"to_time"`
+	CursorTime pgtype.Timestamptz `db:"cursor_time" json:"cursor_time"`
+	CursorID   pgtype.Int4        `db:"cursor_id" json:"cursor_id"`
+	PageSize   int32              `db:"page_size" json:"page_size"`
+}
+
+type ListWeatherHistorySummariesRow struct {
+	ID        int32              `db:"id" json:"id"`
+	City      string             `db:"city" json:"city"`
+	QueryTime pgtype.Timestamptz `db:"query_time" json:"query_time"`
+	Latitude  pgtype.Float8      `db:"latitude" json:"latitude"`
+	Longitude pgtype.Float8      `db:"longitude" json:"longitude"`
+	Days      int32              `db:"days" json:"days"`
+	Temp      float32            `db:"temp" json:"temp"`
+	Tempmin   float32            `db:"tempmin" json:"tempmin"`
+	Tempmax   float32            `db:"tempmax" json:"tempmax"`
+	Humidity  float32            `db:"humidity" json:"humidity"`
+	Precip    float32            `db:"precip" json:"precip"`
+	Windspeed float32            `db:"windspeed" json:"windspeed"`
+}
+
+func (q *Queries) ListWeatherHistorySummaries(ctx context.Context, arg ListWeatherHistorySummariesParams) ([]ListWeatherHistorySummariesRow, error) {
+	rows, err := q.db.Query(ctx, listWeatherHistorySummaries,
+		arg.City,
+		arg.RadiusKm,
+		arg.Latitude,
+		arg.Longitude,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWeatherHistorySummariesRow
+	for rows.Next() {
+		var i ListWeatherHistorySummariesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.City,
+			&i.QueryTime,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Days,
+			&i.Temp,
+			&i.Tempmin,
+			&i.Tempmax,
+			&i.Humidity,
+			&i.Precip,
+			&i.Windspeed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markDeliveryDelivered = `-- name: MarkDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = '',
+    delivered_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type MarkDeliveryDeliveredParams struct {
+	ID             int64       `db:"id" json:"id"`
+	LastStatusCode pgtype.Int4 `db:"last_status_code" json:"last_status_code"`
+}
+
+func (q *Queries) MarkDeliveryDelivered(ctx context.Context, arg MarkDeliveryDeliveredParams) error {
+	_, err := q.db.Exec(ctx, markDeliveryDelivered, arg.ID, arg.LastStatusCode)
+	return err
+}
+
+const markDeliveryFailed = `-- name: MarkDeliveryFailed :exec
+UPDATE webhook_deliveries
+SET status = CASE WHEN $1::bool THEN 'dead' ELSE 'pending' END,
+    attempts = attempts + 1,
+    last_status_code = $2,
+    last_error = $3,
+    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $4::float8)
+WHERE id = $5
+`
+
+type MarkDeliveryFailedParams struct {
+	Dead         bool        `db:"dead" json:"dead"`
+	StatusCode   pgtype.Int4 `db:"status_code" json:"status_code"`
+	LastError    string      `db:"last_error" json:"last_error"`
+	RetrySeconds float64     `db:"retry_seconds" json:"retry_seconds"`
+	ID           int64       `db:"id" json:"id"`
+}
+
+// Records a failed attempt. The delivery is retried after retry_seconds, or
+// moves to the dead state if dead is set.
+func (q *Queries) MarkDeliveryFailed(ctx context.Context, arg MarkDeliveryFailedParams) error {
+	_, err := q.db.Exec(ctx, markDeliveryFailed,
+		arg.Dead,
+		arg.StatusCode,
+		arg.LastError,
+		arg.RetrySeconds,
+		arg.ID,
+	)
+	return err
+}
+
+const recordWatchedFetch = `-- name: RecordWatchedFetch :exec
+UPDATE watched_locations
+SET last_fetched_at = CASE WHEN $1::bool THEN CURRENT_TIMESTAMP ELSE last_fetched_at END,
+    last_error = $2,
+    next_fetch_at = CURRENT_TIMESTAMP + make_interval(secs => $3::float8)
+WHERE id = $4
+`
+
+type RecordWatchedFetchParams struct {
+	Succeeded    bool    `db:"succeeded" json:"succeeded"`
+	LastError    string  `db:"last_error" json:"last_error"`
+	DelaySeconds float64 `db:"delay_seconds" json:"delay_seconds"`
+	ID           int32   `db:"id" json:"id"`
+}
+
+func (q *Queries) RecordWatchedFetch(ctx context.Context, arg RecordWatchedFetchParams) error {
+	_, err := q.db.Exec(ctx, recordWatchedFetch,
+		arg.Succeeded,
+		arg.LastError,
+		arg.DelaySeconds,
+		arg.ID,
+	)
+	return err
+}
+
+const retryDigest = `-- name: RetryDigest :exec
+UPDATE digest_subscriptions
+SET attempts = attempts + 1,
+    due_at = CURRENT_TIMESTAMP + make_interval(secs => $1::float8),
+    last_error = $2
+WHERE id = $3
+`
+
+type RetryDigestParams struct {
+	RetrySeconds float64 `db:"retry_seconds" json:"retry_seconds"`
+	LastError    string  `db:"last_error" json:"last_error"`
+	ID           int32   `db:"id" json:"id"`
+}
+
+func (q *Queries) RetryDigest(ctx context.Context, arg RetryDigestParams) error {
+	_, err := q.db.Exec(ctx, retryDigest, arg.RetrySeconds, arg.LastError, arg.ID)
+	return err
+}
+
+const unsubscribeDigest = `-- name: UnsubscribeDigest :one
+UPDATE digest_subscriptions
+SET unsubscribed_at = COALESCE(unsubscribed_at, CURRENT_TIMESTAMP)
+WHERE unsubscribe_token = $1
+RETURNING id, email, locations, frequency, weekday, send_time, timezone, unsubscribe_token, next_send_at, due_at, attempts, last_sent_at, last_error, unsubscribed_at, created_at, confirm_token, confirmed_at
+`
+
+// Stops the digest with the given token. Unsubscribing twice keeps the first
+// time.
+func (q *Queries) UnsubscribeDigest(ctx context.Context, unsubscribeToken string) (DigestSubscription, error) {
+	row := q.db.QueryRow(ctx, unsubscribeDigest, unsubscribeToken)
+	var i DigestSubscription
 	err := row.Scan(
 		&i.ID,
-		&i.City,
-		&i.QueryTime,
-		&i.WeatherData,
+		&i.Email,
+		&i.Locations,
+		&i.Frequency,
+		&i.Weekday,
+		&i.SendTime,
+		&i.Timezone,
+		&i.UnsubscribeToken,
+		&i.NextSendAt,
+		&i.DueAt,
+		&i.Attempts,
+		&i.LastSentAt,
+		&i.LastError,
+		&i.UnsubscribedAt,
+		&i.CreatedAt,
+		&i.ConfirmToken,
+		&i.ConfirmedAt,
 	)
 	return i, err
 }
 
-const insertWeatherQuery = `-- name: InsertWeatherQuery :one
-INSERT INTO weather_query_history (city, weather_data)
-VALUES ($1, $2::jsonb)
-RETURNING id, city, query_time, weather_data
+const updateWatchedLocation = `-- name: UpdateWatchedLocation :one
+UPDATE watched_locations
+SET name = $2,
+    city = $3,
+    latitude = $4,
+    longitude = $5,
+    interval_seconds = $6,
+    next_fetch_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING id, name, city, latitude, longitude, interval_seconds, next_fetch_at, last_fetched_at, last_error, created_at, updated_at
 `
 
-type InsertWeatherQueryParams struct {
-	City    string `db:"city" json:"city"`
-	Column2 []byte `db:"column_2" json:"column_2"`
+type UpdateWatchedLocationParams struct {
+	ID              int32         `db:"id" json:"id"`
+	Name            string        `db:"name" json:"name"`
+	City            string        `db:"city" json:"city"`
+	Latitude        pgtype.Float8 `db:"latitude" json:"latitude"`
+	Longitude       pgtype.Float8 `db:"longitude" json:"longitude"`
+	IntervalSeconds int32         `db:"interval_seconds" json:"interval_seconds"`
 }
 
-func (q *Queries) InsertWeatherQuery(ctx context.Context, arg InsertWeatherQueryParams) (WeatherQueryHistory, error) {
-	row := q.db.QueryRow(ctx, insertWeatherQuery, arg.City, arg.Column2)
-	var i WeatherQueryHistory
+func (q *Queries) UpdateWatchedLocation(ctx context.Context, arg UpdateWatchedLocationParams) (WatchedLocation, error) {
+	row := q.db.QueryRow(ctx, updateWatchedLocation,
+		arg.ID,
+		arg.Name,
+		arg.City,
+		arg.Latitude,
+		arg.Longitude,
+		arg.IntervalSeconds,
+	)
+	var i WatchedLocation
 	err := row.Scan(
 		&i.ID,
+		&i.Name,
 		&i.City,
-		&i.QueryTime,
-		&i.WeatherData,
+		&i.Latitude,
+		&i.Longitude,
+		&i.IntervalSeconds,
+		&i.NextFetchAt,
+		&i.LastFetchedAt,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertLocation = `-- name: UpsertLocation :one
+INSERT INTO locations (name, country, latitude, longitude, timezone)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (latitude, longitude) DO UPDATE
+SET name = COALESCE(NULLIF(EXCLUDED.name, ''), locations.name),
+    country = COALESCE(NULLIF(EXCLUDED.country, ''), locations.country),
+    timezone = COALESCE(NULLIF(EXCLUDED.timezone, ''), locations.timezone)
+RETURNING id, name, country, latitude, longitude, timezone, created_at
+`
+
+type UpsertLocationParams struct {
+	Name      string  `db:"name" json:"name"`
+	Country   string  `db:"country" json:"country"`
+	Latitude  float64 `db:"latitude" json:"latitude"`
+	Longitude float64 `db:"longitude" json:"longitude"`
+	Timezone  string  `db:"timezone" json:"timezone"`
+}
+
+func (q *Queries) UpsertLocation(ctx context.Context, arg UpsertLocationParams) (Location, error) {
+	row := q.db.QueryRow(ctx, upsertLocation,
+		arg.Name,
+		arg.Country,
+		arg.Latitude,
+		arg.Longitude,
+		arg.Timezone,
+	)
+	var i Location
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Country,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Timezone,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,20 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+
+package sqlitedb
+
+import (
+	"database/sql"
+)
+
+type WeatherQueryHistory struct {
+	ID                int64           `db:"id" json:"id"`
+	City              string          `db:"city" json:"city"`
+	QueryTime         int64           `db:"query_time" json:"query_time"`
+	WeatherData       string          `db:"weather_data" json:"weather_data"`
+	Latitude          sql.NullFloat64 `db:"latitude" json:"latitude"`
+	Longitude         sql.NullFloat64 `db:"longitude" json:"longitude"`
+	LocationLatitude  sql.NullFloat64 `db:"location_latitude" json:"location_latitude"`
+	LocationLongitude sql.NullFloat64 `db:"location_longitude" json:"location_longitude"`
+}
@@ -0,0 +1,19 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+
+package sqlitedb
+
+import (
+	"context"
+)
+
+type Querier interface {
+	GetLatestWeatherAt(ctx context.Context, arg GetLatestWeatherAtParams) (GetLatestWeatherAtRow, error)
+	GetRecentWeather(ctx context.Context, arg GetRecentWeatherParams) (GetRecentWeatherRow, error)
+	GetStorageStats(ctx context.Context) (GetStorageStatsRow, error)
+	InsertWeatherQuery(ctx context.Context, arg InsertWeatherQueryParams) (InsertWeatherQueryRow, error)
+	ListWeatherHistory(ctx context.Context, arg ListWeatherHistoryParams) ([]ListWeatherHistoryRow, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,45 @@
+-- name: InsertWeatherQuery :one
+INSERT INTO weather_query_history (city, query_time, weather_data, latitude, longitude, location_latitude, location_longitude)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id, query_time;
+
+-- name: GetRecentWeather :one
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE city = ? AND query_time >= ?
+ORDER BY query_time DESC
+LIMIT 1;
+
+-- name: GetLatestWeatherAt :one
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE location_latitude = ? AND location_longitude = ?
+ORDER BY query_time DESC
+LIMIT 1;
+
+-- name: ListWeatherHistory :many
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE (CAST(sqlc.narg('city') AS TEXT) IS NULL OR city = sqlc.narg('city'))
+  AND (CAST(sqlc.narg('radius_km') AS REAL) IS NULL OR (
+        latitude IS NOT NULL AND longitude IS NOT NULL AND
+        2 * 6371 * asin(sqrt(
+            power(sin(radians(latitude - sqlc.narg('latitude')) / 2), 2) +
+            cos(radians(sqlc.narg('latitude'))) * cos(radians(latitude)) *
+            power(sin(radians(longitude - sqlc.narg('longitude')) / 2), 2)
+        )) <= sqlc.narg('radius_km')))
+  AND (CAST(sqlc.narg('from_time') AS INTEGER) IS NULL OR query_time >= sqlc.narg('from_time'))
+  AND (CAST(sqlc.narg('to_time') AS INTEGER) IS NULL OR query_time < sqlc.narg('to_time'))
+  AND (CAST(sqlc.narg('cursor_time') AS INTEGER) IS NULL
+       OR query_time < sqlc.narg('cursor_time')
+       OR (query_time = sqlc.narg('cursor_time') AND id < sqlc.narg('cursor_id')))
+ORDER BY query_time DESC, id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: GetStorageStats :one
+SELECT COUNT(*) AS queries,
+       COUNT(DISTINCT city) AS cities,
+       COUNT(DISTINCT location_latitude || ',' || location_longitude) AS locations,
+       CAST(COALESCE(MIN(query_time), 0) AS INTEGER) AS oldest_query,
+       CAST(COALESCE(MAX(query_time), 0) AS INTEGER) AS newest_query
+FROM weather_query_history;
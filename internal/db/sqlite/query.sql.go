@@ -0,0 +1,230 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: query.sql
+
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+)
+
+const getLatestWeatherAt = `-- name: GetLatestWeatherAt :one
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE location_latitude = ? AND location_longitude = ?
+ORDER BY query_time DESC
+LIMIT 1
+`
+
+type GetLatestWeatherAtParams struct {
+	LocationLatitude  sql.NullFloat64 `db:"location_latitude" json:"location_latitude"`
+	LocationLongitude sql.NullFloat64 `db:"location_longitude" json:"location_longitude"`
+}
+
+type GetLatestWeatherAtRow struct {
+	ID          int64           `db:"id" json:"id"`
+	City        string          `db:"city" json:"city"`
+	QueryTime   int64           `db:"query_time" json:"query_time"`
+	WeatherData string          `db:"weather_data" json:"weather_data"`
+	Latitude    sql.NullFloat64 `db:"latitude" json:"latitude"`
+	Longitude   sql.NullFloat64 `db:"longitude" json:"longitude"`
+}
+
+func (q *Queries) GetLatestWeatherAt(ctx context.Context, arg GetLatestWeatherAtParams) (GetLatestWeatherAtRow, error) {
+	row := q.db.QueryRowContext(ctx, getLatestWeatherAt, arg.LocationLatitude, arg.LocationLongitude)
+	var i GetLatestWeatherAtRow
+	err := row.Scan(
+		&i.ID,
+		&i.City,
+		&i.QueryTime,
+		&i.WeatherData,
+		&i.Latitude,
+		&i.Longitude,
+	)
+	return i, err
+}
+
+const getRecentWeather = `-- name: GetRecentWeather :one
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE city = ? AND query_time >= ?
+ORDER BY query_time DESC
+LIMIT 1
+`
+
+type GetRecentWeatherParams struct {
+	City      string `db:"city" json:"city"`
+	QueryTime int64  `db:"query_time" json:"query_time"`
+}
+
+type GetRecentWeatherRow struct {
+	ID          int64           `db:"id" json:"id"`
+	City        string          `db:"city" json:"city"`
+	QueryTime   int64           `db:"query_time" json:"query_time"`
+	WeatherData string          `db:"weather_data" json:"weather_data"`
+	Latitude    sql.NullFloat64 `db:"latitude" json:"latitude"`
+	Longitude   sql.NullFloat64 `db:"longitude" json:"longitude"`
+}
+
+func (q *Queries) GetRecentWeather(ctx context.Context, arg GetRecentWeatherParams) (GetRecentWeatherRow, error) {
+	row := q.db.QueryRowContext(ctx, getRecentWeather, arg.City, arg.QueryTime)
+	var i GetRecentWeatherRow
+	err := row.Scan(
+		&i.ID,
+		&i.City,
+		&i.QueryTime,
+		&i.WeatherData,
+		&i.Latitude,
+		&i.Longitude,
+	)
+	return i, err
+}
+
+const getStorageStats = `-- name: GetStorageStats :one
+SELECT COUNT(*) AS queries,
+       COUNT(DISTINCT city) AS cities,
+       COUNT(DISTINCT location_latitude || ',' || location_longitude) AS locations,
+       CAST(COALESCE(MIN(query_time), 0) AS INTEGER) AS oldest_query,
+       CAST(COALESCE(MAX(query_time), 0) AS INTEGER) AS newest_query
+FROM weather_query_history
+`
+
+type GetStorageStatsRow struct {
+	Queries     int64 `db:"queries" json:"queries"`
+	Cities      int64 `db:"cities" json:"cities"`
+	Locations   int64 `db:"locations" json:"locations"`
+	OldestQuery int64 `db:"oldest_query" json:"oldest_query"`
+	NewestQuery int64 `db:"newest_query" json:"newest_query"`
+}
+
+func (q *Queries) GetStorageStats(ctx context.Context) (GetStorageStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getStorageStats)
+	var i GetStorageStatsRow
+	err := row.Scan(
+		&i.Queries,
+		&i.Cities,
+		&i.Locations,
+		&i.OldestQuery,
+		&i.NewestQuery,
+	)
+	return i, err
+}
+
+const insertWeatherQuery = `-- name: InsertWeatherQuery :one
+INSERT INTO weather_query_history (city, query_time, weather_data, latitude, longitude, location_latitude, location_longitude)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id, query_time
+`
+
+type InsertWeatherQueryParams struct {
+	City              string          `db:"city" json:"city"`
+	QueryTime         int64           `db:"query_time" json:"query_time"`
+	WeatherData       string          `db:"weather_data" json:"weather_data"`
+	Latitude          sql.NullFloat64 `db:"latitude" json:"latitude"`
+	Longitude         sql.NullFloat64 `db:"longitude" json:"longitude"`
+	LocationLatitude  sql.NullFloat64 `db:"location_latitude" json:"location_latitude"`
+	LocationLongitude sql.NullFloat64 `db:"location_longitude" json:"location_longitude"`
+}
+
+type InsertWeatherQueryRow struct {
+	ID        int64 `db:"id" json:"id"`
+	QueryTime int64 `db:"query_time" json:"query_time"`
+}
+
+func (q *Queries) InsertWeatherQuery(ctx context.Context, arg InsertWeatherQueryParams) (InsertWeatherQueryRow, error) {
+	row := q.db.QueryRowContext(ctx, insertWeatherQuery,
+		arg.City,
+		arg.QueryTime,
+		arg.WeatherData,
+		arg.Latitude,
+		arg.Longitude,
+		arg.LocationLatitude,
+		arg.LocationLongitude,
+	)
+	var i InsertWeatherQueryRow
+	err := row.Scan(&i.ID, &i.QueryTime)
+	return i, err
+}
+
+const listWeatherHistory = `-- name: ListWeatherHistory :many
+SELECT id, city, query_time, weather_data, latitude, longitude
+FROM weather_query_history
+WHERE (CAST(?1 AS TEXT) IS NULL OR city = ?1)
+  AND (CAST(?2 AS REAL) IS NULL OR (
+        latitude IS NOT NULL AND longitude IS NOT NULL AND
+        2 * 6371 * asin(sqrt(
+            power(sin(radians(latitude - ?3) / 2), 2) +
+            cos(radians(?3)) * cos(radians(latitude)) *
+            power(sin(radians(longitude - ?4) / 2), 2)
+        )) <= ?2))
+  AND (CAST(?5 AS INTEGER) IS NULL OR query_time >= ?5)
+  AND (CAST(?6 AS INTEGER) IS NULL OR query_time < ?6)
+  AND (CAST(?7 AS INTEGER) IS NULL
+       OR query_time < ?7
+       OR (query_time = ?7 AND id < ?8))
+ORDER BY query_time DESC, id DESC
+LIMIT ?9
+`
+
+type ListWeatherHistoryParams struct {
+	City       sql.NullString  `db:"city" json:"city"`
+	RadiusKm   sql.NullFloat64 `db:"radius_km" json:"radius_km"`
+	Latitude   sql.NullFloat64 `db:"latitude" json:"latitude"`
+	Longitude  sql.NullFloat64 `db:"longitude" json:"longitude"`
+	FromTime   sql.NullInt64   `db:"from_time" json:"from_time"`
+	ToTime     sql.NullInt64   `db:"to_time" json:"to_time"`
+	CursorTime sql.NullInt64   `db:"cursor_time" json:"cursor_time"`
+	CursorID   sql.NullInt64   `db:"cursor_id" json:"cursor_id"`
+	PageSize   int64           `db:"page_size" json:"page_size"`
+}
+
+type ListWeatherHistoryRow struct {
+	ID          int64           `db:"id" json:"id"`
+	City        string          `db:"city" json:"city"`
+	QueryTime   int64           `db:"query_time" json:"query_time"`
+	WeatherData string          `db:"weather_data" json:"weather_data"`
+	Latitude    sql.NullFloat64 `db:"latitude" json:"latitude"`
+	Longitude   sql.NullFloat64 `db:"longitude" json:"longitude"`
+}
+
+func (q *Queries) ListWeatherHistory(ctx context.Context, arg ListWeatherHistoryParams) ([]ListWeatherHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, listWeatherHistory,
+		arg.City,
+		arg.RadiusKm,
+		arg.Latitude,
+		arg.Longitude,
+		arg.FromTime,
+		arg.ToTime,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWeatherHistoryRow
+	for rows.Next() {
+		var i ListWeatherHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.City,
+			&i.QueryTime,
+			&i.WeatherData,
+			&i.Latitude,
+			&i.Longitude,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
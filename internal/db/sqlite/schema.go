@@ -0,0 +1,11 @@
+// Package sqlitedb holds the schema and sqlc-generated queries for the
+// embedded SQLite storage backend.
+package sqlitedb
+
+import _ "embed"
+
+// Schema creates the SQLite tables if they do not exist. It is safe to apply
+// on every start.
+//
+//go:embed schema.sql
+var Schema string
@@ -0,0 +1,20 @@
+-- Schema for the embedded SQLite backend. It mirrors weather_query_history
+-- from the Postgres migrations; query_time is stored as Unix microseconds and
+-- the location columns hold the coordinates rounded to the location grid.
+CREATE TABLE IF NOT EXISTS weather_query_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    city TEXT NOT NULL,
+    query_time INTEGER NOT NULL,
+    weather_data TEXT NOT NULL,
+    latitude REAL,
+    longitude REAL,
+    location_latitude REAL,
+    location_longitude REAL
+);
+
+CREATE INDEX IF NOT EXISTS weather_query_history_query_time_idx
+    ON weather_query_history (query_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS weather_query_history_city_idx
+    ON weather_query_history (city, query_time DESC);
+CREATE INDEX IF NOT EXISTS weather_query_history_location_idx
+    ON weather_query_history (location_latitude, location_longitude, query_time DESC);
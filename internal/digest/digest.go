@@ -0,0 +1,257 @@
+// Package digest sends scheduled email digests of the forecast. A Worker
+// claims the digest subscriptions that are due, has the Sender fetch the
+// weather for their locations and email it, then schedules the next send
+// from the subscription's frequency, local send time and timezone. Digests
+// are only sent once their address is confirmed through the link the Sender
+// emails when they are created.
+//
+// A send that fails is retried after RetryInterval until MaxAttempts; the
+// outcome of every scheduled send is recorded in digest_deliveries. A digest
+// that was due while the service was down is sent once on start-up and then
+// resumes its normal schedule.
+package digest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/platform"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var sendsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "weather_digest_sends_total",
+	Help: "Digest email sends by result.",
+}, []string{"result"})
+
+// Forecast days shown per frequency, starting today.
+const (
+	dailyDays  = 3
+	weeklyDays = 7
+)
+
+// WeatherSource provides the forecasts for a digest.
+type WeatherSource interface {
+	GetWeather(ctx context.Context, rq models.WeatherRequest) (models.WeatherResponse, error)
+}
+
+// Sender renders digests and emails them.
+type Sender struct {
+	weather WeatherSource
+	mailer  platform.Mailer
+	baseURL string
+	log     *zap.Logger
+}
+
+// NewSender returns a sender whose confirmation and unsubscribe links point at
+// baseURL, the public address of this service.
+func NewSender(weather WeatherSource, mailer platform.Mailer, baseURL string, log *zap.Logger) *Sender {
+	return &Sender{weather: weather, mailer: mailer, baseURL: strings.TrimRight(baseURL, "/"), log: log}
+}
+
+// UnsubscribeURL is the link that stops the digest with the given token.
+func (s *Sender) UnsubscribeURL(token string) string {
+	return s.baseURL + "/v1/digests/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// ConfirmURL is the link that confirms the digest with the given token.
+func (s *Sender) ConfirmURL(token string) string {
+	return s.baseURL + "/v1/digests/confirm?token=" + url.QueryEscape(token)
+}
+
+// SendConfirmation emails the link that confirms the digest to its address.
+func (s *Sender) SendConfirmation(ctx context.Context, digest models.DigestSubscription, token string) error {
+	v := view{
+		Subject:    "Confirm your " + digest.Frequency + " weather digest",
+		Email:      digest.Email,
+		Frequency:  digest.Frequency,
+		ConfirmURL: s.ConfirmURL(token),
+	}
+	text, html, err := render("confirm", v)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, platform.EmailMessage{
+		To:      digest.Email,
+		Subject: v.Subject,
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+// Send fetches the forecasts for the digest and emails them. It fails if no
+// forecast could be fetched.
+func (s *Sender) Send(ctx context.Context, digest models.DigestSubscription) error {
+	loc, err := time.LoadLocation(digest.Timezone)
+	if err != nil {
+		return err
+	}
+	today := time.Now().In(loc).Format("2006-01-02")
+	days := dailyDays
+	if digest.Frequency == models.DigestWeekly {
+		days = weeklyDays
+	}
+
+	forecasts := make([]Forecast, 0, len(digest.Locations))
+	var fetched int
+	var lastErr error
+	for _, location := range digest.Locations {
+		weather, err := s.weather.GetWeather(ctx, location.Request(today))
+		if err != nil {
+			s.log.Warn("Failed to fetch digest forecast", zap.Error(err), zap.Int32("digest_id", digest.ID), zap.String("location", location.Label()))
+			forecasts = append(forecasts, Forecast{Label: location.Label(), Error: "the weather service could not be reached"})
+			lastErr = err
+			continue
+		}
+		forecasts = append(forecasts, NewForecast(location.Label(), weather, days))
+		fetched++
+	}
+	if fetched == 0 {
+		return errors.Join(errors.New("no forecast available"), lastErr)
+	}
+
+	unsubscribe := s.UnsubscribeURL(digest.UnsubscribeToken)
+	v := view{
+		Subject:        "Your " + digest.Frequency + " weather digest",
+		Email:          digest.Email,
+		Frequency:      digest.Frequency,
+		Forecasts:      forecasts,
+		UnsubscribeURL: unsubscribe,
+	}
+	text, html, err := render("digest", v)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, platform.EmailMessage{
+		To:      digest.Email,
+		Subject: v.Subject,
+		Text:    text,
+		HTML:    html,
+		Headers: map[string]string{
+			// One-click unsubscribe, RFC 8058.
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+}
+
+// Config controls polling, timeouts and retries of digest sends.
+type Config struct {
+	Interval      time.Duration
+	BatchSize     int
+	Concurrency   int
+	Timeout       time.Duration
+	MaxAttempts   int
+	RetryInterval time.Duration
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Interval, validation.Required),
+		validation.Field(&c.BatchSize, validation.Required, validation.Min(1)),
+		validation.Field(&c.Concurrency, validation.Required, validation.Min(1)),
+		validation.Field(&c.Timeout, validation.Required),
+		validation.Field(&c.MaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&c.RetryInterval, validation.Required),
+	)
+}
+
+// Worker sends the digests that are due.
+type Worker struct {
+	repo   *repository.WeatherRepository
+	sender *Sender
+	cfg    Config
+	log    *zap.Logger
+}
+
+func NewWorker(repo *repository.WeatherRepository, sender *Sender, cfg Config, log *zap.Logger) *Worker {
+	return &Worker{repo: repo, sender: sender, cfg: cfg, log: log}
+}
+
+// Run sends due digests immediately and then on every interval until ctx is
+// cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.log.Error("Digest run failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends every digest that is currently due, a batch at a time.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	lease := 2*w.cfg.Timeout + w.cfg.Interval
+	for {
+		due, err := w.repo.ClaimDueDigests(ctx, w.cfg.BatchSize, lease)
+		if err != nil {
+			return err
+		}
+
+		sem := make(chan struct{}, w.cfg.Concurrency)
+		var wg sync.WaitGroup
+		for _, digest := range due {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				w.send(ctx, digest)
+			}()
+		}
+		wg.Wait()
+
+		if len(due) < w.cfg.BatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// send sends the digest's current slot and records the outcome.
+func (w *Worker) send(ctx context.Context, digest models.DigestSubscription) {
+	sendCtx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	err := w.sender.Send(sendCtx, digest)
+	cancel()
+
+	record := context.WithoutCancel(ctx)
+	next, nextErr := digest.Next(time.Now())
+	if nextErr != nil {
+		// Schedules are validated when digests are created, so this only
+		// happens if the timezone database loses a zone.
+		w.log.Error("Failed to schedule digest", zap.Error(nextErr), zap.Int32("digest_id", digest.ID))
+		next = time.Now().Add(24 * time.Hour)
+	}
+
+	switch {
+	case err == nil:
+		sendsTotal.WithLabelValues("sent").Inc()
+		err = w.repo.RecordDigestSent(record, digest, next)
+	case digest.Attempts+1 >= w.cfg.MaxAttempts:
+		sendsTotal.WithLabelValues("failed").Inc()
+		w.log.Warn("Digest send failed, giving up on this slot", zap.Error(err), zap.Int32("digest_id", digest.ID), zap.Time("scheduled_for", digest.NextSendAt))
+		err = w.repo.RecordDigestFailed(record, digest, err, next)
+	default:
+		sendsTotal.WithLabelValues("retry").Inc()
+		w.log.Info("Digest send failed, will retry", zap.Error(err), zap.Int32("digest_id", digest.ID))
+		err = w.repo.RetryDigest(record, digest.ID, err, w.cfg.RetryInterval)
+	}
+	if err != nil {
+		w.log.Error("Failed to record digest send", zap.Error(err), zap.Int32("digest_id", digest.ID))
+	}
+}
@@ -0,0 +1,84 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	texttemplate "text/template"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var funcs = map[string]any{
+	// day formats a Weather.Datetime as a short date.
+	"day": func(datetime string) string {
+		t, err := models.ParseDatetime(datetime)
+		if err != nil {
+			return datetime
+		}
+		return t.Format("Mon 2 Jan")
+	},
+	"round": func(v float32) string {
+		return fmt.Sprintf("%.0f", math.Round(float64(v)))
+	},
+}
+
+// Every email has a text and an HTML template, templates/<name>.txt and
+// templates/<name>.html.
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(funcs).ParseFS(templateFS, "templates/*.txt"))
+)
+
+// Forecast is the weather for one location of a digest.
+type Forecast struct {
+	Label   string
+	Country string
+	// Current holds the current conditions and Days the forecast days shown.
+	Current *models.Weather
+	Days    []models.Weather
+	// Error is set instead when the forecast could not be fetched.
+	Error string
+}
+
+// view is the data the templates are executed with.
+type view struct {
+	Subject        string
+	Email          string
+	Frequency      string
+	Forecasts      []Forecast
+	UnsubscribeURL string
+	ConfirmURL     string
+}
+
+// NewForecast selects what a digest shows of weather: the current conditions
+// and the next days forecast days, starting today.
+func NewForecast(label string, weather models.WeatherResponse, days int) Forecast {
+	f := Forecast{Label: label, Country: weather.Country}
+	if len(weather.Days) > 0 {
+		current := weather.Days[0]
+		f.Current = &current
+		f.Days = weather.Days[1:]
+		if len(f.Days) > days {
+			f.Days = f.Days[:days]
+		}
+	}
+	return f
+}
+
+// render executes both templates of the named email.
+func render(name string, v view) (text, html string, err error) {
+	var textBuf, htmlBuf bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&textBuf, name+".txt", v); err != nil {
+		return "", "", err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&htmlBuf, name+".html", v); err != nil {
+		return "", "", err
+	}
+	return textBuf.String(), htmlBuf.String(), nil
+}
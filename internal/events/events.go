@@ -0,0 +1,232 @@
+// Package events is an in-process publish/subscribe bus for weather updates.
+// Whenever fresh data is fetched from the provider an Event is published, and
+// background consumers such as webhook evaluation subscribe to it.
+//
+// Publishing never blocks: a subscriber that falls behind by more than its
+// buffer misses events, which is counted in
+// weather_events_dropped_total. Consumers that must see every event, such as
+// webhook evaluation, use SubscribeLossless instead.
+//
+// The bus keeps the latest events so a consumer that reconnects, such as an
+// SSE client sending Last-Event-ID, can Resume where it left off. Event IDs
+// restart when the process does.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	publishedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_events_published_total",
+		Help: "Weather update events published.",
+	})
+	droppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_events_dropped_total",
+		Help: "Events not delivered to a subscriber because its buffer was full.",
+	})
+	subscribers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_events_subscribers",
+		Help: "Current event subscribers.",
+	})
+	queued = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_events_queued",
+		Help: "Events queued for lossless subscribers that have not taken them yet.",
+	})
+)
+
+// Event reports fresh weather data for a location.
+type Event struct {
+	// ID is assigned by Publish and increases with every event.
+	ID uint64
+	// City is the city the data was requested for, empty for coordinate
+	// requests.
+	City    string
+	Weather models.WeatherResponse
+	At      time.Time
+}
+
+// Bus fans events out to subscribers. The zero value is not usable; use NewBus.
+type Bus struct {
+	mu     sync.Mutex
+	lastID uint64
+	subs   map[*Subscription]struct{}
+	// history holds the latest events, oldest first, up to its capacity.
+	history []Event
+}
+
+// Subscription receives events on C until it is closed.
+type Subscription struct {
+	C   <-chan Event
+	c   chan Event
+	bus *Bus
+
+	// A lossless subscription queues events in pending, guarded by bus.mu,
+	// and a goroutine moves them to C. wake signals that pending has grown,
+	// done that the subscription is closed.
+	lossless bool
+	pending  []Event
+	wake     chan struct{}
+	done     chan struct{}
+}
+
+// NewBus returns a bus that keeps the latest replay events for Resume.
+func NewBus(replay int) *Bus {
+	return &Bus{
+		subs:    make(map[*Subscription]struct{}),
+		history: make([]Event, 0, replay),
+	}
+}
+
+// Publish assigns the event its ID and timestamp and delivers it to every
+// subscriber with room in its buffer. It returns the event as delivered.
+func (b *Bus) Publish(e Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = b.lastID
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	publishedTotal.Inc()
+
+	if cap(b.history) > 0 {
+		if len(b.history) == cap(b.history) {
+			copy(b.history, b.history[1:])
+			b.history = b.history[:len(b.history)-1]
+		}
+		b.history = append(b.history, e)
+	}
+
+	for s := range b.subs {
+		if s.lossless {
+			s.pending = append(s.pending, e)
+			queued.Inc()
+			select {
+			case s.wake <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			droppedTotal.Inc()
+		}
+	}
+	return e
+}
+
+// Subscribe returns a subscription whose channel holds up to buffer events.
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.subscribe(buffer)
+}
+
+// SubscribeLossless returns a subscription that receives every event, however
+// far it falls behind: events it has not taken yet are queued in memory without
+// bound rather than dropped, and Publish still never blocks. It is meant for
+// consumers that keep up on average but must not miss events in a burst.
+func (b *Bus) SubscribeLossless() *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.subscribe(0)
+	s.lossless = true
+	s.wake = make(chan struct{}, 1)
+	s.done = make(chan struct{})
+	go s.forward()
+	return s
+}
+
+// forward moves the events queued for a lossless subscription to C, in order,
+// until it is closed.
+func (s *Subscription) forward() {
+	defer close(s.c)
+	for {
+		s.bus.mu.Lock()
+		if len(s.pending) == 0 {
+			s.bus.mu.Unlock()
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		e := s.pending[0]
+		s.pending[0] = Event{}
+		s.pending = s.pending[1:]
+		queued.Dec()
+		s.bus.mu.Unlock()
+
+		select {
+		case s.c <- e:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Resume subscribes like Subscribe and also returns the kept events published
+// after the event with ID after, so that nothing is missed or repeated
+// between the two. complete is false if some of those events are no longer
+// kept, or if after is not an ID this bus has issued.
+func (b *Bus) Resume(buffer int, after uint64) (s *Subscription, missed []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = after <= b.lastID
+	if complete && after < b.lastID {
+		oldest := b.lastID + 1 - uint64(len(b.history))
+		complete = len(b.history) > 0 && after+1 >= oldest
+	}
+	for _, e := range b.history {
+		if e.ID > after {
+			missed = append(missed, e)
+		}
+	}
+	return b.subscribe(buffer), missed, complete
+}
+
+// LastID returns the ID of the latest event, zero if there is none.
+func (b *Bus) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+func (b *Bus) subscribe(buffer int) *Subscription {
+	c := make(chan Event, buffer)
+	s := &Subscription{C: c, c: c, bus: b}
+	b.subs[s] = struct{}{}
+	subscribers.Inc()
+	return s
+}
+
+// Close stops delivery and closes C. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if _, ok := s.bus.subs[s]; !ok {
+		return
+	}
+	delete(s.bus.subs, s)
+	subscribers.Dec()
+	if s.lossless {
+		// forward closes C once it sees done.
+		queued.Sub(float64(len(s.pending)))
+		s.pending = nil
+		close(s.done)
+		return
+	}
+	close(s.c)
+}
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribeLossless(t *testing.T) {
+	const events = 1000
+	b := NewBus(0)
+	lossy := b.Subscribe(10)
+	lossless := b.SubscribeLossless()
+
+	// A burst far larger than any buffer, with nobody reading.
+	for range events {
+		b.Publish(Event{City: "Berlin"})
+	}
+
+	if n := len(lossy.C); n != 10 {
+		t.Errorf("buffered subscription holds %d events, want its buffer of 10", n)
+	}
+	for want := uint64(1); want <= events; want++ {
+		select {
+		case e := <-lossless.C:
+			if e.ID != want {
+				t.Fatalf("got event %d, want %d", e.ID, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %d never arrived", want)
+		}
+	}
+
+	// Closing ends C, with or without events still queued.
+	b.Publish(Event{City: "Berlin"})
+	lossless.Close()
+	lossless.Close()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-lossless.C:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("C not closed")
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package expr implements the small expression language used to filter
+// forecast days and hours, for example
+//
+//	precip > 2 && windspeed < 8
+//	!(tempmin < 0) || snow >= 1.5
+//	tempmax - tempmin > 10
+//
+// Expressions combine the numeric fields of models.Weather, number literals,
+// true and false with the arithmetic operators + - * /, the comparisons
+// < <= > >= == != and the logical operators ! && ||, grouped with
+// parentheses. They cannot call functions or reach anything but the fields
+// listed in Fields, so they are safe to take from clients.
+//
+// An expression is parsed and type-checked once by Compile and can then be
+// evaluated any number of times. Errors are *Error values that give the
+// column of the offending token.
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// MaxLength is the longest expression Compile accepts.
+const MaxLength = 512
+
+// fields maps the names usable in an expression to the value they read.
+var fields = map[string]func(models.Weather) float64{
+	"temp":      func(w models.Weather) float64 { return float64(w.Temp) },
+	"tempmin":   func(w models.Weather) float64 { return float64(w.Tempmin) },
+	"tempmax":   func(w models.Weather) float64 { return float64(w.Tempmax) },
+	"humidity":  func(w models.Weather) float64 { return float64(w.Humidity) },
+	"precip":    func(w models.Weather) float64 { return float64(w.Precip) },
+	"snow":      func(w models.Weather) float64 { return float64(w.Snow) },
+	"snowdepth": func(w models.Weather) float64 { return float64(w.Snowdepth) },
+	"windspeed": func(w models.Weather) float64 { return float64(w.Windspeed) },
+}
+
+// Fields returns the field names usable in expressions, sorted.
+func Fields() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Error is a syntax or type error in an expression.
+type Error struct {
+	// Column is the 1-based position of the offending token.
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+// Program is a compiled expression.
+type Program struct {
+	source string
+	eval   func(models.Weather) bool
+}
+
+// Compile parses and type-checks src, which must be a boolean expression.
+func Compile(src string) (*Program, error) {
+	if utf8.RuneCountInString(src) > MaxLength {
+		return nil, &Error{Column: MaxLength + 1, Msg: fmt.Sprintf("expression longer than %d characters", MaxLength)}
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, errorAt(tok, "unexpected %s after expression", tok)
+	}
+	if n.typ != typeBool {
+		return nil, &Error{Column: n.pos + 1, Msg: `expression must be a condition, for example "precip > 2"`}
+	}
+	return &Program{source: strings.TrimSpace(src), eval: n.boolean}, nil
+}
+
+// Match reports whether the expression holds for w.
+func (p *Program) Match(w models.Weather) bool {
+	return p.eval(w)
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.source
+}
+
+// FilterDays returns the days of weather the expression selects. Each day's
+// hours are narrowed to the matching ones, and a day is kept if it matches
+// itself or has a matching hour. The input is not modified.
+func (p *Program) FilterDays(days []models.Weather) []models.Weather {
+	filtered := make([]models.Weather, 0, len(days))
+	for _, day := range days {
+		hours := day.Hours[:0:0]
+		for _, hour := range day.Hours {
+			if p.eval(hour) {
+				hours = append(hours, hour)
+			}
+		}
+		if len(hours) == 0 && !p.eval(day) {
+			continue
+		}
+		day.Hours = hours
+		filtered = append(filtered, day)
+	}
+	return filtered
+}
@@ -0,0 +1,153 @@
+package expr
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := lex("precip>=2.5 && !(temp2 < -1)")
+	if err != nil {
+		t.Fatalf("lex: %v", err)
+	}
+	type tok struct {
+		kind tokenKind
+		pos  int
+		text string
+	}
+	want := []tok{
+		{tokIdent, 0, "precip"},
+		{tokOp, 6, ">="},
+		{tokNumber, 8, "2.5"},
+		{tokOp, 12, "&&"},
+		{tokOp, 15, "!"},
+		{tokOp, 16, "("},
+		{tokIdent, 17, "temp2"},
+		{tokOp, 23, "<"},
+		{tokOp, 25, "-"},
+		{tokNumber, 26, "1"},
+		{tokOp, 27, ")"},
+		{tokEOF, 28, ""},
+	}
+	var got []tok
+	for _, t := range tokens {
+		got = append(got, tok{t.kind, t.pos, t.text})
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("tokens:\n got %v\nwant %v", got, want)
+	}
+	if tokens[2].num != 2.5 {
+		t.Errorf("number = %v, want 2.5", tokens[2].num)
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		src    string
+		column int
+		msg    string
+	}{
+		{"temp > 1.2.3", 8, `invalid number "1.2.3"`},
+		{"temp = 3", 6, `unexpected character '=', did you mean "=="?`},
+		{"temp > 3 & precip > 1", 10, `did you mean "&&"?`},
+		// Columns count characters, not bytes, and the character is shown
+		// whole.
+		{"tempé > 3", 5, `unexpected character 'é'`},
+		{"température > 3", 5, `unexpected character 'é'`},
+		{"é > 3 && ü", 1, `unexpected character 'é'`},
+		{"temp > 3 && °", 13, `unexpected character '°'`},
+		{"temp > 3 && 日本", 13, `unexpected character '日'`},
+		{"tmp > 3", 1, `unknown field "tmp"`},
+		{"temp > ", 8, `expected a field, number or "(", found end of expression`},
+		{"(temp > 3", 10, `expected ")" to close "(" at column 1`},
+		{"1 < temp < 3", 10, "comparisons cannot be chained"},
+		{"temp + 1", 1, "expression must be a condition"},
+		{"temp > 3 && 4", 13, `operator "&&" needs a condition here, found a number`},
+		{"!temp", 2, `operator "!" needs a condition here`},
+		{"temp > 3 )", 10, `unexpected ")" after expression`},
+		{"  tempmax -", 12, "found end of expression"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.src)
+		var exprErr *Error
+		if !errors.As(err, &exprErr) {
+			t.Errorf("%q: got %v, want an *Error", tt.src, err)
+			continue
+		}
+		if exprErr.Column != tt.column || !strings.Contains(exprErr.Msg, tt.msg) {
+			t.Errorf("%q: got column %d %q, want column %d containing %q", tt.src, exprErr.Column, exprErr.Msg, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestCompileMaxLength(t *testing.T) {
+	// Multi-byte characters count once towards the limit.
+	src := "temp > 1 " + strings.Repeat(" ", MaxLength-12) + "# é"
+	if _, err := Compile(src); err == nil || strings.Contains(err.Error(), "longer than") {
+		t.Errorf("%d characters: got %v, want a syntax error", len([]rune(src)), err)
+	}
+	src += "é"
+	var exprErr *Error
+	if _, err := Compile(src); !errors.As(err, &exprErr) || exprErr.Column != MaxLength+1 {
+		t.Errorf("%d characters: got %v, want the length error", len([]rune(src)), err)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	w := models.Weather{Temp: 20, Tempmin: 12, Tempmax: 25, Humidity: 80, Precip: 3, Snow: 0, Windspeed: 6}
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"precip > 2 && windspeed < 8", true},
+		{"precip > 2 && windspeed < 5", false},
+		{"!(tempmin < 0) || snow >= 1.5", true},
+		{"tempmax - tempmin > 10", true},
+		{"tempmax - tempmin * 2 > 10", false},
+		{"(tempmax - tempmin) * 2 > 10", true},
+		{"temp / 4 == 5", true},
+		{"-temp < -19", true},
+		{"humidity != 80", false},
+		{"true && !false", true},
+		{"false || temp <= 20 && temp >= 20", true},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.src)
+		if err != nil {
+			t.Errorf("%q: %v", tt.src, err)
+			continue
+		}
+		if got := p.Match(w); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestFilterDays(t *testing.T) {
+	days := []models.Weather{
+		{Datetime: "wet day", Precip: 5, Hours: []models.Weather{{Precip: 0}, {Precip: 4}}},
+		{Datetime: "dry day, wet hour", Precip: 0, Hours: []models.Weather{{Precip: 0}, {Precip: 3}}},
+		{Datetime: "dry", Precip: 0, Hours: []models.Weather{{Precip: 0}}},
+	}
+	p, err := Compile("precip > 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := p.FilterDays(days)
+	if len(got) != 2 || got[0].Datetime != "wet day" || got[1].Datetime != "dry day, wet hour" {
+		t.Fatalf("kept %v", got)
+	}
+	for _, day := range got {
+		if len(day.Hours) != 1 || day.Hours[0].Precip <= 2 {
+			t.Errorf("%s: hours %v, want the wet hour only", day.Datetime, day.Hours)
+		}
+	}
+	if len(days[0].Hours) != 2 {
+		t.Error("input days were modified")
+	}
+}
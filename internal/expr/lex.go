@@ -0,0 +1,95 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	// pos is the offset of the token in the source, in characters.
+	pos  int
+	text string
+	num  float64
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators are listed longest first so "<=" is not read as "<".
+var operators = []string{"&&", "||", "<=", ">=", "==", "!=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+// lex splits src into tokens. Every token is ASCII, so up to the first
+// error the byte offset i and the character offset col advance together.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	col := 0
+	for i := 0; i < len(src); {
+		c := src[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(c) || c == '.':
+			for i < len(src) && (isDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			num, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, &Error{Column: col + 1, Msg: fmt.Sprintf("invalid number %q", src[start:i])}
+			}
+			tokens = append(tokens, token{kind: tokNumber, pos: col, text: src[start:i], num: num})
+		case isLetter(c):
+			for i < len(src) && (isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, pos: col, text: src[start:i]})
+		default:
+			op := matchOperator(src[i:])
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(src[i:])
+				msg := fmt.Sprintf("unexpected character %q", r)
+				if c == '&' || c == '|' || c == '=' {
+					msg += fmt.Sprintf(", did you mean %q?", string([]byte{c, c}))
+				}
+				return nil, &Error{Column: col + 1, Msg: msg}
+			}
+			tokens = append(tokens, token{kind: tokOp, pos: col, text: op})
+			i += len(op)
+		}
+		col += i - start
+	}
+	return append(tokens, token{kind: tokEOF, pos: col}), nil
+}
+
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
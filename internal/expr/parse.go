@@ -0,0 +1,289 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeBool
+)
+
+func (t valueType) String() string {
+	if t == typeBool {
+		return "condition"
+	}
+	return "number"
+}
+
+// node is a type-checked subexpression compiled to a closure. Exactly one of
+// number and boolean is set, according to typ.
+type node struct {
+	pos     int
+	typ     valueType
+	number  func(models.Weather) float64
+	boolean func(models.Weather) bool
+}
+
+// parser is a recursive descent parser. From lowest to highest precedence the
+// levels are ||, &&, !, comparisons, + and -, * and /, unary minus.
+type parser struct {
+	tokens []token
+	next   int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token if it is one of the operators ops.
+func (p *parser) accept(ops ...string) (token, bool) {
+	tok := p.peek()
+	if tok.kind != tokOp {
+		return tok, false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.next++
+			return tok, true
+		}
+	}
+	return tok, false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("||")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return node{}, err
+		}
+		if err := expectTypes(op, typeBool, left, right); err != nil {
+			return node{}, err
+		}
+		l, r := left.boolean, right.boolean
+		left = node{pos: left.pos, typ: typeBool, boolean: func(w models.Weather) bool { return l(w) || r(w) }}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("&&")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return node{}, err
+		}
+		if err := expectTypes(op, typeBool, left, right); err != nil {
+			return node{}, err
+		}
+		l, r := left.boolean, right.boolean
+		left = node{pos: left.pos, typ: typeBool, boolean: func(w models.Weather) bool { return l(w) && r(w) }}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	op, ok := p.accept("!")
+	if !ok {
+		return p.parseComparison()
+	}
+	operand, err := p.parseNot()
+	if err != nil {
+		return node{}, err
+	}
+	if err := expectTypes(op, typeBool, operand); err != nil {
+		return node{}, err
+	}
+	f := operand.boolean
+	return node{pos: op.pos, typ: typeBool, boolean: func(w models.Weather) bool { return !f(w) }}, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return node{}, err
+	}
+	op, ok := p.accept("<", "<=", ">", ">=", "==", "!=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return node{}, err
+	}
+	if next, chained := p.accept("<", "<=", ">", ">=", "==", "!="); chained {
+		return node{}, errorAt(next, "comparisons cannot be chained; combine them with &&")
+	}
+
+	if (op.text == "==" || op.text == "!=") && left.typ == typeBool && right.typ == typeBool {
+		l, r := left.boolean, right.boolean
+		eq := op.text == "=="
+		return node{pos: left.pos, typ: typeBool, boolean: func(w models.Weather) bool { return (l(w) == r(w)) == eq }}, nil
+	}
+	if err := expectTypes(op, typeNumber, left, right); err != nil {
+		return node{}, err
+	}
+
+	l, r := left.number, right.number
+	var f func(models.Weather) bool
+	switch op.text {
+	case "<":
+		f = func(w models.Weather) bool { return l(w) < r(w) }
+	case "<=":
+		f = func(w models.Weather) bool { return l(w) <= r(w) }
+	case ">":
+		f = func(w models.Weather) bool { return l(w) > r(w) }
+	case ">=":
+		f = func(w models.Weather) bool { return l(w) >= r(w) }
+	case "==":
+		f = func(w models.Weather) bool { return l(w) == r(w) }
+	default:
+		f = func(w models.Weather) bool { return l(w) != r(w) }
+	}
+	return node{pos: left.pos, typ: typeBool, boolean: f}, nil
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return node{}, err
+		}
+		if left, err = arithmetic(op, left, right); err != nil {
+			return node{}, err
+		}
+	}
+}
+
+func (p *parser) parseProduct() (node, error) {
+	left, err := p.parseNegation()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseNegation()
+		if err != nil {
+			return node{}, err
+		}
+		if left, err = arithmetic(op, left, right); err != nil {
+			return node{}, err
+		}
+	}
+}
+
+func (p *parser) parseNegation() (node, error) {
+	op, ok := p.accept("-")
+	if !ok {
+		return p.parsePrimary()
+	}
+	operand, err := p.parseNegation()
+	if err != nil {
+		return node{}, err
+	}
+	if err := expectTypes(op, typeNumber, operand); err != nil {
+		return node{}, err
+	}
+	f := operand.number
+	return node{pos: op.pos, typ: typeNumber, number: func(w models.Weather) float64 { return -f(w) }}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNumber:
+		p.next++
+		v := tok.num
+		return node{pos: tok.pos, typ: typeNumber, number: func(models.Weather) float64 { return v }}, nil
+	case tokIdent:
+		p.next++
+		name := strings.ToLower(tok.text)
+		switch name {
+		case "true", "false":
+			v := name == "true"
+			return node{pos: tok.pos, typ: typeBool, boolean: func(models.Weather) bool { return v }}, nil
+		}
+		field, ok := fields[name]
+		if !ok {
+			return node{}, errorAt(tok, "unknown field %q; known fields are %s", tok.text, strings.Join(Fields(), ", "))
+		}
+		return node{pos: tok.pos, typ: typeNumber, number: field}, nil
+	}
+
+	if open, ok := p.accept("("); ok {
+		n, err := p.parseOr()
+		if err != nil {
+			return node{}, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return node{}, errorAt(p.peek(), "expected \")\" to close \"(\" at column %d, found %s", open.pos+1, p.peek())
+		}
+		n.pos = open.pos
+		return n, nil
+	}
+	return node{}, errorAt(tok, "expected a field, number or \"(\", found %s", tok)
+}
+
+// arithmetic combines two numeric operands with op.
+func arithmetic(op token, left, right node) (node, error) {
+	if err := expectTypes(op, typeNumber, left, right); err != nil {
+		return node{}, err
+	}
+	l, r := left.number, right.number
+	var f func(models.Weather) float64
+	switch op.text {
+	case "+":
+		f = func(w models.Weather) float64 { return l(w) + r(w) }
+	case "-":
+		f = func(w models.Weather) float64 { return l(w) - r(w) }
+	case "*":
+		f = func(w models.Weather) float64 { return l(w) * r(w) }
+	default:
+		f = func(w models.Weather) float64 { return l(w) / r(w) }
+	}
+	return node{pos: left.pos, typ: typeNumber, number: f}, nil
+}
+
+// expectTypes reports an error at the first operand of op that is not of
+// type want.
+func expectTypes(op token, want valueType, operands ...node) error {
+	for _, n := range operands {
+		if n.typ != want {
+			return &Error{Column: n.pos + 1, Msg: fmt.Sprintf("operator %q needs a %s here, found a %s", op.text, want, n.typ)}
+		}
+	}
+	return nil
+}
+
+func errorAt(tok token, format string, args ...any) *Error {
+	return &Error{Column: tok.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
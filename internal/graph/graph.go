@@ -0,0 +1,158 @@
+// Package graph serves the weather API over GraphQL, using the same
+// module.WeatherService as the HTTP handlers. The schema is in
+// schema.graphql.
+//
+// Every place named in a query is fetched once, when its root field is
+// resolved, however many of its fields are selected or however often it is
+// named. Before anything is fetched a root field is charged the complexity of
+// what it selects, roughly the number of values in its result, and queries
+// over the configured limit fail. Aliased copies of a field are charged
+// separately, as they are resolved separately.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+//go:embed schema.graphql
+var schema string
+
+var rejectedTotal = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "weather_graphql_complex_queries_total",
+	Help: "GraphQL fields rejected for exceeding the query complexity limit.",
+})
+
+// Sizes assumed for lists when estimating complexity.
+const (
+	listDays  = 16
+	listHours = 24
+)
+
+// Config limits queries.
+type Config struct {
+	MaxDepth       int
+	MaxQueryLength int
+	// MaxComplexity caps the estimated number of values in a result.
+	MaxComplexity int
+	// MaxPlaces caps the places of one locations field.
+	MaxPlaces int
+	// Concurrency is how many places of one query are fetched at once.
+	Concurrency int
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxDepth, validation.Required, validation.Min(1)),
+		validation.Field(&c.MaxQueryLength, validation.Required, validation.Min(1)),
+		validation.Field(&c.MaxComplexity, validation.Required, validation.Min(1)),
+		validation.Field(&c.MaxPlaces, validation.Required, validation.Min(1)),
+		validation.Field(&c.Concurrency, validation.Required, validation.Min(1)),
+	)
+}
+
+// Server executes GraphQL queries.
+type Server struct {
+	schema         *graphql.Schema
+	weatherService module.WeatherService
+	cfg            Config
+	log            *zap.Logger
+}
+
+func NewServer(weatherService module.WeatherService, cfg Config, logger *zap.Logger) *Server {
+	s := &Server{weatherService: weatherService, cfg: cfg, log: logger}
+	s.schema = graphql.MustParseSchema(schema, &queryResolver{s},
+		graphql.MaxDepth(cfg.MaxDepth),
+		graphql.MaxQueryLength(cfg.MaxQueryLength),
+		graphql.Logger(log.LoggerFunc(func(ctx context.Context, value any) {
+			logger.Error("GraphQL resolver panicked", zap.Any("panic", value), zap.Stack("stack"))
+		})),
+	)
+	return s
+}
+
+// Exec runs a query.
+func (s *Server) Exec(ctx context.Context, query, operationName string, variables map[string]any) *graphql.Response {
+	rq := &request{
+		loader:        newLoader(s.weatherService, s.cfg.Concurrency),
+		query:         query,
+		operationName: operationName,
+	}
+	return s.schema.Exec(context.WithValue(ctx, requestKey{}, rq), query, operationName, variables)
+}
+
+type requestKey struct{}
+
+// request is the state of one query.
+type request struct {
+	loader *loader
+
+	query         string
+	operationName string
+	parseOnce     sync.Once
+	doc           *document
+
+	mu         sync.Mutex
+	complexity int
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// document parses the query on first use. It is only called from resolvers,
+// after the schema has validated the query.
+func (rq *request) document() *document {
+	rq.parseOnce.Do(func() {
+		if doc, ok := parseDocument(rq.query, rq.operationName); ok {
+			rq.doc = doc
+		}
+	})
+	return rq.doc
+}
+
+// charge adds the complexity of the current root field, estimated from its
+// selection, to the query's and fails if that exceeds the limit. The field is
+// named field, resolves to count values, and lists sizes the list fields it
+// selects.
+func (s *Server) charge(ctx context.Context, field string, count int, lists map[string]int) error {
+	rq := requestFrom(ctx)
+	cost, ok := 0, false
+	if doc := rq.document(); doc != nil {
+		cost, ok = doc.rootCost(field, lists)
+	}
+	if !ok {
+		// Without the query the selected names are all there is, which
+		// misses aliased copies.
+		cost = 1
+		for _, path := range graphql.SelectedFieldNames(ctx) {
+			n := 1
+			for _, name := range strings.Split(path, ".") {
+				if size, ok := lists[name]; ok {
+					n *= size
+				}
+			}
+			cost += n
+		}
+	}
+	cost *= count
+	rq.mu.Lock()
+	defer rq.mu.Unlock()
+	rq.complexity += cost
+	if rq.complexity > s.cfg.MaxComplexity {
+		rejectedTotal.Inc()
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", rq.complexity, s.cfg.MaxComplexity)
+	}
+	return nil
+}
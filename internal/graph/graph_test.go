@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"go.uber.org/zap"
+)
+
+// fakeWeather answers every request with the same day of weather.
+type fakeWeather struct {
+	module.WeatherService
+}
+
+func (fakeWeather) GetWeather(_ context.Context, rq models.WeatherRequest) (models.WeatherResponse, error) {
+	return models.WeatherResponse{
+		Address: rq.City,
+		Days:    []models.Weather{{Datetime: "2026-01-01", Temp: 3}},
+	}, nil
+}
+
+func TestDocumentCost(t *testing.T) {
+	lists := map[string]int{"days": 10, "hours": 2}
+	tests := []struct {
+		name, query, operation string
+		root                   string
+		want                   int
+	}{
+		{"plain", `{ location(city: "Berlin") { address days { temp } } }`, "", "location", 1 + 1 + 10*2},
+		{"aliases", `{ location(city: "Berlin") { a: days { temp } b: days { temp } c: days { temp } } }`, "", "location", 1 + 3*10*2},
+		{"nested aliases", `{ location { days { h1: hours { temp } h2: hours { temp } } } }`, "", "location", 1 + 10*(1+2*2*2)},
+		{"same key merges", `{ location { days { temp } days { humidity } } }`, "", "location", 1 + 10*(1+2)},
+		{"alias of the same name merges", `{ location { d: days { temp } d: days { temp } } }`, "", "location", 1 + 10*2},
+		{"fragments", `
+			query Q { location { ...F ... on Location { x: days { temp } } } }
+			fragment F on Location { days { temp } y: days { temp __typename } }`, "", "location", 1 + 3*10*2},
+		{"largest root alias", `{ a: location { address } b: location { days { temp } } }`, "", "location", 1 + 10*2},
+		{"named operation", `query A { location { address } } query B { location { days { temp } } }`, "B", "location", 1 + 10*2},
+		{"strings and comments", `{ location(city: "{ days { temp } }") { # days { temp }
+			alerts(condition: """ } { """) { temp } days(filter: "temp > 1", limit: -1) @include(if: true) { temp } } }`, "", "location", 1 + 1*2 + 10*2},
+	}
+	for _, tt := range tests {
+		doc, ok := parseDocument(tt.query, tt.operation)
+		if !ok {
+			t.Errorf("%s: not parsed", tt.name)
+			continue
+		}
+		if got, _ := doc.rootCost(tt.root, lists); got != tt.want {
+			t.Errorf("%s: cost %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if _, ok := parseDocument(`query A { location { address } } query B { history { cursor } }`, ""); ok {
+		t.Error("an ambiguous operation was parsed")
+	}
+}
+
+func TestAliasesAreCharged(t *testing.T) {
+	cfg := Config{MaxDepth: 10, MaxQueryLength: 10000, MaxComplexity: 100, MaxPlaces: 10, Concurrency: 1}
+	s := NewServer(fakeWeather{}, cfg, zap.NewNop())
+
+	// 1 + 16 days * 2 values.
+	one := `{ location(city: "Berlin") { days { datetime temp } } }`
+	if resp := s.Exec(context.Background(), one, "", nil); len(resp.Errors) != 0 {
+		t.Fatalf("single selection rejected: %v", resp.Errors)
+	}
+
+	var aliased strings.Builder
+	aliased.WriteString(`{ location(city: "Berlin") {`)
+	for _, alias := range []string{"a", "b", "c", "d"} {
+		aliased.WriteString(" " + alias + `: days { datetime temp }`)
+	}
+	aliased.WriteString(" } }")
+	resp := s.Exec(context.Background(), aliased.String(), "", nil)
+	if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "complexity") {
+		t.Fatalf("aliased copies were not charged: %s %v", resp.Data, resp.Errors)
+	}
+}
@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+)
+
+// loader fetches the weather for the places of one query. Each place is
+// fetched once, and at most concurrency fetches run at a time.
+type loader struct {
+	weatherService module.WeatherService
+	sem            chan struct{}
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// entry is the weather for one place, available once done is closed.
+type entry struct {
+	done    chan struct{}
+	weather models.WeatherResponse
+	err     error
+}
+
+func newLoader(weatherService module.WeatherService, concurrency int) *loader {
+	return &loader{
+		weatherService: weatherService,
+		sem:            make(chan struct{}, concurrency),
+		entries:        make(map[string]*entry),
+	}
+}
+
+// load starts fetching rq unless it is already being fetched, and returns
+// its entry without waiting.
+func (l *loader) load(ctx context.Context, rq models.WeatherRequest) *entry {
+	key := fmt.Sprintf("%s|%g,%g|%s", strings.ToLower(rq.City), rq.Coordinate.Latitude, rq.Coordinate.Longitude, rq.DateTime)
+	l.mu.Lock()
+	e, ok := l.entries[key]
+	if !ok {
+		e = &entry{done: make(chan struct{})}
+		l.entries[key] = e
+	}
+	l.mu.Unlock()
+	if ok {
+		return e
+	}
+
+	go func() {
+		defer close(e.done)
+		select {
+		case l.sem <- struct{}{}:
+			defer func() { <-l.sem }()
+		case <-ctx.Done():
+			e.err = ctx.Err()
+			return
+		}
+		e.weather, e.err = l.weatherService.GetWeather(ctx, rq)
+	}()
+	return e
+}
+
+// loaded returns an entry for weather that is already at hand.
+func loaded(weather models.WeatherResponse) *entry {
+	e := &entry{done: make(chan struct{}), weather: weather}
+	close(e.done)
+	return e
+}
+
+func (e *entry) wait(ctx context.Context) (models.WeatherResponse, error) {
+	select {
+	case <-e.done:
+		return e.weather, e.err
+	case <-ctx.Done():
+		return models.WeatherResponse{}, ctx.Err()
+	}
+}
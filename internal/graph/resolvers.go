@@ -0,0 +1,388 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/expr"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// weatherLists sizes the lists under a Location when estimating complexity.
+var weatherLists = map[string]int{"days": listDays, "alerts": listDays, "hours": listHours}
+
+type queryResolver struct {
+	s *Server
+}
+
+type placeInput struct {
+	City      *string
+	Latitude  *float64
+	Longitude *float64
+}
+
+func (p placeInput) query() (models.LocationQuery, error) {
+	var q models.LocationQuery
+	if p.City != nil {
+		q.City = strings.TrimSpace(*p.City)
+	}
+	if p.Latitude != nil || p.Longitude != nil {
+		if p.Latitude == nil || p.Longitude == nil {
+			return models.LocationQuery{}, errors.New("latitude and longitude must be given together")
+		}
+		q.Coordinate = &models.Location{Latitude: *p.Latitude, Longitude: *p.Longitude}
+	}
+	return q, q.Validate()
+}
+
+func date(d *string) string {
+	if d == nil || *d == "" {
+		return time.Now().Format("2006-01-02")
+	}
+	return *d
+}
+
+func (q *queryResolver) Location(ctx context.Context, args struct {
+	placeInput
+	Date *string
+}) (*locationResolver, error) {
+	place, err := args.query()
+	if err != nil {
+		return nil, err
+	}
+	if err := q.s.charge(ctx, "location", 1, weatherLists); err != nil {
+		return nil, err
+	}
+
+	l := &locationResolver{requestFrom(ctx).loader.load(ctx, place.Request(date(args.Date)))}
+	if _, err := l.weather(ctx); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (q *queryResolver) Locations(ctx context.Context, args struct {
+	Places []placeInput
+	Date   *string
+}) ([]*locationResolver, error) {
+	if len(args.Places) > q.s.cfg.MaxPlaces {
+		return nil, fmt.Errorf("at most %d places can be queried at once", q.s.cfg.MaxPlaces)
+	}
+	places := make([]models.LocationQuery, 0, len(args.Places))
+	for i, p := range args.Places {
+		place, err := p.query()
+		if err != nil {
+			return nil, fmt.Errorf("places[%d]: %w", i, err)
+		}
+		places = append(places, place)
+	}
+	if err := q.s.charge(ctx, "locations", len(places), weatherLists); err != nil {
+		return nil, err
+	}
+
+	// All places are fetched at once; a place that fails is null in the
+	// result, with its error.
+	loader := requestFrom(ctx).loader
+	locations := make([]*locationResolver, 0, len(places))
+	for _, place := range places {
+		locations = append(locations, &locationResolver{loader.load(ctx, place.Request(date(args.Date)))})
+	}
+	return locations, nil
+}
+
+func (q *queryResolver) History(ctx context.Context, args struct {
+	placeInput
+	RadiusKm *float64
+	From     *graphql.Time
+	To       *graphql.Time
+	Cursor   *string
+	Limit    *int32
+}) (*historyPageResolver, error) {
+	filter := models.HistoryFilter{}
+	if args.City != nil {
+		filter.City = *args.City
+	}
+	if args.Latitude != nil || args.Longitude != nil {
+		if args.Latitude == nil || args.Longitude == nil {
+			return nil, errors.New("latitude and longitude must be given together")
+		}
+		filter.Coordinate = &models.Location{Latitude: *args.Latitude, Longitude: *args.Longitude}
+	}
+	if args.RadiusKm != nil {
+		filter.RadiusKm = *args.RadiusKm
+	}
+	if args.From != nil {
+		filter.From = args.From.Time
+	}
+	if args.To != nil {
+		filter.To = args.To.Time
+	}
+	if args.Cursor != nil {
+		filter.Cursor = *args.Cursor
+	}
+	if args.Limit != nil {
+		filter.Limit = int(*args.Limit)
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	items := filter.Limit
+	if items == 0 {
+		items = models.DefaultHistoryLimit
+	}
+	lists := map[string]int{"items": items}
+	for name, size := range weatherLists {
+		lists[name] = size
+	}
+	if err := q.s.charge(ctx, "history", 1, lists); err != nil {
+		return nil, err
+	}
+
+	page, err := q.s.weatherService.GetHistory(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &historyPageResolver{page}, nil
+}
+
+type locationResolver struct {
+	entry *entry
+}
+
+func (l *locationResolver) weather(ctx context.Context) (models.WeatherResponse, error) {
+	return l.entry.wait(ctx)
+}
+
+func (l *locationResolver) Address(ctx context.Context) (string, error) {
+	w, err := l.weather(ctx)
+	return w.Address, err
+}
+
+func (l *locationResolver) Country(ctx context.Context) (string, error) {
+	w, err := l.weather(ctx)
+	return w.Country, err
+}
+
+func (l *locationResolver) Latitude(ctx context.Context) (float64, error) {
+	w, err := l.weather(ctx)
+	return w.Latitude, err
+}
+
+func (l *locationResolver) Longitude(ctx context.Context) (float64, error) {
+	w, err := l.weather(ctx)
+	return w.Longitude, err
+}
+
+func (l *locationResolver) Timezone(ctx context.Context) (string, error) {
+	w, err := l.weather(ctx)
+	return w.Timezone, err
+}
+
+func (l *locationResolver) Source(ctx context.Context) (string, error) {
+	w, err := l.weather(ctx)
+	return w.Source, err
+}
+
+func (l *locationResolver) FetchedAt(ctx context.Context) (graphql.Time, error) {
+	w, err := l.weather(ctx)
+	return graphql.Time{Time: w.FetchedAt}, err
+}
+
+func (l *locationResolver) Origin(ctx context.Context) (string, error) {
+	w, err := l.weather(ctx)
+	return w.Origin, err
+}
+
+func (l *locationResolver) Age(ctx context.Context) (int32, error) {
+	w, err := l.weather(ctx)
+	return int32(w.Age), err
+}
+
+func (l *locationResolver) Stale(ctx context.Context) (bool, error) {
+	w, err := l.weather(ctx)
+	return w.Stale, err
+}
+
+func (l *locationResolver) Current(ctx context.Context) (*conditionsResolver, error) {
+	w, err := l.weather(ctx)
+	if err != nil || len(w.Days) == 0 {
+		return nil, err
+	}
+	return &conditionsResolver{w.Days[0]}, nil
+}
+
+func (l *locationResolver) Days(ctx context.Context, args struct {
+	Filter *string
+	Limit  *int32
+}) ([]*conditionsResolver, error) {
+	w, err := l.weather(ctx)
+	if err != nil {
+		return nil, err
+	}
+	days := w.Days
+	if args.Filter != nil && *args.Filter != "" {
+		filter, err := compile("filter", *args.Filter)
+		if err != nil {
+			return nil, err
+		}
+		days = filter.FilterDays(days)
+	}
+	if args.Limit != nil {
+		if *args.Limit < 0 {
+			return nil, errors.New("limit must not be negative")
+		}
+		days = days[:min(len(days), int(*args.Limit))]
+	}
+	return conditionsList(days), nil
+}
+
+func (l *locationResolver) Alerts(ctx context.Context, args struct{ Condition string }) ([]*conditionsResolver, error) {
+	w, err := l.weather(ctx)
+	if err != nil {
+		return nil, err
+	}
+	condition, err := compile("condition", args.Condition)
+	if err != nil {
+		return nil, err
+	}
+	var days []models.Weather
+	if len(w.Days) > 1 {
+		for _, day := range w.Days[1:] {
+			if condition.Match(day) {
+				days = append(days, day)
+			}
+		}
+	}
+	return conditionsList(days), nil
+}
+
+func (l *locationResolver) Anomalies(ctx context.Context) (*anomaliesResolver, error) {
+	w, err := l.weather(ctx)
+	if err != nil || w.Anomalies == nil {
+		return nil, err
+	}
+	return &anomaliesResolver{*w.Anomalies}, nil
+}
+
+// exprError reports an invalid expression argument with its column as an
+// extension.
+type exprError struct {
+	arg string
+	err *expr.Error
+}
+
+func (e *exprError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.arg, e.err)
+}
+
+func (e *exprError) Extensions() map[string]any {
+	return map[string]any{"column": e.err.Column}
+}
+
+func compile(arg, src string) (*expr.Program, error) {
+	program, err := expr.Compile(src)
+	var exprErr *expr.Error
+	if errors.As(err, &exprErr) {
+		return nil, &exprError{arg: arg, err: exprErr}
+	}
+	return program, err
+}
+
+// conditionsResolver resolves both Conditions and Day.
+type conditionsResolver struct {
+	w models.Weather
+}
+
+func conditionsList(days []models.Weather) []*conditionsResolver {
+	out := make([]*conditionsResolver, 0, len(days))
+	for _, d := range days {
+		out = append(out, &conditionsResolver{d})
+	}
+	return out
+}
+
+func (c *conditionsResolver) Datetime() string             { return c.w.Datetime }
+func (c *conditionsResolver) Temp() float64                { return float64(c.w.Temp) }
+func (c *conditionsResolver) Tempmin() float64             { return float64(c.w.Tempmin) }
+func (c *conditionsResolver) Tempmax() float64             { return float64(c.w.Tempmax) }
+func (c *conditionsResolver) Humidity() float64            { return float64(c.w.Humidity) }
+func (c *conditionsResolver) Precip() float64              { return float64(c.w.Precip) }
+func (c *conditionsResolver) Snow() float64                { return float64(c.w.Snow) }
+func (c *conditionsResolver) Snowdepth() float64           { return float64(c.w.Snowdepth) }
+func (c *conditionsResolver) Windspeed() float64           { return float64(c.w.Windspeed) }
+func (c *conditionsResolver) Hours() []*conditionsResolver { return conditionsList(c.w.Hours) }
+
+type anomaliesResolver struct {
+	a models.Anomalies
+}
+
+func (a *anomaliesResolver) Samples() int32                   { return int32(a.a.Samples) }
+func (a *anomaliesResolver) WindowDays() int32                { return int32(a.a.WindowDays) }
+func (a *anomaliesResolver) Temp() *anomalyScoreResolver      { return anomalyScore(a.a.Temp) }
+func (a *anomaliesResolver) Precip() *anomalyScoreResolver    { return anomalyScore(a.a.Precip) }
+func (a *anomaliesResolver) Windspeed() *anomalyScoreResolver { return anomalyScore(a.a.Windspeed) }
+
+func (a *anomaliesResolver) Flags() []string {
+	if a.a.Flags == nil {
+		return []string{}
+	}
+	return a.a.Flags
+}
+
+type anomalyScoreResolver struct {
+	s models.AnomalyScore
+}
+
+func anomalyScore(s *models.AnomalyScore) *anomalyScoreResolver {
+	if s == nil {
+		return nil
+	}
+	return &anomalyScoreResolver{*s}
+}
+
+func (s *anomalyScoreResolver) Value() float64  { return s.s.Value }
+func (s *anomalyScoreResolver) Mean() float64   { return s.s.Mean }
+func (s *anomalyScoreResolver) Stddev() float64 { return s.s.Stddev }
+func (s *anomalyScoreResolver) ZScore() float64 { return s.s.ZScore }
+
+type historyPageResolver struct {
+	page models.HistoryPage
+}
+
+func (p *historyPageResolver) Items() []*historyEntryResolver {
+	out := make([]*historyEntryResolver, 0, len(p.page.Items))
+	for _, e := range p.page.Items {
+		out = append(out, &historyEntryResolver{e})
+	}
+	return out
+}
+
+func (p *historyPageResolver) NextCursor() *string {
+	if p.page.NextCursor == "" {
+		return nil
+	}
+	return &p.page.NextCursor
+}
+
+type historyEntryResolver struct {
+	e models.HistoryEntry
+}
+
+func (h *historyEntryResolver) ID() int32               { return h.e.ID }
+func (h *historyEntryResolver) City() string            { return h.e.City }
+func (h *historyEntryResolver) Latitude() *float64      { return h.e.Latitude }
+func (h *historyEntryResolver) Longitude() *float64     { return h.e.Longitude }
+func (h *historyEntryResolver) QueryTime() graphql.Time { return graphql.Time{Time: h.e.QueryTime} }
+
+func (h *historyEntryResolver) Weather() *locationResolver {
+	var w models.WeatherResponse
+	if h.e.Weather != nil {
+		w = *h.e.Weather
+	}
+	return &locationResolver{loaded(w)}
+}
@@ -0,0 +1,314 @@
+package graph
+
+import (
+	"strings"
+)
+
+// The executor only tells resolvers which field names are selected beneath
+// them, not how often: aliases of one field collapse into a single name. To
+// charge every aliased copy, the query is walked here instead. Queries reach
+// this point only after the schema has validated them, so the parser does not
+// report errors; it just stops at anything it does not expect.
+
+// selection is an item of a selection set: a field with its sub-selection,
+// a fragment spread or an inline fragment.
+type selection struct {
+	alias    string
+	name     string
+	fragment string
+	inline   bool
+	children []selection
+}
+
+// key is the response key of a field, under which aliased copies are
+// resolved separately.
+func (s selection) key() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// document is the part of a query needed to estimate complexity.
+type document struct {
+	operation []selection
+	fragments map[string][]selection
+}
+
+// parseDocument returns the root selections of the named operation, or of
+// the only one if operationName is empty.
+func parseDocument(query, operationName string) (*document, bool) {
+	p := &selectionParser{tokens: tokenize(query)}
+	doc := &document{fragments: map[string][]selection{}}
+	operations := 0
+	found := false
+	for !p.done() {
+		switch tok := p.next(); tok {
+		case "{":
+			operations++
+			sels := p.selectionSet()
+			if operationName == "" {
+				doc.operation, found = sels, true
+			}
+		case "query", "mutation", "subscription":
+			operations++
+			name := ""
+			if p.peek() != "(" && p.peek() != "@" && p.peek() != "{" {
+				name = p.next()
+			}
+			p.skipArguments()
+			p.skipDirectives()
+			p.expect("{")
+			sels := p.selectionSet()
+			if name == operationName || operationName == "" {
+				doc.operation, found = sels, true
+			}
+		case "fragment":
+			name := p.next()
+			p.expect("on")
+			p.next()
+			p.skipDirectives()
+			p.expect("{")
+			doc.fragments[name] = p.selectionSet()
+		default:
+			return nil, false
+		}
+	}
+	if !found || (operationName == "" && operations != 1) {
+		return nil, false
+	}
+	return doc, true
+}
+
+// maxFragmentDepth bounds fragment expansion. Validated queries have no
+// fragment cycles; this only guards against surprises.
+const maxFragmentDepth = 64
+
+// fields flattens fragments in sels and merges fields with the same response
+// key, which the executor resolves once. The result keeps query order.
+func (d *document) fields(sels []selection) []selection {
+	var merged []selection
+	index := map[string]int{}
+	var collect func(sels []selection, depth int)
+	collect = func(sels []selection, depth int) {
+		if depth > maxFragmentDepth {
+			return
+		}
+		for _, s := range sels {
+			switch {
+			case s.inline:
+				collect(s.children, depth+1)
+			case s.fragment != "":
+				collect(d.fragments[s.fragment], depth+1)
+			case strings.HasPrefix(s.name, "__"):
+			default:
+				if i, ok := index[s.key()]; ok {
+					merged[i].children = append(merged[i].children, s.children...)
+					continue
+				}
+				index[s.key()] = len(merged)
+				s.children = append([]selection(nil), s.children...)
+				merged = append(merged, s)
+			}
+		}
+	}
+	collect(sels, 0)
+	return merged
+}
+
+// cost estimates the number of values sels resolve to: each field counts once
+// per element of the lists above it, sized by lists.
+func (d *document) cost(sels []selection, lists map[string]int, depth int) int {
+	if depth > maxFragmentDepth {
+		return 0
+	}
+	total := 0
+	for _, f := range d.fields(sels) {
+		n := 1
+		if size, ok := lists[f.name]; ok {
+			n = size
+		}
+		total += n * (1 + d.cost(f.children, lists, depth+1))
+	}
+	return total
+}
+
+// rootCost is the cost of one root field named name: the largest over the
+// response keys it appears under, as the resolver cannot tell which of them
+// it is resolving. It returns false if the field is not in the operation.
+func (d *document) rootCost(name string, lists map[string]int) (int, bool) {
+	cost, found := 0, false
+	for _, f := range d.fields(d.operation) {
+		if f.name != name {
+			continue
+		}
+		found = true
+		cost = max(cost, 1+d.cost(f.children, lists, 0))
+	}
+	return cost, found
+}
+
+type selectionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *selectionParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *selectionParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *selectionParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *selectionParser) expect(tok string) {
+	if p.peek() == tok {
+		p.pos++
+	}
+}
+
+// skipArguments skips a parenthesized argument or variable list, whose values
+// do not change the cost.
+func (p *selectionParser) skipArguments() {
+	if p.peek() != "(" {
+		return
+	}
+	for depth := 0; !p.done(); {
+		switch p.next() {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *selectionParser) skipDirectives() {
+	for p.peek() == "@" {
+		p.next()
+		p.next()
+		p.skipArguments()
+	}
+}
+
+// selectionSet parses up to the "}" closing a selection set whose "{" was
+// consumed.
+func (p *selectionParser) selectionSet() []selection {
+	var sels []selection
+	for !p.done() {
+		tok := p.next()
+		switch tok {
+		case "}":
+			return sels
+		case "...":
+			if p.peek() != "on" && p.peek() != "@" && p.peek() != "{" {
+				sels = append(sels, selection{fragment: p.next()})
+				p.skipDirectives()
+				continue
+			}
+			if p.peek() == "on" {
+				p.next()
+				p.next()
+			}
+			p.skipDirectives()
+			s := selection{inline: true}
+			if p.peek() == "{" {
+				p.next()
+				s.children = p.selectionSet()
+			}
+			sels = append(sels, s)
+		default:
+			s := selection{name: tok}
+			if p.peek() == ":" {
+				p.next()
+				s.alias, s.name = tok, p.next()
+			}
+			p.skipArguments()
+			p.skipDirectives()
+			if p.peek() == "{" {
+				p.next()
+				s.children = p.selectionSet()
+			}
+			sels = append(sels, s)
+		}
+	}
+	return sels
+}
+
+// tokenize splits a query into names, punctuators and literals, dropping
+// whitespace, commas and comments. Strings are kept as one token so braces
+// in them do not count.
+func tokenize(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case strings.HasPrefix(query[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case c == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.HasPrefix(query[i:], `"""`):
+			start := i
+			i += 3
+			for i < len(query) && !strings.HasPrefix(query[i:], `"""`) {
+				if strings.HasPrefix(query[i:], `\"""`) {
+					i += 4
+					continue
+				}
+				i++
+			}
+			i = min(i+3, len(query))
+			tokens = append(tokens, query[start:i])
+		case c == '"':
+			start := i
+			for i++; i < len(query) && query[i] != '"' && query[i] != '\n'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+			i = min(i+1, len(query))
+			tokens = append(tokens, query[start:i])
+		case isNameStart(c):
+			start := i
+			for i++; i < len(query) && (isNameStart(query[i]) || isDigit(query[i])); i++ {
+			}
+			tokens = append(tokens, query[start:i])
+		case isDigit(c) || c == '-':
+			start := i
+			for i++; i < len(query) && (isDigit(query[i]) || strings.IndexByte(".eE+-", query[i]) >= 0); i++ {
+			}
+			tokens = append(tokens, query[start:i])
+		default:
+			tokens = append(tokens, query[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
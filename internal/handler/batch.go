@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/expr"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var batchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+	Name:    "weather_batch_locations",
+	Help:    "Locations per batch weather request.",
+	Buckets: prometheus.ExponentialBuckets(1, 4, 6),
+})
+
+// BatchConfig limits batch weather requests.
+type BatchConfig struct {
+	// MaxLocations is how many locations one batch may ask for.
+	MaxLocations int
+	// Concurrency is how many locations of one batch are fetched at once.
+	Concurrency int
+}
+
+// BatchHandler serves the weather for many locations in one request.
+type BatchHandler struct {
+	weatherService module.WeatherService
+	cfg            BatchConfig
+	logger         *zap.Logger
+}
+
+// NewBatchHandler creates a new BatchHandler.
+func NewBatchHandler(weatherService module.WeatherService, cfg BatchConfig, logger *zap.Logger) *BatchHandler {
+	return &BatchHandler{
+		weatherService: weatherService,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// Batch handles POST /weather/batch requests.
+//
+// Locations are fetched through the weather service, and so its caches, at
+// most Concurrency at a time; repeated locations are fetched once. The
+// answer is 200 with a result per location, in order, unless the batch
+// itself is invalid. Failed locations carry the status and error a single
+// request would have answered with.
+func (h *BatchHandler) Batch(c *gin.Context) {
+	var in models.BatchRequest
+	if err := c.ShouldBindJSON(&in); err != nil {
+		writeError(c, h.logger, models.InvalidRequest(err))
+		return
+	}
+	if len(in.Locations) == 0 || len(in.Locations) > h.cfg.MaxLocations {
+		writeError(c, h.logger, models.InvalidRequest(fmt.Errorf("a batch needs between 1 and %d locations", h.cfg.MaxLocations)))
+		return
+	}
+	if in.Filter != "" {
+		if _, err := expr.Compile(in.Filter); err != nil {
+			writeError(c, h.logger, models.InvalidRequest(fmt.Errorf("%w: %w", models.ErrInvalidFilter, err)))
+			return
+		}
+	}
+	if in.Datetime == "" {
+		in.Datetime = time.Now().Format("2006-01-02")
+	}
+	batchSize.Observe(float64(len(in.Locations)))
+
+	results := h.weatherService.GetBatch(c.Request.Context(), in, h.cfg.Concurrency)
+	failed := 0
+	for _, r := range results {
+		if r.Error != nil {
+			failed++
+		}
+	}
+	h.logger.Info("Batch weather retrieved", zap.Int("locations", len(results)), zap.Int("failed", failed))
+	c.JSON(http.StatusOK, models.BatchResponse{Results: results})
+}
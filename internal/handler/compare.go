@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/compare"
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CompareConfig limits city comparisons.
+type CompareConfig struct {
+	// MaxCities is how many cities one comparison may cover.
+	MaxCities int
+	// Concurrency is how many cities of one comparison are fetched at once.
+	Concurrency int
+}
+
+// CompareHandler serves the weather of several cities side by side.
+type CompareHandler struct {
+	weatherService module.WeatherService
+	cfg            CompareConfig
+	logger         *zap.Logger
+}
+
+// NewCompareHandler creates a new CompareHandler.
+func NewCompareHandler(weatherService module.WeatherService, cfg CompareConfig, logger *zap.Logger) *CompareHandler {
+	return &CompareHandler{
+		weatherService: weatherService,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// Compare handles GET /weather/compare?cities=Berlin,Paris,Rome requests.
+//
+// cities is separated by commas, or by semicolons if it has any, so names
+// can be qualified as in "London,GB;Paris,FR". A name shared by several
+// places means the best match, as on /v1/weather. date (YYYY-MM-DD, default
+// today in the baseline's time zone) is the first day compared, and baseline
+// names the city the others
+// are compared to, by default the first. A city that fails is listed with
+// its error; the comparison fails only if the baseline does.
+func (h *CompareHandler) Compare(c *gin.Context) {
+	cities, err := parseCities(c.Query("cities"), h.cfg.MaxCities)
+	if err != nil {
+		writeError(c, h.logger, models.InvalidRequest(err))
+		return
+	}
+	date := c.Query("date")
+	if date != "" {
+		if _, err := time.Parse(compare.DateLayout, date); err != nil {
+			writeError(c, h.logger, models.InvalidRequest(fmt.Errorf("date must be YYYY-MM-DD, got %q", date)))
+			return
+		}
+	}
+	baseline := 0
+	if name := c.Query("baseline"); name != "" {
+		baseline = -1
+		for i, city := range cities {
+			if strings.EqualFold(city, strings.TrimSpace(name)) {
+				baseline = i
+			}
+		}
+		if baseline < 0 {
+			writeError(c, h.logger, models.InvalidRequest(fmt.Errorf("baseline %q is not one of the cities", name)))
+			return
+		}
+	}
+
+	// The forecast fetched does not depend on the date, which only selects
+	// the days compared.
+	fetchDate := date
+	if fetchDate == "" {
+		fetchDate = time.Now().Format(compare.DateLayout)
+	}
+	in := models.BatchRequest{Locations: make([]models.LocationQuery, len(cities)), Datetime: fetchDate}
+	for i, city := range cities {
+		in.Locations[i] = models.LocationQuery{City: city}
+	}
+	results := h.weatherService.GetBatch(c.Request.Context(), in, h.cfg.Concurrency)
+	if base := results[baseline]; base.Error != nil {
+		status, body := base.Status, *base.Error
+		body.RequestID = c.Writer.Header().Get(models.RequestIDHeader)
+		h.logger.Warn("Baseline city failed", zap.String("city", cities[baseline]), zap.String("code", body.Code))
+		c.JSON(status, body)
+		return
+	}
+
+	comparison, err := compare.Compare(date, cities, results, baseline)
+	if err != nil {
+		if errors.Is(err, compare.ErrNoCommonForecast) {
+			err = models.InvalidRequest(err)
+		}
+		writeError(c, h.logger, err)
+		return
+	}
+	h.logger.Info("Cities compared", zap.Strings("cities", cities), zap.String("date", comparison.Date))
+	c.JSON(http.StatusOK, comparison)
+}
+
+// parseCities splits the cities parameter, rejecting empty names and
+// repeated cities.
+func parseCities(s string, max int) ([]string, error) {
+	sep := ","
+	if strings.Contains(s, ";") {
+		sep = ";"
+	}
+	var cities []string
+	seen := make(map[string]bool)
+	for _, city := range strings.Split(s, sep) {
+		city = strings.TrimSpace(city)
+		key := strings.ToLower(city)
+		switch {
+		case city == "":
+			return nil, errors.New("cities must be a list of city names")
+		case len(city) > 100:
+			return nil, errors.New("city must be at most 100 characters")
+		case seen[key]:
+			return nil, fmt.Errorf("city %q is listed twice", city)
+		}
+		seen[key] = true
+		cities = append(cities, city)
+	}
+	if len(cities) < 2 || len(cities) > max {
+		return nil, fmt.Errorf("a comparison needs between 2 and %d cities", max)
+	}
+	return cities, nil
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/compare"
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ambiguousProvider geocodes every city to several places, as the provider
+// does for common names, and forecasts a week from today in Berlin time.
+type ambiguousProvider struct{}
+
+func (ambiguousProvider) Geocode(_ context.Context, city string) (models.Place, error) {
+	best := models.Place{Name: city, Country: "DE", Coordinate: models.Location{Latitude: 52.52, Longitude: 13.4}}
+	other := models.Place{Name: city, State: "Texas", Country: "US", Coordinate: models.Location{Latitude: 33.6, Longitude: -95.5}}
+	best.Candidates = []models.Place{best, other}
+	return best, nil
+}
+
+func (p ambiguousProvider) GetWeather(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error {
+	place, err := p.Geocode(ctx, rq.City)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	current := models.Weather{Datetime: models.FormatDatetime(now), Temp: 10}
+	for i := range 48 {
+		at := now.Truncate(time.Hour).Add(time.Duration(i) * time.Hour)
+		current.Hours = append(current.Hours, models.Weather{Datetime: models.FormatDatetime(at), Temp: 10})
+	}
+	response.Address = place.Name
+	response.Timezone = loc.String()
+	response.Latitude = place.Coordinate.Latitude
+	response.Longitude = place.Coordinate.Longitude
+	response.Days = []models.Weather{current}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, loc)
+	for i := -1; i < 7; i++ {
+		response.Days = append(response.Days, models.Weather{Datetime: models.FormatDatetime(today.AddDate(0, 0, i)), Temp: 10})
+	}
+	return nil
+}
+
+func TestCompareExample(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := module.NewService(ambiguousProvider{}, repository.NewMemoryRepository(10), nil, events.NewBus(1), module.Config{CacheTTL: time.Hour}, zap.NewNop())
+	h := NewCompareHandler(svc, CompareConfig{MaxCities: 5, Concurrency: 2}, zap.NewNop())
+	r := gin.New()
+	r.GET("/v1/weather/compare", h.Compare)
+
+	// Each of the names is shared by several places; v1 takes the best match.
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/weather/compare?cities=Berlin,Paris,Rome", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+	}
+	var body models.Comparison
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Locations) != 3 || len(body.Days) == 0 {
+		t.Fatalf("%d locations over %d days, want 3 over some days", len(body.Locations), len(body.Days))
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().In(berlin).Format(compare.DateLayout)
+	if body.Date != today || body.Days[0] != today {
+		t.Errorf("date %s, first day %s, want today in Berlin, %s", body.Date, body.Days[0], today)
+	}
+	for _, l := range body.Locations {
+		if l.Error != nil || len(l.Daily) != len(body.Days) {
+			t.Errorf("%s: error %v, %d days", l.City, l.Error, len(l.Daily))
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var deprecatedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "weather_deprecated_requests_total",
+	Help: "Requests to deprecated routes, by route.",
+}, []string{"route"})
+
+// Deprecated marks the responses of the routes it wraps as deprecated since
+// the given time (RFC 9745) and going away at sunset (RFC 8594). The Link
+// header points to the same request under successor, such as "/v1".
+func Deprecated(since, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(since.Unix(), 10)
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(c *gin.Context) {
+		deprecatedRequests.WithLabelValues(c.FullPath()).Inc()
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunsetDate)
+		c.Header("Link", "<"+successor+c.Request.URL.RequestURI()+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
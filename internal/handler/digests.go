@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DigestHandler handles HTTP requests for email digest subscriptions.
+type DigestHandler struct {
+	digestService module.DigestService
+	logger        *zap.Logger
+}
+
+// NewDigestHandler creates a new DigestHandler.
+func NewDigestHandler(digestService module.DigestService, logger *zap.Logger) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+		logger:        logger,
+	}
+}
+
+// CreateDigest handles POST /digests requests. The response includes the
+// unsubscribe token; it is not shown again.
+func (h *DigestHandler) CreateDigest(c *gin.Context) {
+	var in models.DigestInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		writeError(c, h.logger, models.InvalidRequest(err))
+		return
+	}
+
+	digest, err := h.digestService.CreateDigest(c.Request.Context(), in)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusCreated, digest)
+}
+
+// GetDigest handles GET /digests/:id requests.
+func (h *DigestHandler) GetDigest(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	digest, err := h.digestService.GetDigest(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, digest)
+}
+
+// DeleteDigest handles DELETE /digests/:id requests.
+func (h *DigestHandler) DeleteDigest(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.digestService.DeleteDigest(c.Request.Context(), id); err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /digests/:id/deliveries requests.
+func (h *DigestHandler) ListDeliveries(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.digestService.ListDigestDeliveries(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": deliveries})
+}
+
+// SendDigest handles POST /digests/:id/send requests, which send the digest
+// right away to check the mail setup.
+func (h *DigestHandler) SendDigest(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.digestService.SendDigest(c.Request.Context(), id); err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// Confirm handles GET and POST /digests/confirm?token= requests. GET is the
+// link in the confirmation email and only shows a page asking to confirm, so
+// that mail scanners following links do not confirm addresses; POST confirms.
+func (h *DigestHandler) Confirm(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		h.page(c, http.StatusBadRequest, page{Title: "Missing confirmation token."})
+		return
+	}
+	if c.Request.Method == http.MethodGet {
+		h.page(c, http.StatusOK, page{
+			Title:  "Confirm your weather digest?",
+			Action: formAction(c, token),
+			Button: "Confirm",
+		})
+		return
+	}
+
+	digest, err := h.digestService.Confirm(c.Request.Context(), token)
+	if errors.Is(err, models.ErrDigestNotFound) {
+		h.page(c, http.StatusNotFound, page{Title: "This confirmation link is not valid."})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to confirm digest", zap.Error(err))
+		h.page(c, http.StatusInternalServerError, page{Title: "Confirming failed, please try again later."})
+		return
+	}
+	h.page(c, http.StatusOK, page{Title: fmt.Sprintf("%s will receive a %s weather digest.", digest.Email, digest.Frequency)})
+}
+
+// Unsubscribe handles GET and POST /digests/unsubscribe?token= requests. GET
+// is the link in the email and only shows a page asking to unsubscribe, so
+// that mail scanners following links do not unsubscribe anyone. POST
+// unsubscribes; it is also the one-click unsubscribe mail clients send for the
+// List-Unsubscribe header.
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		h.page(c, http.StatusBadRequest, page{Title: "Missing unsubscribe token."})
+		return
+	}
+	if c.Request.Method == http.MethodGet {
+		h.page(c, http.StatusOK, page{
+			Title:  "Unsubscribe from weather digests?",
+			Action: formAction(c, token),
+			Button: "Unsubscribe",
+		})
+		return
+	}
+
+	digest, err := h.digestService.Unsubscribe(c.Request.Context(), token)
+	if errors.Is(err, models.ErrDigestNotFound) {
+		h.page(c, http.StatusNotFound, page{Title: "This unsubscribe link is not valid."})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to unsubscribe digest", zap.Error(err))
+		h.page(c, http.StatusInternalServerError, page{Title: "Unsubscribing failed, please try again later."})
+		return
+	}
+	h.page(c, http.StatusOK, page{Title: digest.Email + " will no longer receive weather digests."})
+}
+
+// page is a minimal HTML page for the links in digest emails. If Action is
+// set it shows a form posting to it.
+type page struct {
+	Title  string
+	Action string
+	Button string
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+</head>
+<body style="font-family: sans-serif; color: #222;">
+<h1 style="font-size: 20px;">{{.Title}}</h1>
+{{- if .Action}}
+<form method="post" action="{{.Action}}">
+<button type="submit">{{.Button}}</button>
+</form>
+{{- end}}
+</body>
+</html>
+`))
+
+func (h *DigestHandler) page(c *gin.Context, status int, p page) {
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, p); err != nil {
+		h.logger.Error("Failed to render page", zap.Error(err))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// formAction is the current path with the token, for a form posting back to
+// the same endpoint.
+func formAction(c *gin.Context, token string) string {
+	return c.Request.URL.Path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// fakeDigests records the tokens it was asked to confirm or unsubscribe.
+type fakeDigests struct {
+	module.DigestService
+	digest       models.DigestSubscription
+	confirmed    []string
+	unsubscribed []string
+}
+
+func (f *fakeDigests) Confirm(_ context.Context, token string) (models.DigestSubscription, error) {
+	if token != "good" {
+		return models.DigestSubscription{}, models.ErrDigestNotFound
+	}
+	f.confirmed = append(f.confirmed, token)
+	return f.digest, nil
+}
+
+func (f *fakeDigests) Unsubscribe(_ context.Context, token string) (models.DigestSubscription, error) {
+	if token != "good" {
+		return models.DigestSubscription{}, models.ErrDigestNotFound
+	}
+	f.unsubscribed = append(f.unsubscribed, token)
+	return f.digest, nil
+}
+
+func (f *fakeDigests) SendDigest(context.Context, int32) error {
+	if f.digest.ConfirmedAt == nil {
+		return models.ErrDigestNotConfirmed
+	}
+	return nil
+}
+
+func digestRouter(f *fakeDigests) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewDigestHandler(f, zap.NewNop())
+	r := gin.New()
+	r.GET("/v1/digests/confirm", h.Confirm)
+	r.POST("/v1/digests/confirm", h.Confirm)
+	r.GET("/v1/digests/unsubscribe", h.Unsubscribe)
+	r.POST("/v1/digests/unsubscribe", h.Unsubscribe)
+	r.POST("/v1/digests/:id/send", h.SendDigest)
+	return r
+}
+
+func serve(r http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestDigestLinksOnlyActOnPost(t *testing.T) {
+	for _, path := range []string{"/v1/digests/confirm", "/v1/digests/unsubscribe"} {
+		f := &fakeDigests{digest: models.DigestSubscription{Email: "a@example.com", DigestSchedule: models.DigestSchedule{Frequency: models.DigestDaily}}}
+		r := digestRouter(f)
+
+		w := serve(r, http.MethodGet, path+"?token=good")
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, w.Code)
+		}
+		if len(f.confirmed)+len(f.unsubscribed) != 0 {
+			t.Errorf("GET %s changed the digest", path)
+		}
+		form := `<form method="post" action="` + path + `?token=good">`
+		if body := w.Body.String(); !strings.Contains(body, form) {
+			t.Errorf("GET %s: page has no form posting back:\n%s", path, body)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+			t.Errorf("GET %s: Content-Type %q", path, ct)
+		}
+
+		w = serve(r, http.MethodPost, path+"?token=good")
+		if w.Code != http.StatusOK {
+			t.Fatalf("POST %s: status %d", path, w.Code)
+		}
+		if len(f.confirmed)+len(f.unsubscribed) != 1 {
+			t.Errorf("POST %s: confirmed %v, unsubscribed %v", path, f.confirmed, f.unsubscribed)
+		}
+		if body := w.Body.String(); !strings.Contains(body, "a@example.com") {
+			t.Errorf("POST %s: page does not name the address:\n%s", path, body)
+		}
+
+		if w := serve(r, http.MethodPost, path+"?token=bad"); w.Code != http.StatusNotFound {
+			t.Errorf("POST %s with an unknown token: status %d, want 404", path, w.Code)
+		}
+		if w := serve(r, http.MethodGet, path); w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s without a token: status %d, want 400", path, w.Code)
+		}
+	}
+}
+
+func TestSendDigestNeedsConfirmation(t *testing.T) {
+	r := digestRouter(&fakeDigests{})
+	if w := serve(r, http.MethodPost, "/v1/digests/1/send"); w.Code != http.StatusConflict {
+		t.Errorf("send unconfirmed: status %d, want 409", w.Code)
+	}
+}
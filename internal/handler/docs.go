@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"path"
+
+	"github.com/Orion777-cmd/weather-app/internal/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// DocsHandler serves the OpenAPI description and its docs page.
+type DocsHandler struct {
+	spec *openapi.Spec
+}
+
+// NewDocsHandler creates a new DocsHandler.
+func NewDocsHandler(spec *openapi.Spec) *DocsHandler {
+	return &DocsHandler{spec: spec}
+}
+
+// Spec handles GET /openapi.json requests.
+func (h *DocsHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", h.spec.JSON())
+}
+
+// Docs handles GET /docs requests.
+func (h *DocsHandler) Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.Docs())
+}
+
+// Asset handles GET /docs/swagger-ui/:file requests for the files the docs
+// page loads.
+func (h *DocsHandler) Asset(c *gin.Context) {
+	name := c.Param("file")
+	data, ok := openapi.DocsAsset(name)
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Data(http.StatusOK, contentType, data)
+}
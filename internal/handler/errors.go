@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/Orion777-cmd/weather-app/internal/apierror"
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// validRequestID limits which client supplied request IDs are kept, so they
+// are safe to log and echo.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID gives every request an ID, taken from its X-Request-ID header if
+// that is well formed and generated otherwise. The ID is sent back in the
+// same header and repeated in error bodies.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(models.RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			var b [16]byte
+			rand.Read(b[:])
+			id = hex.EncodeToString(b[:])
+		}
+		c.Header(models.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// writeError answers with the status and code for the kind of err.
+func writeError(c *gin.Context, logger *zap.Logger, err error) {
+	status, body := apierror.Response(err)
+	body.RequestID = c.Writer.Header().Get(models.RequestIDHeader)
+	var rateLimited *models.RateLimitedError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(rateLimited.RetryAfter.Seconds()))))
+	}
+
+	fields := []zap.Field{zap.Error(err), zap.String("code", body.Code), zap.String("request_id", body.RequestID)}
+	if status >= http.StatusInternalServerError {
+		logger.Error("Request failed", fields...)
+	} else {
+		logger.Warn("Request failed", fields...)
+	}
+	c.JSON(status, body)
+}
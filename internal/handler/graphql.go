@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/graph"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// GraphQLHandler serves GraphQL queries.
+type GraphQLHandler struct {
+	server *graph.Server
+	logger *zap.Logger
+}
+
+// NewGraphQLHandler creates a new GraphQLHandler.
+func NewGraphQLHandler(server *graph.Server, logger *zap.Logger) *GraphQLHandler {
+	return &GraphQLHandler{server: server, logger: logger}
+}
+
+type graphQLParams struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Query handles GET and POST /graphql requests. POST takes a JSON body with
+// query, operationName and variables; GET takes the same as query
+// parameters, with variables JSON encoded.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var params graphQLParams
+	if c.Request.Method == http.MethodGet {
+		params.Query = c.Query("query")
+		params.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+				writeError(c, h.logger, models.InvalidRequest(fmt.Errorf("invalid variables: %w", err)))
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&params); err != nil {
+		writeError(c, h.logger, models.InvalidRequest(fmt.Errorf("invalid request body: %w", err)))
+		return
+	}
+	if params.Query == "" {
+		writeError(c, h.logger, models.InvalidRequest(errors.New("query is required")))
+		return
+	}
+
+	response := h.server.Exec(c.Request.Context(), params.Query, params.OperationName, params.Variables)
+	if len(response.Errors) > 0 {
+		h.logger.Info("GraphQL query failed", zap.String("operation", params.OperationName), zap.Any("errors", response.Errors))
+	}
+	c.JSON(http.StatusOK, response)
+}
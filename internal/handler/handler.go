@@ -1,9 +1,9 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"time"
-	"context"
 	"strings"
 	"strconv"
 
@@ -28,7 +28,29 @@ func NewWeatherHandler(weatherService module.WeatherService, logger *zap.Logger)
 }
 
 // GetWeather handles GET /weather requests.
+//
+// The optional filter query parameter is an expression such as
+// "precip > 2 && windspeed < 8" that limits the response to the matching
+// days and hours.
 func (h *WeatherHandler) GetWeather(c *gin.Context) {
+	if weather, ok := h.weather(c, false); ok {
+		c.JSON(http.StatusOK, weather)
+	}
+}
+
+// GetWeatherV2 handles GET /v2/weather requests, which take the same
+// parameters as GetWeather. A city matching several places is answered with
+// the candidates rather than the best match.
+func (h *WeatherHandler) GetWeatherV2(c *gin.Context) {
+	if weather, ok := h.weather(c, true); ok {
+		c.JSON(http.StatusOK, weather.V2())
+	}
+}
+
+// weather fetches the requested weather and sets the cache headers,
+// answering 400 if the request is invalid. rejectAmbiguous is passed on as
+// WeatherRequest.RejectAmbiguous.
+func (h *WeatherHandler) weather(c *gin.Context, rejectAmbiguous bool) (models.WeatherResponse, bool) {
     city := c.Query("city")
     coordinateStr := c.Query("coordinate")
     datetime := c.Query("datetime")
@@ -36,13 +58,10 @@ func (h *WeatherHandler) GetWeather(c *gin.Context) {
 	h.logger.Info("abiy Received weather request", zap.String("city", city), zap.String("coordinate", coordinateStr), zap.String("datetime", datetime))
     var location models.Location
     if coordinateStr != "" {
-        parts := strings.Split(coordinateStr, ",")
-        if len(parts) == 2 {
-            lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
-            lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
-            if err1 == nil && err2 == nil {
-                location = models.Location{Latitude: lat, Longitude: lon}
-            }
+        var err error
+        if location, err = parseCoordinate(coordinateStr); err != nil {
+            writeError(c, h.logger, models.InvalidRequest(err))
+            return models.WeatherResponse{}, false
         }
     }
 
@@ -50,6 +69,8 @@ func (h *WeatherHandler) GetWeather(c *gin.Context) {
         City:     city,
         Coordinate: location,
         DateTime: datetime,
+        Filter:   c.Query("filter"),
+        RejectAmbiguous: rejectAmbiguous,
     }
     if rq.DateTime == "" {
         rq.DateTime = time.Now().Format("2006-01-02")
@@ -57,28 +78,141 @@ func (h *WeatherHandler) GetWeather(c *gin.Context) {
 
     weather, err := h.weatherService.GetWeather(c.Request.Context(), rq)
     if err != nil {
-        h.logger.Error("Failed to fetch weather", zap.Error(err), zap.Any("request", rq))
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
+        writeError(c, h.logger.With(zap.Any("request", rq)), err)
+        return models.WeatherResponse{}, false
     }
 
-    h.logger.Info("Weather retrieved", zap.Any("request", rq))
-    c.JSON(http.StatusOK, weather)
+    h.logger.Info("Weather retrieved", zap.Any("request", rq), zap.String("origin", weather.Origin))
+    setCacheHeaders(c, weather)
+    return weather, true
 }
 
 // GetHistory handles GET /history requests.
+//
+// Supported query parameters are city, coordinate (lat,lon) with radius in
+// kilometers, from and to (RFC3339 or YYYY-MM-DD), cursor, limit and summary.
 func (h *WeatherHandler) GetHistory(c *gin.Context) {
-	// Assuming Persistence is injected or accessible via WeatherService
-	// For simplicity, we'll call a method on WeatherService (to be added)
-	history, err := h.weatherService.(interface {
-		GetWeatherHistory(ctx context.Context) ([]interface{}, error)
-	}).GetWeatherHistory(c.Request.Context())
+	if history, ok := h.history(c); ok {
+		c.JSON(http.StatusOK, history)
+	}
+}
+
+// GetHistoryV2 handles GET /v2/history requests, which take the same
+// parameters as GetHistory.
+func (h *WeatherHandler) GetHistoryV2(c *gin.Context) {
+	if history, ok := h.history(c); ok {
+		c.JSON(http.StatusOK, history.V2())
+	}
+}
+
+// history fetches the requested page of history, answering with an error
+// if that fails.
+func (h *WeatherHandler) history(c *gin.Context) (models.HistoryPage, bool) {
+	filter, err := parseHistoryFilter(c)
+	if err == nil {
+		err = filter.Validate()
+	}
+	if err != nil {
+		writeError(c, h.logger, models.InvalidRequest(err))
+		return models.HistoryPage{}, false
+	}
+
+	history, err := h.weatherService.GetHistory(c.Request.Context(), filter)
 	if err != nil {
-		h.logger.Error("Failed to retrieve history", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeError(c, h.logger, err)
+		return models.HistoryPage{}, false
+	}
+
+	h.logger.Info("History retrieved", zap.Int("items", len(history.Items)))
+	return history, true
+}
+
+// GetStats handles GET /stats requests.
+func (h *WeatherHandler) GetStats(c *gin.Context) {
+	stats, err := h.weatherService.GetStats(c.Request.Context())
+	if err != nil {
+		writeError(c, h.logger, err)
 		return
 	}
+	c.JSON(http.StatusOK, stats)
+}
 
-	h.logger.Info("History retrieved")
-	c.JSON(http.StatusOK, history)
-}
\ No newline at end of file
+// setCacheHeaders reports whether the response was served from the cache and
+// how old its data is. Stale data also gets a Warning header.
+func setCacheHeaders(c *gin.Context, weather models.WeatherResponse) {
+	if weather.Stale {
+		c.Header("X-Cache", "STALE")
+		c.Header("Warning", `110 - "Response is Stale"`)
+	} else if weather.Origin == models.OriginCache {
+		c.Header("X-Cache", "HIT")
+	} else {
+		c.Header("X-Cache", "MISS")
+	}
+	c.Header("Age", strconv.FormatInt(weather.Age, 10))
+}
+
+func parseHistoryFilter(c *gin.Context) (models.HistoryFilter, error) {
+	filter := models.HistoryFilter{
+		City:   c.Query("city"),
+		Cursor: c.Query("cursor"),
+	}
+
+	if coordinateStr := c.Query("coordinate"); coordinateStr != "" {
+		location, err := parseCoordinate(coordinateStr)
+		if err != nil {
+			return models.HistoryFilter{}, err
+		}
+		filter.Coordinate = &location
+	}
+
+	var err error
+	if radius := c.Query("radius"); radius != "" {
+		if filter.RadiusKm, err = strconv.ParseFloat(radius, 64); err != nil {
+			return models.HistoryFilter{}, fmt.Errorf("invalid radius: %q", radius)
+		}
+	}
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = parseTime(from); err != nil {
+			return models.HistoryFilter{}, fmt.Errorf("invalid from: %q", from)
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = parseTime(to); err != nil {
+			return models.HistoryFilter{}, fmt.Errorf("invalid to: %q", to)
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return models.HistoryFilter{}, fmt.Errorf("invalid limit: %q", limit)
+		}
+	}
+	if summary := c.Query("summary"); summary != "" {
+		if filter.Summary, err = strconv.ParseBool(summary); err != nil {
+			return models.HistoryFilter{}, fmt.Errorf("invalid summary: %q", summary)
+		}
+	}
+
+	return filter, nil
+}
+
+// parseCoordinate parses a "lat,lon" pair.
+func parseCoordinate(s string) (models.Location, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return models.Location{}, fmt.Errorf("invalid coordinate: %q", s)
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil {
+		return models.Location{}, fmt.Errorf("invalid coordinate: %q", s)
+	}
+	return models.Location{Latitude: lat, Longitude: lon}, nil
+}
+
+// parseTime accepts either an RFC3339 timestamp or a plain date.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LocationHandler handles HTTP requests for watched locations.
+type LocationHandler struct {
+	locationService module.WatchedLocationService
+	logger          *zap.Logger
+}
+
+// NewLocationHandler creates a new LocationHandler.
+func NewLocationHandler(locationService module.WatchedLocationService, logger *zap.Logger) *LocationHandler {
+	return &LocationHandler{
+		locationService: locationService,
+		logger:          logger,
+	}
+}
+
+// ListWatched handles GET /locations/watched requests.
+func (h *LocationHandler) ListWatched(c *gin.Context) {
+	locations, err := h.locationService.ListWatchedLocations(c.Request.Context())
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": locations})
+}
+
+// GetWatched handles GET /locations/watched/:id requests.
+func (h *LocationHandler) GetWatched(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	location, err := h.locationService.GetWatchedLocation(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, location)
+}
+
+// CreateWatched handles POST /locations/watched requests.
+func (h *LocationHandler) CreateWatched(c *gin.Context) {
+	in, ok := watchedInput(c, h.logger)
+	if !ok {
+		return
+	}
+
+	location, err := h.locationService.CreateWatchedLocation(c.Request.Context(), in)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusCreated, location)
+}
+
+// UpdateWatched handles PUT /locations/watched/:id requests.
+func (h *LocationHandler) UpdateWatched(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+	in, ok := watchedInput(c, h.logger)
+	if !ok {
+		return
+	}
+
+	location, err := h.locationService.UpdateWatchedLocation(c.Request.Context(), id, in)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, location)
+}
+
+// DeleteWatched handles DELETE /locations/watched/:id requests.
+func (h *LocationHandler) DeleteWatched(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.locationService.DeleteWatchedLocation(c.Request.Context(), id); err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// pathID parses the :id path parameter, answering 400 if it is invalid.
+func pathID(c *gin.Context, logger *zap.Logger) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil || id <= 0 {
+		writeError(c, logger, models.InvalidRequest(fmt.Errorf("invalid id: %q", c.Param("id"))))
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// watchedInput binds and validates the request body, answering 400 if it is
+// invalid.
+func watchedInput(c *gin.Context, logger *zap.Logger) (models.WatchedLocationInput, bool) {
+	var in models.WatchedLocationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		writeError(c, logger, models.InvalidRequest(err))
+		return in, false
+	}
+	if err := in.Validate(); err != nil {
+		writeError(c, logger, models.InvalidRequest(err))
+		return in, false
+	}
+	return in, true
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/apierror"
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var streamClients = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "weather_stream_clients",
+	Help: "Open weather update streams.",
+})
+
+// StreamConfig tunes weather update streams.
+type StreamConfig struct {
+	// Heartbeat is how often a comment is sent on an idle stream so proxies
+	// and clients keep the connection open.
+	Heartbeat time.Duration
+	// Refresh is how often a stream asks the weather service for its
+	// location, so updates arrive even when nothing else fetches it. Fresh
+	// data reaches every stream for the location; cached data is not resent.
+	Refresh time.Duration
+	// Buffer is how many updates a slow client may fall behind by before
+	// missing some.
+	Buffer int
+}
+
+// StreamHandler serves weather updates as Server-Sent Events.
+type StreamHandler struct {
+	weatherService module.WeatherService
+	bus            *events.Bus
+	cfg            StreamConfig
+	logger         *zap.Logger
+}
+
+// NewStreamHandler creates a new StreamHandler.
+func NewStreamHandler(weatherService module.WeatherService, bus *events.Bus, cfg StreamConfig, logger *zap.Logger) *StreamHandler {
+	return &StreamHandler{
+		weatherService: weatherService,
+		bus:            bus,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// Stream handles GET /weather/stream?city= requests.
+//
+// Each update is a "weather" event whose data is a WeatherResponse and whose
+// ID can be sent back as Last-Event-ID, as a header or last_event_id query
+// parameter, to resume after a reconnect. If the missed updates are no longer
+// available the stream starts with the current weather instead, as it does
+// for new connections. That first fetch happens before the stream starts, so
+// a request the weather service rejects, such as one for an unknown city, is
+// answered with an error response; if the service is only unavailable the
+// stream starts anyway and catches up on the next refresh.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	city := strings.TrimSpace(c.Query("city"))
+	if city == "" {
+		writeError(c, h.logger, models.InvalidRequest(errors.New("city is required")))
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var sub *events.Subscription
+	var missed []events.Event
+	complete := false
+	if lastEventID != "" {
+		after, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			writeError(c, h.logger, models.InvalidRequest(errors.New("invalid Last-Event-ID: "+strconv.Quote(lastEventID))))
+			return
+		}
+		sub, missed, complete = h.bus.Resume(h.cfg.Buffer, after)
+	} else {
+		sub = h.bus.Subscribe(h.cfg.Buffer)
+	}
+	defer sub.Close()
+
+	ctx := c.Request.Context()
+	var (
+		currentID      uint64
+		currentWeather models.WeatherResponse
+		haveCurrent    bool
+	)
+	if !complete {
+		// Subscribed first, so updates published while fetching are either
+		// in the snapshot or on sub.C.
+		id, weather, err := h.current(ctx, city)
+		if err != nil {
+			if status, _ := apierror.Response(err); status < http.StatusInternalServerError {
+				writeError(c, h.logger, err)
+				return
+			}
+		} else {
+			currentID, currentWeather, haveCurrent = id, weather, true
+		}
+	}
+
+	streamClients.Inc()
+	defer streamClients.Dec()
+	h.logger.Info("Weather stream opened", zap.String("city", city), zap.String("last_event_id", lastEventID))
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Header("Content-Type", "text/event-stream")
+	c.Status(http.StatusOK)
+	// Ask clients to reconnect after five seconds.
+	c.Writer.WriteString("retry: 5000\n\n")
+
+	var sentID uint64
+	if complete {
+		for _, e := range missed {
+			if matchesCity(e, city) {
+				h.send(c, e.ID, e.Weather)
+			}
+		}
+	} else if haveCurrent {
+		// Updates published while fetching are already in the snapshot.
+		sentID = currentID
+		h.send(c, currentID, currentWeather)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.cfg.Heartbeat)
+	defer heartbeat.Stop()
+	refresh := time.NewTicker(h.cfg.Refresh)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.Info("Weather stream closed", zap.String("city", city))
+			return
+		case e := <-sub.C:
+			if e.ID <= sentID || !matchesCity(e, city) {
+				continue
+			}
+			sentID = e.ID
+			h.send(c, e.ID, e.Weather)
+		case <-heartbeat.C:
+			c.Writer.WriteString(": heartbeat\n\n")
+		case <-refresh.C:
+			// Fresh data is published on the bus and arrives on sub.C.
+			h.current(ctx, city)
+			continue
+		}
+		c.Writer.Flush()
+	}
+}
+
+// current fetches the weather for city. It returns the latest event ID at
+// that point, so events up to it can be skipped.
+func (h *StreamHandler) current(ctx context.Context, city string) (uint64, models.WeatherResponse, error) {
+	rq := models.WeatherRequest{City: city, DateTime: time.Now().Format("2006-01-02")}
+	weather, err := h.weatherService.GetWeather(ctx, rq)
+	if err != nil {
+		if ctx.Err() == nil {
+			h.logger.Warn("Failed to fetch weather for stream", zap.Error(err), zap.String("city", city))
+		}
+		return 0, models.WeatherResponse{}, err
+	}
+	return h.bus.LastID(), weather, nil
+}
+
+func (h *StreamHandler) send(c *gin.Context, id uint64, weather models.WeatherResponse) {
+	c.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(id, 10),
+		Event: "weather",
+		Data:  weather,
+	})
+}
+
+// matchesCity reports whether e is an update for city, as requested or as
+// the provider names it.
+func matchesCity(e events.Event, city string) bool {
+	return strings.EqualFold(e.City, city) || strings.EqualFold(e.Weather.Address, city)
+}
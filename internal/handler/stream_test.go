@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// fakeWeather answers every request with err.
+type fakeWeather struct {
+	module.WeatherService
+	err error
+}
+
+func (f fakeWeather) GetWeather(context.Context, models.WeatherRequest) (models.WeatherResponse, error) {
+	return models.WeatherResponse{}, f.err
+}
+
+func TestStreamFirstFetch(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("geocoding Atlantis: %w", models.ErrLocationNotFound), http.StatusNotFound, models.CodeLocationNotFound},
+		{models.InvalidRequest(errors.New("bad date")), http.StatusBadRequest, models.CodeInvalidRequest},
+		// The stream starts anyway and catches up once the provider is back.
+		{models.ErrUpstreamUnavailable, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		gin.SetMode(gin.TestMode)
+		cfg := StreamConfig{Heartbeat: time.Minute, Refresh: time.Minute, Buffer: 1}
+		h := NewStreamHandler(fakeWeather{err: tt.err}, events.NewBus(1), cfg, zap.NewNop())
+		r := gin.New()
+		r.GET("/v1/weather/stream", h.Stream)
+
+		// Cancelled, so a started stream returns right away.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequestWithContext(ctx, http.MethodGet, "/v1/weather/stream?city=Atlantis", nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%v: status %d, want %d", tt.err, w.Code, tt.status)
+			continue
+		}
+		if tt.code == "" {
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+				t.Errorf("%v: Content-Type %q, want an event stream", tt.err, ct)
+			}
+			continue
+		}
+		var body models.ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != tt.code {
+			t.Errorf("%v: body %s, want code %s", tt.err, w.Body, tt.code)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SubscriptionHandler handles HTTP requests for webhook subscriptions.
+type SubscriptionHandler struct {
+	subscriptionService module.SubscriptionService
+	logger              *zap.Logger
+}
+
+// NewSubscriptionHandler creates a new SubscriptionHandler.
+func NewSubscriptionHandler(subscriptionService module.SubscriptionService, logger *zap.Logger) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+		logger:              logger,
+	}
+}
+
+// CreateSubscription handles POST /subscriptions requests. The response
+// includes the secret used to sign the payloads; it is not shown again.
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	var in models.SubscriptionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		writeError(c, h.logger, models.InvalidRequest(err))
+		return
+	}
+
+	sub, err := h.subscriptionService.CreateSubscription(c.Request.Context(), in)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// GetSubscription handles GET /subscriptions/:id requests.
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.GetSubscription(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubscription handles DELETE /subscriptions/:id requests.
+func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.subscriptionService.DeleteSubscription(c.Request.Context(), id); err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /subscriptions/:id/deliveries requests.
+func (h *SubscriptionHandler) ListDeliveries(c *gin.Context) {
+	id, ok := pathID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.subscriptionService.ListDeliveries(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": deliveries})
+}
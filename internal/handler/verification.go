@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// VerificationHandler handles HTTP requests for forecast verification scores.
+type VerificationHandler struct {
+	verificationService module.VerificationService
+	logger              *zap.Logger
+}
+
+// NewVerificationHandler creates a new VerificationHandler.
+func NewVerificationHandler(verificationService module.VerificationService, logger *zap.Logger) *VerificationHandler {
+	return &VerificationHandler{
+		verificationService: verificationService,
+		logger:              logger,
+	}
+}
+
+// GetVerification handles GET /verification requests.
+//
+// Supported query parameters are provider, field and lead (days, 1-7).
+func (h *VerificationHandler) GetVerification(c *gin.Context) {
+	filter := models.VerificationFilter{
+		Provider: c.Query("provider"),
+		Field:    c.Query("field"),
+	}
+	var err error
+	if lead := c.Query("lead"); lead != "" {
+		if filter.LeadDays, err = strconv.Atoi(lead); err != nil {
+			err = fmt.Errorf("invalid lead: %q", lead)
+		}
+	}
+	if err == nil {
+		err = filter.Validate()
+	}
+	if err != nil {
+		writeError(c, h.logger, models.InvalidRequest(err))
+		return
+	}
+
+	scores, err := h.verificationService.GetVerification(c.Request.Context(), filter)
+	if err != nil {
+		writeError(c, h.logger, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": scores})
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Orion777-cmd/weather-app/internal/live"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// WebSocketHandler serves live weather subscriptions over WebSocket.
+type WebSocketHandler struct {
+	server   *live.Server
+	upgrader websocket.Upgrader
+	logger   *zap.Logger
+}
+
+// NewWebSocketHandler creates a new WebSocketHandler. checkOrigin decides
+// which browser origins may connect; nil allows only the server's own.
+func NewWebSocketHandler(server *live.Server, checkOrigin func(origin string) bool, logger *zap.Logger) *WebSocketHandler {
+	h := &WebSocketHandler{server: server, logger: logger}
+	if checkOrigin != nil {
+		h.upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || checkOrigin(origin)
+		}
+	}
+	return h
+}
+
+// Serve handles GET /ws requests. The protocol is described in package live.
+func (h *WebSocketHandler) Serve(c *gin.Context) {
+	// The upgrader writes the error response itself.
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.Warn("WebSocket upgrade failed", zap.Error(err))
+		return
+	}
+	h.server.Serve(c.Request.Context(), conn)
+}
@@ -0,0 +1,447 @@
+// Package live serves weather updates over WebSocket. A client subscribes to
+// any number of locations, up to a limit, on one connection using the JSON
+// messages in protocol.go, and receives an update whenever fresh data for one
+// of them is published on the event bus. A subscription may also name an
+// alert condition in the filter expression language; forecast days that meet
+// it are pushed as alerts, once per day.
+//
+// Each connection has its own outbox. Updates for a subscription replace any
+// earlier update that has not been written yet, so a slow client gets the
+// latest data rather than a backlog. Replies and alerts are never dropped; a
+// client that lets more than SendBuffer of them pile up is disconnected.
+//
+// A connection fetches the current weather of at most MaxFetches new
+// subscriptions at once; further subscribe messages wait for one to finish.
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/apierror"
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/expr"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	connectionsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_ws_connections",
+		Help: "Open WebSocket connections.",
+	})
+	subscriptionsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_ws_subscriptions",
+		Help: "Location subscriptions across all WebSocket connections.",
+	})
+	conflatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_ws_updates_conflated_total",
+		Help: "Updates replaced by a newer one before they were written.",
+	})
+	slowClientsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_ws_slow_clients_total",
+		Help: "Connections closed because the client did not keep up.",
+	})
+)
+
+// errSlowClient closes a connection whose outbox overflowed.
+var errSlowClient = errors.New("client is not reading fast enough")
+
+// WeatherSource provides the current weather when a location is subscribed.
+type WeatherSource interface {
+	GetWeather(ctx context.Context, rq models.WeatherRequest) (models.WeatherResponse, error)
+}
+
+// Config limits and tunes connections.
+type Config struct {
+	MaxSubscriptions int
+	// MaxFetches is how many subscriptions of one connection may have their
+	// current weather fetched at once.
+	MaxFetches int
+	// SendBuffer is how many replies and alerts may wait to be written.
+	SendBuffer int
+	// EventBuffer is how many bus events a connection may fall behind by.
+	EventBuffer int
+	// MatchRadiusKm is how close an update must be to a coordinate
+	// subscription to count as its location.
+	MatchRadiusKm  float64
+	MaxMessageSize int64
+	PingInterval   time.Duration
+	PongTimeout    time.Duration
+	WriteTimeout   time.Duration
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxSubscriptions, validation.Required, validation.Min(1)),
+		validation.Field(&c.MaxFetches, validation.Required, validation.Min(1)),
+		validation.Field(&c.SendBuffer, validation.Required, validation.Min(1)),
+		validation.Field(&c.EventBuffer, validation.Required, validation.Min(1)),
+		validation.Field(&c.MatchRadiusKm, validation.Min(0.0)),
+		validation.Field(&c.MaxMessageSize, validation.Required, validation.Min(int64(256))),
+		validation.Field(&c.PingInterval, validation.Required),
+		validation.Field(&c.PongTimeout, validation.Required, validation.Min(c.PingInterval)),
+		validation.Field(&c.WriteTimeout, validation.Required),
+	)
+}
+
+// Server runs WebSocket sessions.
+type Server struct {
+	weather WeatherSource
+	bus     *events.Bus
+	cfg     Config
+	log     *zap.Logger
+}
+
+func NewServer(weather WeatherSource, bus *events.Bus, cfg Config, log *zap.Logger) *Server {
+	return &Server{weather: weather, bus: bus, cfg: cfg, log: log}
+}
+
+type subscription struct {
+	id       string
+	location models.LocationQuery
+	alert    *expr.Program
+	// lastEventID is the newest bus event sent for the subscription.
+	lastEventID uint64
+	// alerted holds the forecast dates already alerted on.
+	alerted map[string]bool
+}
+
+type session struct {
+	srv    *Server
+	conn   *websocket.Conn
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	subs   map[string]*subscription
+	nextID int
+	// control holds replies and alerts, updates the latest unwritten update
+	// per subscription and pending the order those updates were queued in.
+	control []ServerMessage
+	updates map[string]ServerMessage
+	pending []string
+	wake    chan struct{}
+	// fetches holds a slot for every current-weather fetch running.
+	fetches chan struct{}
+}
+
+// Serve runs a session on conn until the client disconnects or the session
+// fails, then releases everything it holds.
+func (s *Server) Serve(ctx context.Context, conn *websocket.Conn) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	ss := &session{
+		srv:     s,
+		conn:    conn,
+		ctx:     ctx,
+		cancel:  cancel,
+		subs:    make(map[string]*subscription),
+		updates: make(map[string]ServerMessage),
+		wake:    make(chan struct{}, 1),
+		fetches: make(chan struct{}, s.cfg.MaxFetches),
+	}
+	busSub := s.bus.Subscribe(s.cfg.EventBuffer)
+	connectionsGauge.Inc()
+	s.log.Info("WebSocket connected", zap.String("remote", conn.RemoteAddr().String()))
+
+	ss.wg.Add(2)
+	go ss.writeLoop()
+	go ss.eventLoop(busSub)
+	ss.readLoop()
+
+	cancel(nil)
+	busSub.Close()
+	ss.wg.Wait()
+	conn.Close()
+
+	ss.mu.Lock()
+	subscriptionsGauge.Sub(float64(len(ss.subs)))
+	ss.subs = nil
+	ss.mu.Unlock()
+	connectionsGauge.Dec()
+	s.log.Info("WebSocket disconnected", zap.String("remote", conn.RemoteAddr().String()), zap.NamedError("cause", context.Cause(ctx)))
+}
+
+// readLoop handles client messages until the connection fails or the
+// session ends.
+func (ss *session) readLoop() {
+	cfg := ss.srv.cfg
+	ss.conn.SetReadLimit(cfg.MaxMessageSize)
+	ss.conn.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+	ss.conn.SetPongHandler(func(string) error {
+		return ss.conn.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+	})
+
+	for {
+		kind, data, err := ss.conn.ReadMessage()
+		if err != nil {
+			ss.cancel(err)
+			return
+		}
+		ss.conn.SetReadDeadline(time.Now().Add(cfg.PongTimeout))
+		if kind != websocket.TextMessage {
+			ss.reply(ServerMessage{Type: TypeError, Error: "only text messages are supported"})
+			continue
+		}
+
+		msg, err := decode(data)
+		if err != nil {
+			ss.reply(ServerMessage{Type: TypeError, Error: "invalid message: " + err.Error()})
+			continue
+		}
+		switch msg.Type {
+		case TypeSubscribe:
+			ss.subscribe(msg)
+		case TypeUnsubscribe:
+			ss.unsubscribe(msg)
+		case TypePing:
+			ss.reply(ServerMessage{Type: TypePong, ID: msg.ID})
+		default:
+			ss.reply(ServerMessage{Type: TypeError, ID: msg.ID, Error: "unknown message type " + strconv.Quote(msg.Type)})
+		}
+	}
+}
+
+func (ss *session) subscribe(msg ClientMessage) {
+	if err := msg.Location.Validate(); err != nil {
+		ss.reply(errorMessage(msg.ID, "", models.InvalidRequest(err)))
+		return
+	}
+	sub := &subscription{location: msg.Location, alerted: make(map[string]bool)}
+	if msg.Alert != "" {
+		alert, err := expr.Compile(msg.Alert)
+		if err != nil {
+			ss.reply(errorMessage(msg.ID, "", models.InvalidRequest(fmt.Errorf("invalid alert: %w", err))))
+			return
+		}
+		sub.alert = alert
+	}
+
+	ss.mu.Lock()
+	if len(ss.subs) >= ss.srv.cfg.MaxSubscriptions {
+		ss.mu.Unlock()
+		ss.reply(ServerMessage{Type: TypeError, ID: msg.ID, Error: "at most " + strconv.Itoa(ss.srv.cfg.MaxSubscriptions) + " subscriptions per connection"})
+		return
+	}
+	ss.nextID++
+	sub.id = "s" + strconv.Itoa(ss.nextID)
+	ss.subs[sub.id] = sub
+	ss.mu.Unlock()
+	subscriptionsGauge.Inc()
+
+	ss.reply(ServerMessage{Type: TypeSubscribed, ID: msg.ID, Subscription: sub.id, Location: &sub.location})
+
+	// The current weather is sent right away rather than at the next update.
+	// Waiting for a fetch slot holds up reading, so a client cannot start
+	// fetches faster than they finish by unsubscribing again.
+	select {
+	case ss.fetches <- struct{}{}:
+	case <-ss.ctx.Done():
+		return
+	}
+	ss.wg.Add(1)
+	go func() {
+		defer ss.wg.Done()
+		defer func() { <-ss.fetches }()
+		ss.snapshot(sub)
+	}()
+}
+
+func (ss *session) snapshot(sub *subscription) {
+	ss.mu.Lock()
+	active := ss.subs[sub.id] == sub
+	ss.mu.Unlock()
+	if !active {
+		return
+	}
+	rq := sub.location.Request(time.Now().Format("2006-01-02"))
+	weather, err := ss.srv.weather.GetWeather(ss.ctx, rq)
+	if err != nil {
+		if ss.ctx.Err() == nil {
+			ss.srv.log.Warn("WebSocket weather fetch failed", zap.Error(err), zap.String("subscription", sub.id))
+			ss.reply(errorMessage("", sub.id, err))
+		}
+		return
+	}
+	// Events published while fetching are already in the response.
+	ss.update(sub, ss.srv.bus.LastID(), weather)
+}
+
+func (ss *session) unsubscribe(msg ClientMessage) {
+	ss.mu.Lock()
+	_, ok := ss.subs[msg.Subscription]
+	if ok {
+		delete(ss.subs, msg.Subscription)
+		delete(ss.updates, msg.Subscription)
+	}
+	ss.mu.Unlock()
+
+	if !ok {
+		ss.reply(ServerMessage{Type: TypeError, ID: msg.ID, Subscription: msg.Subscription, Error: "unknown subscription"})
+		return
+	}
+	subscriptionsGauge.Dec()
+	ss.reply(ServerMessage{Type: TypeUnsubscribed, ID: msg.ID, Subscription: msg.Subscription})
+}
+
+// eventLoop queues updates for the bus events that match subscriptions.
+func (ss *session) eventLoop(busSub *events.Subscription) {
+	defer ss.wg.Done()
+	for {
+		select {
+		case <-ss.ctx.Done():
+			return
+		case e, ok := <-busSub.C:
+			if !ok {
+				return
+			}
+			ss.mu.Lock()
+			var matched []*subscription
+			for _, sub := range ss.subs {
+				if sub.location.Matches(e.City, e.Weather, ss.srv.cfg.MatchRadiusKm) {
+					matched = append(matched, sub)
+				}
+			}
+			ss.mu.Unlock()
+			for _, sub := range matched {
+				ss.update(sub, e.ID, e.Weather)
+			}
+		}
+	}
+}
+
+// update queues weather as the latest update for sub, and an alert for any
+// forecast days newly meeting its condition. Updates older than the last one
+// sent are ignored.
+func (ss *session) update(sub *subscription, eventID uint64, weather models.WeatherResponse) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.subs[sub.id] != sub || (eventID != 0 && eventID <= sub.lastEventID) {
+		return
+	}
+	sub.lastEventID = max(sub.lastEventID, eventID)
+
+	if _, queued := ss.updates[sub.id]; queued {
+		conflatedTotal.Inc()
+	} else {
+		ss.pending = append(ss.pending, sub.id)
+	}
+	ss.updates[sub.id] = ServerMessage{Type: TypeUpdate, Subscription: sub.id, Location: &sub.location, EventID: eventID, Weather: &weather}
+
+	if sub.alert != nil && len(weather.Days) > 1 {
+		var days []models.Weather
+		for _, day := range weather.Days[1:] {
+			date, _, _ := strings.Cut(day.Datetime, " ")
+			if sub.alerted[date] || !sub.alert.Match(day) {
+				continue
+			}
+			sub.alerted[date] = true
+			days = append(days, day)
+		}
+		if len(days) > 0 {
+			ss.queueLocked(ServerMessage{Type: TypeAlert, Subscription: sub.id, Location: &sub.location, Alert: sub.alert.String(), Days: days})
+		}
+	}
+	ss.signal()
+}
+
+// errorMessage reports err as the HTTP API would, with the code for its kind
+// and without internal details.
+func errorMessage(id, subscription string, err error) ServerMessage {
+	_, body := apierror.Response(err)
+	return ServerMessage{Type: TypeError, ID: id, Subscription: subscription, Code: body.Code, Error: body.Error, Column: body.Column}
+}
+
+// reply queues a message that must not be dropped.
+func (ss *session) reply(msg ServerMessage) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.queueLocked(msg)
+	ss.signal()
+}
+
+func (ss *session) queueLocked(msg ServerMessage) {
+	if len(ss.control) >= ss.srv.cfg.SendBuffer {
+		if ss.ctx.Err() == nil {
+			slowClientsTotal.Inc()
+		}
+		ss.cancel(errSlowClient)
+		return
+	}
+	ss.control = append(ss.control, msg)
+}
+
+func (ss *session) signal() {
+	select {
+	case ss.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next takes the next message to write, replies and alerts first.
+func (ss *session) next() (ServerMessage, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if len(ss.control) > 0 {
+		msg := ss.control[0]
+		ss.control = ss.control[1:]
+		return msg, true
+	}
+	for len(ss.pending) > 0 {
+		id := ss.pending[0]
+		ss.pending = ss.pending[1:]
+		if msg, ok := ss.updates[id]; ok {
+			delete(ss.updates, id)
+			return msg, true
+		}
+	}
+	return ServerMessage{}, false
+}
+
+// writeLoop writes queued messages and keepalive pings. It is the only
+// writer of data frames on the connection.
+func (ss *session) writeLoop() {
+	defer ss.wg.Done()
+	cfg := ss.srv.cfg
+	ping := time.NewTicker(cfg.PingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ss.ctx.Done():
+			code, text := websocket.CloseNormalClosure, ""
+			if errors.Is(context.Cause(ss.ctx), errSlowClient) {
+				code, text = websocket.ClosePolicyViolation, errSlowClient.Error()
+			}
+			ss.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(cfg.WriteTimeout))
+			return
+		case <-ping.C:
+			if err := ss.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(cfg.WriteTimeout)); err != nil {
+				ss.cancel(err)
+				return
+			}
+		case <-ss.wake:
+			for {
+				msg, ok := ss.next()
+				if !ok {
+					break
+				}
+				ss.conn.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+				if err := ss.conn.WriteJSON(msg); err != nil {
+					ss.cancel(err)
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/gorilla/websocket"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+var testConfig = Config{
+	MaxSubscriptions: 2,
+	MaxFetches:       2,
+	SendBuffer:       16,
+	EventBuffer:      16,
+	MaxMessageSize:   4096,
+	PingInterval:     time.Minute,
+	PongTimeout:      2 * time.Minute,
+	WriteTimeout:     5 * time.Second,
+}
+
+// fakeSource serves one day of weather, does not know Atlantis, fails for Erewhon\n// and,
+// while release is open, holds fetches until it is closed or their context
+// ends.
+type fakeSource struct {
+	release chan struct{}
+
+	mu                  sync.Mutex
+	running, maxRunning int
+	fetches, cancelled  int
+}
+
+func (f *fakeSource) GetWeather(ctx context.Context, rq models.WeatherRequest) (models.WeatherResponse, error) {
+	f.mu.Lock()
+	f.fetches++
+	f.running++
+	f.maxRunning = max(f.maxRunning, f.running)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.running--
+		f.mu.Unlock()
+	}()
+
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			f.mu.Lock()
+			f.cancelled++
+			f.mu.Unlock()
+			return models.WeatherResponse{}, ctx.Err()
+		}
+	}
+	switch rq.City {
+	case "Atlantis":
+		return models.WeatherResponse{}, fmt.Errorf("geocoding %s: %w", rq.City, models.ErrLocationNotFound)
+	case "Erewhon":
+		return models.WeatherResponse{}, errors.New("GET https://provider/?appid=secret: connection reset")
+	}
+	return models.WeatherResponse{Address: rq.City, Days: []models.Weather{{Datetime: "2026-10-19 00:00:00", Temp: 12}}}, nil
+}
+
+// testSession is a session without a connection, for the outbox alone.
+func testSession(cfg Config) *session {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	return &session{
+		srv:     &Server{cfg: cfg, log: zap.NewNop()},
+		ctx:     ctx,
+		cancel:  cancel,
+		subs:    make(map[string]*subscription),
+		updates: make(map[string]ServerMessage),
+		wake:    make(chan struct{}, 1),
+		fetches: make(chan struct{}, cfg.MaxFetches),
+	}
+}
+
+func TestUpdatesAreConflated(t *testing.T) {
+	ss := testSession(testConfig)
+	berlin := &subscription{id: "s1", location: models.LocationQuery{City: "Berlin"}, alerted: map[string]bool{}}
+	paris := &subscription{id: "s2", location: models.LocationQuery{City: "Paris"}, alerted: map[string]bool{}}
+	ss.subs[berlin.id], ss.subs[paris.id] = berlin, paris
+
+	// A client that is not reading gets the latest update per subscription,
+	// in the order they were first queued, after any replies.
+	for id := uint64(1); id <= 3; id++ {
+		ss.update(berlin, id, models.WeatherResponse{Address: "Berlin"})
+	}
+	ss.update(paris, 4, models.WeatherResponse{Address: "Paris"})
+	ss.update(berlin, 2, models.WeatherResponse{Address: "Berlin"})
+	ss.reply(ServerMessage{Type: TypePong})
+
+	var got []string
+	for {
+		msg, ok := ss.next()
+		if !ok {
+			break
+		}
+		got = append(got, fmt.Sprintf("%s:%s:%d", msg.Type, msg.Subscription, msg.EventID))
+	}
+	want := []string{"pong::0", "update:s1:3", "update:s2:4"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("written %v, want %v", got, want)
+	}
+}
+
+func TestSlowClientIsDisconnected(t *testing.T) {
+	cfg := testConfig
+	cfg.SendBuffer = 2
+	ss := testSession(cfg)
+
+	// Replies are never dropped: overflowing them ends the session.
+	for range cfg.SendBuffer {
+		ss.reply(ServerMessage{Type: TypePong})
+	}
+	if ss.ctx.Err() != nil {
+		t.Fatal("session ended within its send buffer")
+	}
+	ss.reply(ServerMessage{Type: TypePong})
+	if cause := context.Cause(ss.ctx); !errors.Is(cause, errSlowClient) {
+		t.Errorf("session ended with %v, want errSlowClient", cause)
+	}
+}
+
+// client connects to a test server running srv. done is closed once the
+// session has ended and released everything; the test waits for that before
+// it finishes.
+func client(t *testing.T, srv *Server) (conn *websocket.Conn, done <-chan struct{}) {
+	t.Helper()
+	ended := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		srv.Serve(context.Background(), conn)
+		close(ended)
+	}))
+	t.Cleanup(ts.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		select {
+		case <-ended:
+		case <-time.After(5 * time.Second):
+			t.Error("session not released after the client disconnected")
+		}
+	})
+	return conn, ended
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg ClientMessage) {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// await reads messages until one matches.
+func await(t *testing.T, conn *websocket.Conn, match func(ServerMessage) bool) ServerMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var msg ServerMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("reading: %v", err)
+		}
+		if match(msg) {
+			return msg
+		}
+	}
+}
+
+func reply(id string) func(ServerMessage) bool {
+	return func(msg ServerMessage) bool { return msg.ID == id }
+}
+
+func TestSubscriptionLimit(t *testing.T) {
+	conn, _ := client(t, NewServer(&fakeSource{}, events.NewBus(0), testConfig, zap.NewNop()))
+
+	send(t, conn, ClientMessage{Type: TypeSubscribe, ID: "1", Location: models.LocationQuery{City: "Berlin"}})
+	first := await(t, conn, reply("1"))
+	send(t, conn, ClientMessage{Type: TypeSubscribe, ID: "2", Location: models.LocationQuery{City: "Paris"}})
+	await(t, conn, reply("2"))
+	send(t, conn, ClientMessage{Type: TypeSubscribe, ID: "3", Location: models.LocationQuery{City: "Rome"}})
+	if msg := await(t, conn, reply("3")); msg.Type != TypeError || !strings.Contains(msg.Error, "at most 2") {
+		t.Errorf("third subscription: %+v, want the limit error", msg)
+	}
+
+	// Unsubscribing makes room.
+	send(t, conn, ClientMessage{Type: TypeUnsubscribe, ID: "4", Subscription: first.Subscription})
+	await(t, conn, reply("4"))
+	send(t, conn, ClientMessage{Type: TypeSubscribe, ID: "5", Location: models.LocationQuery{City: "Rome"}})
+	if msg := await(t, conn, reply("5")); msg.Type != TypeSubscribed {
+		t.Errorf("after unsubscribing: %+v, want subscribed", msg)
+	}
+}
+
+func TestFetchErrorsAreMapped(t *testing.T) {
+	conn, _ := client(t, NewServer(&fakeSource{}, events.NewBus(0), testConfig, zap.NewNop()))
+
+	for _, tt := range []struct {
+		city, code, error string
+	}{
+		{"Atlantis", models.CodeLocationNotFound, "geocoding Atlantis: location not found"},
+		{"Erewhon", models.CodeInternal, "internal error"},
+	} {
+		send(t, conn, ClientMessage{Type: TypeSubscribe, ID: tt.city, Location: models.LocationQuery{City: tt.city}})
+		sub := await(t, conn, reply(tt.city)).Subscription
+		msg := await(t, conn, func(msg ServerMessage) bool { return msg.Type == TypeError && msg.Subscription == sub })
+		if msg.Code != tt.code || msg.Error != tt.error {
+			t.Errorf("%s: code %q, error %q, want %q, %q", tt.city, msg.Code, msg.Error, tt.code, tt.error)
+		}
+		send(t, conn, ClientMessage{Type: TypeUnsubscribe, ID: "u" + tt.city, Subscription: sub})
+		await(t, conn, reply("u"+tt.city))
+	}
+
+	send(t, conn, ClientMessage{Type: TypeSubscribe, ID: "alert", Location: models.LocationQuery{City: "Berlin"}, Alert: "temp >"})
+	if msg := await(t, conn, reply("alert")); msg.Code != models.CodeInvalidRequest || msg.Column == 0 {
+		t.Errorf("invalid alert: %+v, want %s with a column", msg, models.CodeInvalidRequest)
+	}
+}
+
+func TestFetchesAreBounded(t *testing.T) {
+	source := &fakeSource{release: make(chan struct{})}
+	cfg := testConfig
+	cfg.MaxSubscriptions = 4
+	conn, _ := client(t, NewServer(source, events.NewBus(0), cfg, zap.NewNop()))
+
+	cities := []string{"Berlin", "Paris", "Rome", "Madrid"}
+	for i, city := range cities {
+		send(t, conn, ClientMessage{Type: TypeSubscribe, ID: fmt.Sprint(i), Location: models.LocationQuery{City: city}})
+	}
+	send(t, conn, ClientMessage{Type: TypePing, ID: "ping"})
+	// The third subscription is confirmed, then waits for a fetch slot.
+	await(t, conn, reply("2"))
+	time.Sleep(100 * time.Millisecond)
+	source.mu.Lock()
+	fetches := source.fetches
+	source.mu.Unlock()
+	if fetches != cfg.MaxFetches {
+		t.Errorf("%d fetches started, want the limit of %d", fetches, cfg.MaxFetches)
+	}
+
+	// Once they finish, the rest are fetched and reading resumes.
+	close(source.release)
+	updates, ponged := 0, false
+	await(t, conn, func(msg ServerMessage) bool {
+		switch msg.Type {
+		case TypeUpdate:
+			updates++
+		case TypePong:
+			ponged = true
+		}
+		return ponged && updates == len(cities)
+	})
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if source.maxRunning > cfg.MaxFetches || source.fetches != len(cities) {
+		t.Errorf("%d fetches with up to %d at once, want %d with at most %d", source.fetches, source.maxRunning, len(cities), cfg.MaxFetches)
+	}
+}
+
+func TestDisconnectReleasesSession(t *testing.T) {
+	gauge := func() float64 {
+		var m dto.Metric
+		if err := subscriptionsGauge.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetGauge().GetValue()
+	}
+	before := gauge()
+
+	source := &fakeSource{release: make(chan struct{})}
+	defer close(source.release)
+	conn, done := client(t, NewServer(source, events.NewBus(0), testConfig, zap.NewNop()))
+	for _, id := range []string{"1", "2"} {
+		send(t, conn, ClientMessage{Type: TypeSubscribe, ID: id, Location: models.LocationQuery{City: "Berlin"}})
+		await(t, conn, reply(id))
+	}
+	if got := gauge(); got != before+2 {
+		t.Fatalf("%v subscriptions counted, want %v", got, before+2)
+	}
+
+	// Both fetches are still running when the client goes away.
+	conn.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("session not released after the client disconnected")
+	}
+	source.mu.Lock()
+	cancelled, running := source.cancelled, source.running
+	source.mu.Unlock()
+	if cancelled != 2 || running != 0 {
+		t.Errorf("%d fetches cancelled and %d still running, want 2 and 0", cancelled, running)
+	}
+	if got := gauge(); got != before {
+		t.Errorf("%v subscriptions counted after disconnect, want %v", got, before)
+	}
+}
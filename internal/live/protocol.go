@@ -0,0 +1,64 @@
+package live
+
+import (
+	"encoding/json"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// Client message types.
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypePing        = "ping"
+)
+
+// Server message types.
+const (
+	TypeSubscribed   = "subscribed"
+	TypeUnsubscribed = "unsubscribed"
+	TypeUpdate       = "update"
+	TypeAlert        = "alert"
+	TypePong         = "pong"
+	TypeError        = "error"
+)
+
+// ClientMessage is a message from the client. ID is echoed in the reply so
+// clients can match replies to requests.
+//
+//	{"type": "subscribe", "id": "1", "location": {"city": "Berlin"}, "alert": "precip > 10"}
+//	{"type": "unsubscribe", "id": "2", "subscription": "s1"}
+//	{"type": "ping", "id": "3"}
+type ClientMessage struct {
+	Type         string               `json:"type"`
+	ID           string               `json:"id,omitempty"`
+	Location     models.LocationQuery `json:"location"`
+	Alert        string               `json:"alert,omitempty"`
+	Subscription string               `json:"subscription,omitempty"`
+}
+
+// ServerMessage is a message to the client. Updates carry the latest weather
+// for a subscription; alerts carry the forecast days that newly meet its
+// alert condition.
+type ServerMessage struct {
+	Type         string                  `json:"type"`
+	ID           string                  `json:"id,omitempty"`
+	Subscription string                  `json:"subscription,omitempty"`
+	Location     *models.LocationQuery   `json:"location,omitempty"`
+	EventID      uint64                  `json:"event_id,omitempty"`
+	Weather      *models.WeatherResponse `json:"weather,omitempty"`
+	Alert        string                  `json:"alert,omitempty"`
+	Days         []models.Weather        `json:"days,omitempty"`
+	// Code classifies an error as in the HTTP API's error bodies, for
+	// errors that have one.
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Column locates an error in an alert condition.
+	Column int `json:"column,omitempty"`
+}
+
+func decode(data []byte) (ClientMessage, error) {
+	var msg ClientMessage
+	err := json.Unmarshal(data, &msg)
+	return msg, err
+}
@@ -0,0 +1,80 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Orion777-cmd/weather-app/internal/apierror"
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// GetBatch gets the weather for every location of in, at most concurrency at
+// a time and repeated locations once, returning a result per location in the
+// order given. A failed location carries the status and error a single
+// request would have been answered with. Once ctx is done, locations not yet
+// fetched fail with its error.
+func (s *serviceModule) GetBatch(ctx context.Context, in models.BatchRequest, concurrency int) []models.BatchResult {
+	results := make([]models.BatchResult, len(in.Locations))
+	// Indexes of the locations that share a request, by request.
+	same := make(map[string][]int)
+	var order []string
+	for i, l := range in.Locations {
+		results[i].Location = l
+		if err := l.Validate(); err != nil {
+			setResult(&results[i], models.WeatherResponse{}, models.InvalidRequest(err))
+			continue
+		}
+		key := batchKey(l)
+		if _, ok := same[key]; !ok {
+			order = append(order, key)
+		}
+		same[key] = append(same[key], i)
+	}
+
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for _, key := range order {
+		indexes := same[key]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var weather models.WeatherResponse
+			var err error
+			select {
+			case sem <- struct{}{}:
+				rq := in.Locations[indexes[0]].Request(in.Datetime)
+				rq.Filter = in.Filter
+				weather, err = s.GetWeather(ctx, rq)
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			for _, i := range indexes {
+				setResult(&results[i], weather, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// setResult records the outcome of fetching the weather for r's location.
+func setResult(r *models.BatchResult, weather models.WeatherResponse, err error) {
+	if err != nil {
+		status, body := apierror.Response(err)
+		r.Status, r.Error = status, &body
+		return
+	}
+	r.Status, r.Weather = http.StatusOK, &weather
+}
+
+// batchKey identifies the request for l, so repeated locations share it.
+func batchKey(l models.LocationQuery) string {
+	if l.City != "" {
+		return "city:" + strings.ToLower(strings.TrimSpace(l.City))
+	}
+	return fmt.Sprintf("coordinate:%g,%g", l.Coordinate.Latitude, l.Coordinate.Longitude)
+}
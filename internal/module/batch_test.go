@@ -0,0 +1,131 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+// batchProvider counts calls per city and knows every city but Atlantis. If
+// block is set, calls wait for their context to end.
+type batchProvider struct {
+	block bool
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *batchProvider) Geocode(_ context.Context, city string) (models.Place, error) {
+	if strings.EqualFold(city, "Atlantis") {
+		return models.Place{}, fmt.Errorf("%w: %q", models.ErrLocationNotFound, city)
+	}
+	return models.Place{Name: city}, nil
+}
+
+func (p *batchProvider) GetWeather(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error {
+	p.mu.Lock()
+	p.calls[strings.ToLower(rq.City)]++
+	p.mu.Unlock()
+	if p.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if _, err := p.Geocode(ctx, rq.City); err != nil {
+		return err
+	}
+	response.Address = rq.City
+	response.Days = []models.Weather{{Datetime: "2026-10-19 00:00:00", Temp: 12}}
+	return nil
+}
+
+func TestGetBatch(t *testing.T) {
+	provider := &batchProvider{calls: map[string]int{}}
+	s := NewService(provider, repository.NewMemoryRepository(0), nil, events.NewBus(0), Config{}, zap.NewNop())
+	in := models.BatchRequest{
+		Datetime: "2026-10-19",
+		Locations: []models.LocationQuery{
+			{City: "Berlin"},
+			{City: "Atlantis"},
+			{City: "Paris"},
+			{},
+			{City: " berlin"},
+			{Coordinate: &models.Location{Latitude: 48.85, Longitude: 2.35}},
+		},
+	}
+
+	results := s.GetBatch(context.Background(), in, 2)
+	if len(results) != len(in.Locations) {
+		t.Fatalf("%d results for %d locations", len(results), len(in.Locations))
+	}
+	want := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusOK, ""},
+		{http.StatusNotFound, models.CodeLocationNotFound},
+		{http.StatusOK, ""},
+		{http.StatusBadRequest, models.CodeInvalidRequest},
+		{http.StatusOK, ""},
+		{http.StatusOK, ""},
+	}
+	for i, r := range results {
+		if r.Location != in.Locations[i] {
+			t.Errorf("result %d is for %+v, want %+v", i, r.Location, in.Locations[i])
+		}
+		code := ""
+		if r.Error != nil {
+			code = r.Error.Code
+		}
+		if r.Status != want[i].status || code != want[i].code {
+			t.Errorf("result %d: %d %q, want %d %q", i, r.Status, code, want[i].status, want[i].code)
+		}
+		if (r.Weather != nil) != (r.Status == http.StatusOK) {
+			t.Errorf("result %d: status %d with weather %v", i, r.Status, r.Weather)
+		}
+	}
+	if results[0].Weather.Address != "Berlin" || results[2].Weather.Address != "Paris" {
+		t.Errorf("results out of order: %s, %s", results[0].Weather.Address, results[2].Weather.Address)
+	}
+	if n := provider.calls["berlin"]; n != 1 {
+		t.Errorf("Berlin, listed twice, fetched %d times, want once", n)
+	}
+}
+
+func TestGetBatchCancelled(t *testing.T) {
+	provider := &batchProvider{block: true, calls: map[string]int{}}
+	s := NewService(provider, repository.NewMemoryRepository(0), nil, events.NewBus(0), Config{}, zap.NewNop())
+	in := models.BatchRequest{Datetime: "2026-10-19"}
+	for _, city := range []string{"Berlin", "Paris", "Rome", "Oslo"} {
+		in.Locations = append(in.Locations, models.LocationQuery{City: city})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	done := make(chan []models.BatchResult)
+	go func() { done <- s.GetBatch(ctx, in, 1) }()
+
+	select {
+	case results := <-done:
+		for i, r := range results {
+			if r.Error == nil || r.Weather != nil {
+				t.Errorf("result %d succeeded after cancellation: %+v", i, r)
+			}
+		}
+		// Only the location holding the single slot reached the provider.
+		provider.mu.Lock()
+		defer provider.mu.Unlock()
+		if len(provider.calls) != 1 {
+			t.Errorf("provider called for %v, want one location", provider.calls)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetBatch did not return after cancellation")
+	}
+}
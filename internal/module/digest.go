@@ -0,0 +1,139 @@
+package module
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/digest"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+type digestModule struct {
+	log    *zap.Logger
+	repo   *repository.WeatherRepository
+	sender *digest.Sender
+}
+
+// NewDigestService manages email digest subscriptions. Subscriptions are kept
+// in Postgres.
+func NewDigestService(repo *repository.WeatherRepository, sender *digest.Sender, log *zap.Logger) DigestService {
+	return &digestModule{log: log, repo: repo, sender: sender}
+}
+
+// CreateDigest stores an unconfirmed digest and emails the link that confirms
+// it to the address.
+func (s *digestModule) CreateDigest(ctx context.Context, in models.DigestInput) (models.DigestSubscription, error) {
+	if err := in.Validate(); err != nil {
+		s.log.Warn(err.Error(), zap.Any("digest", in))
+		return models.DigestSubscription{}, models.InvalidRequest(err)
+	}
+
+	next, err := in.Next(time.Now())
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	token, err := newToken()
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	confirmToken, err := newToken()
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	sub, err := s.repo.CreateDigest(ctx, in, token, confirmToken, next)
+	if err != nil {
+		s.log.Error("Failed to create digest subscription", zap.Error(err))
+		return models.DigestSubscription{}, err
+	}
+	// A digest nobody can confirm would only sit in the table, so it is
+	// removed again if the confirmation email cannot be sent.
+	if err := s.sender.SendConfirmation(ctx, sub, confirmToken); err != nil {
+		s.log.Error("Failed to send digest confirmation", zap.Error(err), zap.Int32("id", sub.ID))
+		if err := s.repo.DeleteDigest(context.WithoutCancel(ctx), sub.ID); err != nil {
+			s.log.Error("Failed to delete unconfirmable digest subscription", zap.Error(err), zap.Int32("id", sub.ID))
+		}
+		return models.DigestSubscription{}, fmt.Errorf("sending confirmation email: %w", err)
+	}
+	s.log.Info("Digest subscription created", zap.Int32("id", sub.ID), zap.Time("next_send_at", sub.NextSendAt))
+	return sub, nil
+}
+
+// GetDigest returns a digest subscription without its unsubscribe token,
+// which is only given out when the subscription is created.
+func (s *digestModule) GetDigest(ctx context.Context, id int32) (models.DigestSubscription, error) {
+	sub, err := s.repo.GetDigest(ctx, id)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	sub.UnsubscribeToken = ""
+	return sub, nil
+}
+
+func (s *digestModule) DeleteDigest(ctx context.Context, id int32) error {
+	if err := s.repo.DeleteDigest(ctx, id); err != nil {
+		return err
+	}
+	s.log.Info("Digest subscription deleted", zap.Int32("id", id))
+	return nil
+}
+
+// ListDigestDeliveries returns the latest deliveries of a digest.
+func (s *digestModule) ListDigestDeliveries(ctx context.Context, id int32) ([]models.DigestDelivery, error) {
+	if _, err := s.repo.GetDigest(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDigestDeliveries(ctx, id, deliveryListLimit)
+}
+
+func (s *digestModule) SendDigest(ctx context.Context, id int32) error {
+	sub, err := s.repo.GetDigest(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sub.UnsubscribedAt != nil {
+		return models.ErrDigestUnsubscribed
+	}
+	if sub.ConfirmedAt == nil {
+		return models.ErrDigestNotConfirmed
+	}
+	if err := s.sender.Send(ctx, sub); err != nil {
+		s.log.Error("Failed to send digest", zap.Error(err), zap.Int32("id", id))
+		return err
+	}
+	return nil
+}
+
+// Confirm confirms the address of the digest with the given confirmation
+// token. Confirming twice is harmless.
+func (s *digestModule) Confirm(ctx context.Context, token string) (models.DigestSubscription, error) {
+	sub, err := s.repo.ConfirmDigest(ctx, token)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	s.log.Info("Digest confirmed", zap.Int32("id", sub.ID))
+	sub.UnsubscribeToken = ""
+	return sub, nil
+}
+
+func (s *digestModule) Unsubscribe(ctx context.Context, token string) (models.DigestSubscription, error) {
+	sub, err := s.repo.UnsubscribeDigest(ctx, token)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	s.log.Info("Digest unsubscribed", zap.Int32("id", sub.ID))
+	return sub, nil
+}
+
+// newToken returns a random unsubscribe or confirmation token.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -5,6 +5,53 @@ import (
 	"github.com/Orion777-cmd/weather-app/internal/constants/models"
 )
 
+// WeatherService serves weather, from the provider or storage, and the stored
+// query history.
 type WeatherService interface {
 	GetWeather(ctx context.Context, rq models.WeatherRequest) (models.WeatherResponse, error)
-}
\ No newline at end of file
+	// GetBatch gets the weather for many locations, at most concurrency at
+	// a time, with a result per location in order.
+	GetBatch(ctx context.Context, in models.BatchRequest, concurrency int) []models.BatchResult
+	GetHistory(ctx context.Context, filter models.HistoryFilter) (models.HistoryPage, error)
+	GetStats(ctx context.Context) (models.StorageStats, error)
+}
+
+// WatchedLocationService manages the locations whose forecasts are prefetched
+// and verified in the background.
+type WatchedLocationService interface {
+	ListWatchedLocations(ctx context.Context) ([]models.WatchedLocation, error)
+	GetWatchedLocation(ctx context.Context, id int32) (models.WatchedLocation, error)
+	CreateWatchedLocation(ctx context.Context, in models.WatchedLocationInput) (models.WatchedLocation, error)
+	UpdateWatchedLocation(ctx context.Context, id int32, in models.WatchedLocationInput) (models.WatchedLocation, error)
+	DeleteWatchedLocation(ctx context.Context, id int32) error
+}
+
+// VerificationService reports how accurate past forecasts turned out to be.
+type VerificationService interface {
+	GetVerification(ctx context.Context, filter models.VerificationFilter) ([]models.VerificationScore, error)
+}
+
+// SubscriptionService manages webhook subscriptions to weather conditions and
+// lists their delivery attempts.
+type SubscriptionService interface {
+	CreateSubscription(ctx context.Context, in models.SubscriptionInput) (models.Subscription, error)
+	GetSubscription(ctx context.Context, id int32) (models.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int32) error
+	ListDeliveries(ctx context.Context, id int32) ([]models.WebhookDelivery, error)
+}
+
+// DigestService manages scheduled email digests of the weather.
+type DigestService interface {
+	CreateDigest(ctx context.Context, in models.DigestInput) (models.DigestSubscription, error)
+	GetDigest(ctx context.Context, id int32) (models.DigestSubscription, error)
+	DeleteDigest(ctx context.Context, id int32) error
+	ListDigestDeliveries(ctx context.Context, id int32) ([]models.DigestDelivery, error)
+	// SendDigest sends a digest now, outside its schedule and without
+	// recording it, to try out the mail setup. The address must have been
+	// confirmed.
+	SendDigest(ctx context.Context, id int32) error
+	// Confirm confirms a digest's address with the token from the
+	// confirmation email, after which it is sent on schedule.
+	Confirm(ctx context.Context, token string) (models.DigestSubscription, error)
+	Unsubscribe(ctx context.Context, token string) (models.DigestSubscription, error)
+}
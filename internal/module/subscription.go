@@ -0,0 +1,81 @@
+package module
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/internal/expr"
+	"go.uber.org/zap"
+)
+
+// deliveryListLimit is how many recent deliveries ListDeliveries returns.
+const deliveryListLimit = 50
+
+type subscriptionModule struct {
+	log  *zap.Logger
+	repo *repository.WeatherRepository
+}
+
+// NewSubscriptionService manages webhook subscriptions. Subscriptions are
+// kept in Postgres.
+func NewSubscriptionService(repo *repository.WeatherRepository, log *zap.Logger) SubscriptionService {
+	return &subscriptionModule{log: log, repo: repo}
+}
+
+func (s *subscriptionModule) CreateSubscription(ctx context.Context, in models.SubscriptionInput) (models.Subscription, error) {
+	if err := in.Validate(); err != nil {
+		s.log.Warn(err.Error(), zap.Any("subscription", in))
+		return models.Subscription{}, models.InvalidRequest(err)
+	}
+	condition, err := expr.Compile(in.Condition)
+	if err != nil {
+		s.log.Warn(err.Error(), zap.Any("subscription", in))
+		return models.Subscription{}, models.InvalidRequest(fmt.Errorf("%w: %w", models.ErrInvalidCondition, err))
+	}
+	in.Condition = condition.String()
+
+	secret, err := newSecret()
+	if err != nil {
+		return models.Subscription{}, err
+	}
+	sub, err := s.repo.CreateSubscription(ctx, in, secret)
+	if err != nil {
+		s.log.Error("Failed to create subscription", zap.Error(err), zap.Any("subscription", in))
+		return models.Subscription{}, err
+	}
+	s.log.Info("Subscription created", zap.Int32("id", sub.ID))
+	return sub, nil
+}
+
+func (s *subscriptionModule) GetSubscription(ctx context.Context, id int32) (models.Subscription, error) {
+	return s.repo.GetSubscription(ctx, id)
+}
+
+func (s *subscriptionModule) DeleteSubscription(ctx context.Context, id int32) error {
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		return err
+	}
+	s.log.Info("Subscription deleted", zap.Int32("id", id))
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries for a subscription.
+func (s *subscriptionModule) ListDeliveries(ctx context.Context, id int32) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, id, deliveryListLimit)
+}
+
+// newSecret returns a random key for signing a subscription's payloads.
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,34 @@
+package module
+
+import (
+	"context"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+type verificationModule struct {
+	log  *zap.Logger
+	repo *repository.WeatherRepository
+}
+
+// NewVerificationService serves the forecast scores computed by the
+// verification worker.
+func NewVerificationService(repo *repository.WeatherRepository, log *zap.Logger) VerificationService {
+	return &verificationModule{log: log, repo: repo}
+}
+
+func (s *verificationModule) GetVerification(ctx context.Context, filter models.VerificationFilter) ([]models.VerificationScore, error) {
+	if err := filter.Validate(); err != nil {
+		s.log.Warn(err.Error(), zap.Any("filter", filter))
+		return nil, err
+	}
+
+	scores, err := s.repo.GetVerification(ctx, filter)
+	if err != nil {
+		s.log.Error("Failed to load verification scores", zap.Error(err), zap.Any("filter", filter))
+		return nil, err
+	}
+	return scores, nil
+}
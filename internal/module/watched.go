@@ -0,0 +1,64 @@
+package module
+
+import (
+	"context"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+type watchedModule struct {
+	log  *zap.Logger
+	repo *repository.WeatherRepository
+}
+
+// NewWatchedLocationService manages the locations prefetched in the
+// background. Watched locations are kept in Postgres.
+func NewWatchedLocationService(repo *repository.WeatherRepository, log *zap.Logger) WatchedLocationService {
+	return &watchedModule{log: log, repo: repo}
+}
+
+func (s *watchedModule) ListWatchedLocations(ctx context.Context) ([]models.WatchedLocation, error) {
+	locations, err := s.repo.ListWatchedLocations(ctx)
+	if err != nil {
+		s.log.Error("Failed to list watched locations", zap.Error(err))
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (s *watchedModule) GetWatchedLocation(ctx context.Context, id int32) (models.WatchedLocation, error) {
+	return s.repo.GetWatchedLocation(ctx, id)
+}
+
+func (s *watchedModule) CreateWatchedLocation(ctx context.Context, in models.WatchedLocationInput) (models.WatchedLocation, error) {
+	if err := in.Validate(); err != nil {
+		s.log.Warn(err.Error(), zap.Any("location", in))
+		return models.WatchedLocation{}, err
+	}
+
+	location, err := s.repo.CreateWatchedLocation(ctx, in)
+	if err != nil {
+		s.log.Error("Failed to create watched location", zap.Error(err), zap.Any("location", in))
+		return models.WatchedLocation{}, err
+	}
+	s.log.Info("Watched location created", zap.Int32("id", location.ID))
+	return location, nil
+}
+
+func (s *watchedModule) UpdateWatchedLocation(ctx context.Context, id int32, in models.WatchedLocationInput) (models.WatchedLocation, error) {
+	if err := in.Validate(); err != nil {
+		s.log.Warn(err.Error(), zap.Any("location", in))
+		return models.WatchedLocation{}, err
+	}
+	return s.repo.UpdateWatchedLocation(ctx, id, in)
+}
+
+func (s *watchedModule) DeleteWatchedLocation(ctx context.Context, id int32) error {
+	if err := s.repo.DeleteWatchedLocation(ctx, id); err != nil {
+		return err
+	}
+	s.log.Info("Watched location deleted", zap.Int32("id", id))
+	return nil
+}
@@ -2,41 +2,279 @@ package module
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/expr"
 	"github.com/Orion777-cmd/weather-app/platform"
 	"github.com/Orion777-cmd/weather-app/internal/repository"
 	"go.uber.org/zap"
 )
 
+// Config tunes the weather service.
+type Config struct {
+	// CacheTTL is how old a stored response may be and still be served
+	// instead of calling the provider. Zero disables the lookup.
+	CacheTTL time.Duration
+	// MaxAge is how old a stored response may be and still be served, marked
+	// stale, while a background refresh replaces it. Below CacheTTL it has
+	// no effect.
+	MaxAge time.Duration
+	// MaxStaleAge is how old a stored response may be and still be served,
+	// marked stale, when the provider fails. Zero disables the fallback.
+	MaxStaleAge time.Duration
+}
+
+// AnomalyDetector scores a response against its location's history.
+type AnomalyDetector interface {
+	Detect(ctx context.Context, weather models.WeatherResponse) (*models.Anomalies, error)
+}
+
 type serviceModule struct {
     log        *zap.Logger
     weatherAPI platform.WeatherAPI
-	repo       *repository.WeatherRepository
+	repo       repository.Store
+	anomalies  AnomalyDetector
+	bus        *events.Bus
+	cfg        Config
+
+	// refreshMu guards refreshing, which holds for each location being
+	// refreshed in the background the earliest time another refresh may
+	// start.
+	refreshMu  sync.Mutex
+	refreshing map[string]time.Time
 }
 
-func NewService(weatherAPI platform.WeatherAPI, repo *repository.WeatherRepository, log *zap.Logger) WeatherService {
+const (
+	// refreshRetryInterval is how long stale weather is served before
+	// another background refresh after one fails, so an outage is not
+	// hammered once per request.
+	refreshRetryInterval = 30 * time.Second
+	// refreshTimeout bounds a background refresh, which no request waits on.
+	refreshTimeout = 30 * time.Second
+)
+
+// NewService builds the weather service. anomalies may be nil, in which case
+// responses are not annotated. Fresh provider data is published on bus.
+func NewService(weatherAPI platform.WeatherAPI, repo repository.Store, anomalies AnomalyDetector, bus *events.Bus, cfg Config, log *zap.Logger) WeatherService {
     return &serviceModule{
         log:        log,
         weatherAPI: weatherAPI,
 		repo: 	    repo,
+		anomalies:  anomalies,
+		bus:        bus,
+		cfg:        cfg,
+		refreshing: make(map[string]time.Time),
     }
 }
 
 func (s *serviceModule) GetWeather(ctx context.Context, rq models.WeatherRequest) (models.WeatherResponse, error) {
     if err := rq.Validate(); err != nil {
         s.log.Warn(err.Error(), zap.Any("request", rq))
-        return models.WeatherResponse{}, err
+        return models.WeatherResponse{}, models.InvalidRequest(err)
+    }
+    var filter *expr.Program
+    if rq.Filter != "" {
+        var err error
+        if filter, err = expr.Compile(rq.Filter); err != nil {
+            s.log.Warn("Invalid filter", zap.Error(err), zap.Any("request", rq))
+            return models.WeatherResponse{}, models.InvalidRequest(fmt.Errorf("%w: %w", models.ErrInvalidFilter, err))
+        }
+    }
+
+    // Cached and stored weather is kept under the best match, so ambiguity
+    // is checked on the geocoding result up front. Geocoding failures are
+    // left to the fetch, which may still serve stored weather.
+    if rq.RejectAmbiguous && rq.City != "" {
+        if place, err := s.weatherAPI.Geocode(ctx, rq.City); err == nil {
+            if err := place.Ambiguous(rq.City); err != nil {
+                s.log.Info("Ambiguous city", zap.String("city", rq.City), zap.Int("places", len(place.Candidates)))
+                return models.WeatherResponse{}, err
+            }
+        }
     }
 
-    var weatherResponse models.WeatherResponse
-    if err := s.weatherAPI.GetWeather(ctx, rq, &weatherResponse); err != nil {
+    weather, err := s.getWeather(ctx, rq)
+    if err != nil {
         return models.WeatherResponse{}, err
     }
 
-	if err := s.repo.SaveWeatherQuery(ctx, rq.City, weatherResponse); err != nil {
-        s.log.Error("Failed to save weather query", zap.Error(err))
+    if s.anomalies != nil {
+        anomalies, err := s.anomalies.Detect(ctx, weather)
+        if err != nil {
+            s.log.Warn("Failed to detect anomalies", zap.Error(err), zap.Any("request", rq))
+        }
+        weather.Anomalies = anomalies
+    }
+    if filter != nil {
+        weather.Days = filter.FilterDays(weather.Days)
     }
+    return weather, nil
+}
+
+// getWeather serves a validated request from the in-process cache, storage or
+// the provider, cheapest first. Cached or stored weather past its TTL is
+// served stale while a background refresh replaces it.
+func (s *serviceModule) getWeather(ctx context.Context, rq models.WeatherRequest) (models.WeatherResponse, error) {
+	if cache, ok := s.weatherAPI.(platform.CachedWeatherAPI); ok {
+		var cached models.WeatherResponse
+		if cache.Cached(ctx, rq, &cached) {
+			cached.Age = int64(time.Since(cached.FetchedAt).Seconds())
+			if cached.Stale {
+				s.revalidate(ctx, rq)
+			}
+			return cached, nil
+		}
+	}
+	window := s.cfg.CacheTTL
+	if window > 0 && s.cfg.MaxAge > window {
+		window = s.cfg.MaxAge
+	}
+	if stored, ok := s.storedWeather(ctx, rq, window); ok {
+		if time.Since(stored.FetchedAt) >= s.cfg.CacheTTL {
+			stored.Stale = true
+			s.revalidate(ctx, rq)
+		}
+		return stored, nil
+	}
+
+	weather, err := s.fetch(ctx, rq)
+	if err != nil {
+		if stale, ok := s.storedWeather(ctx, rq, s.cfg.MaxStaleAge); ok {
+			s.log.Warn("Provider failed, serving stale weather", zap.Error(err), zap.Any("request", rq), zap.Int64("age", stale.Age))
+			stale.Stale = true
+			return stale, nil
+		}
+		return models.WeatherResponse{}, err
+	}
+	weather.Age = int64(time.Since(weather.FetchedAt).Seconds())
+	return weather, nil
+}
 
-    return weatherResponse, nil
-}
\ No newline at end of file
+// fetch gets the weather from the provider, saving and publishing it unless
+// the provider's own cache served it.
+func (s *serviceModule) fetch(ctx context.Context, rq models.WeatherRequest) (models.WeatherResponse, error) {
+	var weather models.WeatherResponse
+	if err := s.weatherAPI.GetWeather(ctx, rq, &weather); err != nil {
+		return models.WeatherResponse{}, err
+	}
+	if weather.FetchedAt.IsZero() {
+		weather.FetchedAt = time.Now().UTC()
+	}
+
+	// The provider may be fronted by an in-process cache; only fresh data is
+	// worth storing.
+	if weather.Origin != models.OriginCache {
+		if err := s.repo.SaveWeatherQuery(ctx, rq.City, weather); err != nil {
+			s.log.Error("Failed to save weather query", zap.Error(err))
+		}
+		weather.Origin = models.OriginProvider
+		s.bus.Publish(events.Event{City: rq.City, Weather: weather})
+	}
+	return weather, nil
+}
+
+// revalidate refreshes the weather for rq in the background through fetch, so
+// the refreshed data is stored and published like any other. One refresh runs
+// per location at a time, and after a failure the next waits for
+// refreshRetryInterval.
+func (s *serviceModule) revalidate(ctx context.Context, rq models.WeatherRequest) {
+	key := strings.ToLower(strings.TrimSpace(rq.City))
+	if key == "" {
+		key = fmt.Sprintf("%.4f,%.4f", repository.RoundCoordinate(rq.Coordinate.Latitude), repository.RoundCoordinate(rq.Coordinate.Longitude))
+	}
+	now := time.Now()
+	s.refreshMu.Lock()
+	if now.Before(s.refreshing[key]) {
+		s.refreshMu.Unlock()
+		return
+	}
+	// A refresh in flight ends within refreshTimeout.
+	s.refreshing[key] = now.Add(refreshTimeout)
+	s.refreshMu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), refreshTimeout)
+		defer cancel()
+		_, err := s.fetch(ctx, rq)
+
+		s.refreshMu.Lock()
+		defer s.refreshMu.Unlock()
+		if err == nil {
+			delete(s.refreshing, key)
+			return
+		}
+		s.log.Warn("Background weather refresh failed", zap.Error(err), zap.Any("request", rq))
+		s.refreshing[key] = time.Now().Add(refreshRetryInterval)
+		for k, retryAt := range s.refreshing {
+			if time.Now().After(retryAt) {
+				delete(s.refreshing, k)
+			}
+		}
+	}()
+}
+
+// storedWeather looks for a stored response for the requested location that
+// is newer than maxAge. City requests are matched on the stored query history,
+// coordinate requests on the latest snapshot for the location.
+func (s *serviceModule) storedWeather(ctx context.Context, rq models.WeatherRequest, maxAge time.Duration) (models.WeatherResponse, bool) {
+	if maxAge <= 0 {
+		return models.WeatherResponse{}, false
+	}
+
+	since := time.Now().Add(-maxAge)
+	var weather models.WeatherResponse
+	var fetchedAt time.Time
+	var err error
+	if rq.City != "" {
+		weather, fetchedAt, err = s.repo.GetRecentWeather(ctx, rq.City, since)
+	} else {
+		weather, fetchedAt, err = s.repo.GetLatestWeather(ctx, rq.Coordinate)
+	}
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.log.Warn("Failed to read cached weather", zap.Error(err), zap.Any("request", rq))
+		}
+		return models.WeatherResponse{}, false
+	}
+	if fetchedAt.Before(since) {
+		return models.WeatherResponse{}, false
+	}
+
+	weather.FetchedAt = fetchedAt
+	weather.Origin = models.OriginCache
+	weather.Age = int64(time.Since(fetchedAt).Seconds())
+	return weather, true
+}
+
+func (s *serviceModule) GetHistory(ctx context.Context, filter models.HistoryFilter) (models.HistoryPage, error) {
+	if err := filter.Validate(); err != nil {
+		s.log.Warn(err.Error(), zap.Any("filter", filter))
+		return models.HistoryPage{}, models.InvalidRequest(err)
+	}
+
+	page, err := s.repo.GetHistory(ctx, filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return models.HistoryPage{}, models.InvalidRequest(err)
+		}
+		s.log.Error("Failed to load weather history", zap.Error(err), zap.Any("filter", filter))
+		return models.HistoryPage{}, fmt.Errorf("%w: %w", models.ErrStorage, err)
+	}
+
+	return page, nil
+}
+
+func (s *serviceModule) GetStats(ctx context.Context) (models.StorageStats, error) {
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		s.log.Error("Failed to load storage stats", zap.Error(err))
+		return models.StorageStats{}, fmt.Errorf("%w: %w", models.ErrStorage, err)
+	}
+	return stats, nil
+}
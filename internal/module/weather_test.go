@@ -0,0 +1,114 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+// paris geocodes like the provider does for a name several cities share.
+var paris = models.Place{
+	Name:       "Paris",
+	Country:    "FR",
+	Coordinate: models.Location{Latitude: 48.8589, Longitude: 2.32},
+	Candidates: []models.Place{
+		{Name: "Paris", Country: "FR", Coordinate: models.Location{Latitude: 48.8589, Longitude: 2.32}},
+		{Name: "Paris", State: "Texas", Country: "US", Coordinate: models.Location{Latitude: 33.6609, Longitude: -95.5555}},
+	},
+}
+
+// fakeProvider serves one day of weather for the place it geocodes to.
+type fakeProvider struct {
+	place models.Place
+}
+
+func (p fakeProvider) Geocode(context.Context, string) (models.Place, error) {
+	return p.place, nil
+}
+
+func (p fakeProvider) GetWeather(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error {
+	coordinate := rq.Coordinate
+	if rq.City != "" {
+		place, err := p.Geocode(ctx, rq.City)
+		if err != nil {
+			return err
+		}
+		coordinate = place.Coordinate
+		response.Address = place.Name
+	}
+	response.Latitude = coordinate.Latitude
+	response.Longitude = coordinate.Longitude
+	response.Days = []models.Weather{{Datetime: "2026-10-19 00:00:00", Temp: 12}}
+	return nil
+}
+
+func TestGetWeatherAmbiguousCity(t *testing.T) {
+	s := NewService(fakeProvider{place: paris}, repository.NewMemoryRepository(10), nil, events.NewBus(1), Config{CacheTTL: time.Hour}, zap.NewNop())
+	ctx := context.Background()
+	rq := models.WeatherRequest{City: "Paris", DateTime: "2026-10-19"}
+
+	// Legacy and v1 callers, and background jobs, get the best match.
+	weather, err := s.GetWeather(ctx, rq)
+	if err != nil {
+		t.Fatalf("best match: %v", err)
+	}
+	if weather.Latitude != paris.Coordinate.Latitude {
+		t.Errorf("best match at %v, want %v", weather.Latitude, paris.Coordinate.Latitude)
+	}
+
+	// v2 lists the candidates, even though the best match is now stored.
+	rq.RejectAmbiguous = true
+	_, err = s.GetWeather(ctx, rq)
+	var ambiguous *models.AmbiguousLocationError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+		t.Fatalf("rejecting ambiguity: got %v, want the two candidates", err)
+	}
+
+	// A qualified name is not ambiguous.
+	rq.City = "Paris,FR"
+	if _, err := s.GetWeather(ctx, rq); err != nil {
+		t.Errorf("qualified name: %v", err)
+	}
+}
+
+func TestStaleStoredWeatherIsRefreshed(t *testing.T) {
+	repo := repository.NewMemoryRepository(10)
+	bus := events.NewBus(1)
+	updates := bus.Subscribe(1)
+	s := NewService(fakeProvider{place: paris}, repo, nil, bus, Config{CacheTTL: time.Nanosecond, MaxAge: time.Hour}, zap.NewNop())
+	ctx := context.Background()
+
+	old := models.WeatherResponse{Address: "Paris", Days: []models.Weather{{Datetime: "2026-10-19 00:00:00", Temp: 8}}}
+	if err := repo.SaveWeatherQuery(ctx, "Paris", old); err != nil {
+		t.Fatal(err)
+	}
+
+	// Past the TTL but within MaxAge, the stored weather is served stale...
+	weather, err := s.GetWeather(ctx, models.WeatherRequest{City: "Paris", DateTime: "2026-10-19"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !weather.Stale || weather.Days[0].Temp != 8 {
+		t.Errorf("served temp %v, stale %v, want the stored 8, stale", weather.Days[0].Temp, weather.Stale)
+	}
+
+	// ...and refreshed in the background, stored and published like a miss.
+	select {
+	case e := <-updates.C:
+		if e.Weather.Days[0].Temp != 12 {
+			t.Errorf("published temp %v, want the refreshed 12", e.Weather.Days[0].Temp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the refresh was not published")
+	}
+	stored, _, err := repo.GetRecentWeather(ctx, "Paris", time.Now().Add(-time.Minute))
+	if err != nil || stored.Days[0].Temp != 12 {
+		t.Errorf("stored %v (%v), want the refreshed weather", stored.Days, err)
+	}
+}
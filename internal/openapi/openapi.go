@@ -0,0 +1,205 @@
+// Package openapi holds the OpenAPI description of the HTTP API in
+// openapi.yaml, validates requests against it and provides the pages that
+// serve it.
+//
+// The docs page uses Swagger UI, which is served from this binary rather than
+// a CDN. The files in swagger-ui/ are generated by swaggerui_gen.go at the
+// version pinned in swagger-ui/VERSION.
+package openapi
+
+//go:generate go run swaggerui_gen.go
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+//go:embed docs.html
+var docs []byte
+
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+// docsMissing replaces the docs page in builds without the Swagger UI files.
+var docsMissing = []byte(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Weather API</title></head>
+<body>
+<p>The interactive documentation is not part of this build; run go generate ./internal/openapi to include it.
+The description is at <a href="/openapi.json">/openapi.json</a>.</p>
+</body>
+</html>
+`)
+
+// Spec is the loaded API description.
+type Spec struct {
+	doc    *openapi3.T
+	json   []byte
+	router routers.Router
+}
+
+// Load parses and checks the embedded description.
+func Load() (*Spec, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	addLegacyPaths(doc)
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Spec{doc: doc, json: data, router: router}, nil
+}
+
+// addLegacyPaths describes each /v1 path again without the prefix, as it is
+// still routed for clients from before the API was versioned.
+func addLegacyPaths(doc *openapi3.T) {
+	var paths []string
+	for path := range doc.Paths.Map() {
+		if strings.HasPrefix(path, "/v1/") {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range paths {
+		item := doc.Paths.Value(path)
+		legacy := &openapi3.PathItem{Parameters: item.Parameters}
+		for method, op := range item.Operations() {
+			deprecated := *op
+			deprecated.OperationID = "legacy" + strings.ToUpper(op.OperationID[:1]) + op.OperationID[1:]
+			deprecated.Description = strings.TrimSpace("Deprecated: use " + path + ". " + op.Description)
+			deprecated.Deprecated = true
+			legacy.SetOperation(method, &deprecated)
+		}
+		doc.Paths.Set(strings.TrimPrefix(path, "/v1"), legacy)
+	}
+}
+
+// JSON returns the description as JSON.
+func (s *Spec) JSON() []byte {
+	return s.json
+}
+
+// Docs returns an HTML page that shows the description served at
+// /openapi.json and lets readers try out requests.
+func Docs() []byte {
+	if _, err := fs.Stat(swaggerUI, "swagger-ui/swagger-ui-bundle.js"); err != nil {
+		return docsMissing
+	}
+	return docs
+}
+
+// DocsAsset returns the named Swagger UI file used by the docs page.
+func DocsAsset(name string) ([]byte, bool) {
+	if !fs.ValidPath(name) || strings.Contains(name, "/") {
+		return nil, false
+	}
+	data, err := swaggerUI.ReadFile("swagger-ui/" + name)
+	return data, err == nil
+}
+
+// Operations lists the described operations as "METHOD /path", with path
+// parameters in braces, sorted.
+func (s *Spec) Operations() []string {
+	var ops []string
+	for path, item := range s.doc.Paths.Map() {
+		for method := range item.Operations() {
+			ops = append(ops, method+" "+path)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// Validator answers 400 to requests whose parameters or body do not match
+// their operation. Requests for routes that are not described are passed on.
+func (s *Spec) Validator(logger *zap.Logger) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		SkipSettingDefaults: true,
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	}
+	return func(c *gin.Context) {
+		route, params, err := s.router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		err = openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route:      route,
+			Options:    options,
+		})
+		if err != nil {
+			message := validationMessage(err)
+			logger.Info("Invalid request", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path), zap.String("error", message))
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{
+				Code:      models.CodeInvalidRequest,
+				Error:     message,
+				RequestID: c.Writer.Header().Get(models.RequestIDHeader),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// validationMessage describes a validation error in one line, leaving out
+// the schema dump kin-openapi includes.
+func validationMessage(err error) string {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return err.Error()
+	}
+
+	where := "request body"
+	if p := requestErr.Parameter; p != nil {
+		where = fmt.Sprintf("%s parameter %q", p.In, p.Name)
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		// Errors under allOf and the like are reported by the outer schema;
+		// the innermost one says what is wrong.
+		field := schemaErr.JSONPointer()
+		var inner *openapi3.SchemaError
+		for errors.As(schemaErr.Origin, &inner) {
+			schemaErr = inner
+			field = append(field, inner.JSONPointer()...)
+		}
+		if requestErr.Parameter == nil && len(field) > 0 {
+			where += " field " + strings.Join(field, ".")
+		}
+		return fmt.Sprintf("invalid %s: %s", where, schemaErr.Reason)
+	}
+	reason := requestErr.Reason
+	if reason == "" && requestErr.Err != nil {
+		reason = requestErr.Err.Error()
+	}
+	return fmt.Sprintf("invalid %s: %s", where, reason)
+}
@@ -0,0 +1,25 @@
+package openapi
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDocsAsset(t *testing.T) {
+	if _, ok := DocsAsset("VERSION"); !ok {
+		t.Error("VERSION not found")
+	}
+	for _, name := range []string{"../openapi.yaml", "/VERSION", "", ".", "missing.js"} {
+		if _, ok := DocsAsset(name); ok {
+			t.Errorf("%q was served", name)
+		}
+	}
+
+	// The page never loads Swagger UI from elsewhere.
+	if bytes.Contains(Docs(), []byte("https://")) {
+		t.Errorf("docs page loads remote files:\n%s", Docs())
+	}
+	if _, ok := DocsAsset("swagger-ui-bundle.js"); !ok && !bytes.Equal(Docs(), docsMissing) {
+		t.Error("docs page served without its Swagger UI files")
+	}
+}
@@ -0,0 +1,117 @@
+//go:build ignore
+
+// This program downloads the Swagger UI assets served by the docs page into
+// swagger-ui/. The version is pinned in swagger-ui/VERSION, and the package
+// is checked against the integrity hash the npm registry publishes for it.
+// Run it with go generate after changing the version and commit the result.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const dir = "swagger-ui"
+
+// assets are the files of the swagger-ui-dist package the docs page uses.
+var assets = []string{"swagger-ui.css", "swagger-ui-bundle.js", "LICENSE"}
+
+var client = &http.Client{Timeout: time.Minute}
+
+func main() {
+	version, err := os.ReadFile(filepath.Join(dir, "VERSION"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := fetch(strings.TrimSpace(string(version))); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func fetch(version string) error {
+	var meta struct {
+		Dist struct {
+			Tarball   string `json:"tarball"`
+			Integrity string `json:"integrity"`
+		} `json:"dist"`
+	}
+	body, err := get("https://registry.npmjs.org/swagger-ui-dist/" + version)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return err
+	}
+	want, ok := strings.CutPrefix(meta.Dist.Integrity, "sha512-")
+	if !ok {
+		return fmt.Errorf("swagger-ui-dist %s: unexpected integrity %q", version, meta.Dist.Integrity)
+	}
+
+	tarball, err := get(meta.Dist.Tarball)
+	if err != nil {
+		return err
+	}
+	sum := sha512.Sum512(tarball)
+	if got := base64.StdEncoding.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("swagger-ui-dist %s: integrity mismatch: got sha512-%s, want sha512-%s", version, got, want)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return err
+	}
+	files := tar.NewReader(gz)
+	found := 0
+	for {
+		h, err := files.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name, ok := strings.CutPrefix(h.Name, "package/")
+		if !ok || !slices.Contains(assets, name) {
+			continue
+		}
+		data, err := io.ReadAll(files)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+		found++
+	}
+	if found != len(assets) {
+		return fmt.Errorf("swagger-ui-dist %s: found %d of the files %v", version, found, assets)
+	}
+	log.Printf("swagger-ui-dist %s written to %s", version, dir)
+	return nil
+}
+
+func get(url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,240 @@
+// Package partition maintains the monthly range partitions of
+// weather_query_history: it creates partitions ahead of time and detaches and
+// drops partitions that have aged out.
+package partition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+const (
+	parentTable = "weather_query_history"
+	// Partition names are parentTable_pYYYY_MM, as created by migration 4.
+	namePrefix = parentTable + "_p"
+	nameLayout = "2006_01"
+	// defaultPartition, from migration 11, holds rows no monthly partition
+	// covers.
+	defaultPartition = parentTable + "_default"
+)
+
+var (
+	partitionsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_history_partitions_created_total",
+		Help: "History partitions created by the partition manager.",
+	})
+	partitionsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_history_partitions_dropped_total",
+		Help: "History partitions detached and dropped by the partition manager.",
+	})
+	partitionCount = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_history_partitions",
+		Help: "History partitions present after the last maintenance run.",
+	})
+)
+
+// Config controls how far ahead partitions are created and how long they are
+// kept.
+type Config struct {
+	Interval      time.Duration
+	PremakeMonths int
+	// RetainMonths is how many whole months before the current one are kept.
+	// Zero keeps every partition.
+	RetainMonths int
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Interval, validation.Required),
+		validation.Field(&c.PremakeMonths, validation.Required, validation.Min(1)),
+		validation.Field(&c.RetainMonths, validation.Min(0)),
+	)
+}
+
+// Manager runs partition maintenance against the database.
+type Manager struct {
+	pool *pgxpool.Pool
+	cfg  Config
+	log  *zap.Logger
+}
+
+func NewManager(pool *pgxpool.Pool, cfg Config, log *zap.Logger) *Manager {
+	return &Manager{pool: pool, cfg: cfg, log: log}
+}
+
+// Run performs maintenance immediately and then on every interval until ctx
+// is cancelled.
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.log.Error("Partition maintenance failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce ensures partitions exist from the current month through
+// PremakeMonths ahead, then drops partitions older than RetainMonths.
+func (m *Manager) RunOnce(ctx context.Context) error {
+	now := time.Now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	existing, err := m.partitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i <= m.cfg.PremakeMonths; i++ {
+		start := current.AddDate(0, i, 0)
+		if _, ok := existing[start]; ok {
+			continue
+		}
+		if err := m.create(ctx, start); err != nil {
+			return err
+		}
+		existing[start] = partitionName(start)
+	}
+
+	if m.cfg.RetainMonths > 0 {
+		oldest := current.AddDate(0, -m.cfg.RetainMonths, 0)
+		for start, name := range existing {
+			if !start.Before(oldest) {
+				continue
+			}
+			if err := m.drop(ctx, name); err != nil {
+				return err
+			}
+			delete(existing, start)
+		}
+	}
+
+	partitionCount.Set(float64(len(existing)))
+	return nil
+}
+
+// partitions returns the managed partitions keyed by the month they start.
+// Partitions that do not follow the naming scheme are ignored.
+func (m *Manager) partitions(ctx context.Context) (map[time.Time]string, error) {
+	rows, err := m.pool.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = $1::regclass`, parentTable)
+	if err != nil {
+		return nil, err
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := make(map[time.Time]string, len(names))
+	for _, name := range names {
+		suffix, ok := strings.CutPrefix(name, namePrefix)
+		if !ok {
+			continue
+		}
+		start, err := time.Parse(nameLayout, suffix)
+		if err != nil {
+			continue
+		}
+		partitions[start] = name
+	}
+	return partitions, nil
+}
+
+// create adds the partition for the month starting at start. Rows of that
+// month already in the default partition, written while no partition covered
+// it, are moved into the new partition first: Postgres refuses to attach a
+// range the default partition has rows for.
+func (m *Manager) create(ctx context.Context, start time.Time) error {
+	name := partitionName(start)
+	end := start.AddDate(0, 1, 0)
+	ident := pgx.Identifier{name}.Sanitize()
+	bounds := fmt.Sprintf(`FOR VALUES FROM ('%s') TO ('%s')`, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var stray bool
+	err = tx.QueryRow(ctx, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE query_time >= $1 AND query_time < $2)`, defaultPartition),
+		start, end).Scan(&stray)
+	if err != nil {
+		return fmt.Errorf("create partition %s: %w", name, err)
+	}
+	statements := []string{fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s %s`, ident, parentTable, bounds)}
+	if stray {
+		statements = []string{
+			fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, ident, parentTable),
+			fmt.Sprintf(`WITH moved AS (DELETE FROM %s WHERE query_time >= '%s' AND query_time < '%s' RETURNING *) INSERT INTO %s SELECT * FROM moved`,
+				defaultPartition, start.Format(time.RFC3339), end.Format(time.RFC3339), ident),
+			fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s %s`, parentTable, ident, bounds),
+		}
+	}
+	for _, sql := range statements {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("create partition %s: %w", name, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("create partition %s: %w", name, err)
+	}
+	partitionsCreated.Inc()
+	m.log.Info("Created history partition", zap.String("partition", name), zap.Bool("moved_from_default", stray))
+	return nil
+}
+
+// drop detaches a partition without blocking writers on the parent table and
+// then drops it. A concurrent detach waits for running transactions, so it
+// runs on a dedicated connection without the pool's statement timeout.
+func (m *Manager) drop(ctx context.Context, name string) (err error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SET statement_timeout = 0`); err != nil {
+		return err
+	}
+	defer func() {
+		if _, resetErr := conn.Exec(context.Background(), `RESET statement_timeout`); resetErr != nil {
+			err = errors.Join(err, resetErr)
+		}
+	}()
+
+	ident := pgx.Identifier{name}.Sanitize()
+	if _, err := conn.Exec(ctx, fmt.Sprintf(`ALTER TABLE %s DETACH PARTITION %s CONCURRENTLY`, parentTable, ident)); err != nil {
+		return fmt.Errorf("detach partition %s: %w", name, err)
+	}
+	if _, err := conn.Exec(ctx, fmt.Sprintf(`DROP TABLE %s`, ident)); err != nil {
+		return fmt.Errorf("drop partition %s: %w", name, err)
+	}
+	partitionsDropped.Inc()
+	m.log.Info("Dropped history partition", zap.String("partition", name))
+	return nil
+}
+
+func partitionName(start time.Time) string {
+	return namePrefix + start.Format(nameLayout)
+}
@@ -0,0 +1,169 @@
+// Package prefetch keeps the stored weather for watched locations fresh. A
+// scheduler claims the locations that are due, fetches them from the provider
+// with bounded concurrency and stores the results, then schedules each
+// location's next fetch after its own interval plus some jitter so fetches do
+// not bunch up.
+package prefetch
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"github.com/Orion777-cmd/weather-app/platform"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	fetchesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_prefetch_fetches_total",
+		Help: "Prefetches of watched locations by result.",
+	}, []string{"result"})
+	fetchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "weather_prefetch_fetch_duration_seconds",
+		Help:    "Duration of a single watched location prefetch.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+	})
+)
+
+// Config controls how often due locations are polled and how many are fetched
+// at once.
+type Config struct {
+	// Interval is how often the scheduler looks for due locations. Each
+	// location's own interval is stored with it.
+	Interval    time.Duration
+	Concurrency int
+	BatchSize   int
+	// Timeout bounds a single fetch. Claimed locations are leased for a
+	// little longer, so a crashed scheduler's claims are retried.
+	Timeout time.Duration
+	// Jitter spreads each location's next fetch by up to this fraction of its
+	// interval in either direction.
+	Jitter float64
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Interval, validation.Required),
+		validation.Field(&c.Concurrency, validation.Required, validation.Min(1)),
+		validation.Field(&c.BatchSize, validation.Required, validation.Min(1)),
+		validation.Field(&c.Timeout, validation.Required),
+		validation.Field(&c.Jitter, validation.Min(0.0), validation.Max(0.5)),
+	)
+}
+
+// Scheduler prefetches weather for watched locations.
+type Scheduler struct {
+	repo *repository.WeatherRepository
+	api  platform.WeatherAPI
+	bus  *events.Bus
+	cfg  Config
+	log  *zap.Logger
+}
+
+// NewScheduler returns a scheduler that fetches from api, stores in repo and
+// publishes every stored update on bus. api should be the provider itself
+// rather than a cache in front of it.
+func NewScheduler(repo *repository.WeatherRepository, api platform.WeatherAPI, bus *events.Bus, cfg Config, log *zap.Logger) *Scheduler {
+	return &Scheduler{repo: repo, api: api, bus: bus, cfg: cfg, log: log}
+}
+
+// Run polls for due locations immediately and then on every interval until
+// ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error("Prefetch run failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce fetches every location that is currently due, a batch at a time.
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	lease := s.cfg.Timeout + s.cfg.Interval
+	for {
+		due, err := s.repo.ClaimDueWatchedLocations(ctx, s.cfg.BatchSize, lease)
+		if err != nil {
+			return err
+		}
+
+		sem := make(chan struct{}, s.cfg.Concurrency)
+		var wg sync.WaitGroup
+		for _, location := range due {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				s.prefetch(ctx, location)
+			}()
+		}
+		wg.Wait()
+
+		if len(due) < s.cfg.BatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// prefetch fetches and stores one location and records when it is next due.
+func (s *Scheduler) prefetch(ctx context.Context, location models.WatchedLocation) {
+	start := time.Now()
+	err := s.fetch(ctx, location)
+	fetchDuration.Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		fetchesTotal.WithLabelValues("error").Inc()
+		s.log.Warn("Prefetch failed", zap.Error(err), zap.Int32("location_id", location.ID))
+	} else {
+		fetchesTotal.WithLabelValues("success").Inc()
+	}
+
+	if err := s.repo.RecordWatchedFetch(context.WithoutCancel(ctx), location.ID, err, s.nextDelay(location)); err != nil {
+		s.log.Error("Failed to record prefetch", zap.Error(err), zap.Int32("location_id", location.ID))
+	}
+}
+
+func (s *Scheduler) fetch(ctx context.Context, location models.WatchedLocation) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	rq := location.Request(time.Now().Format("2006-01-02"))
+	var weather models.WeatherResponse
+	if err := s.api.GetWeather(ctx, rq, &weather); err != nil {
+		return err
+	}
+	if weather.FetchedAt.IsZero() {
+		weather.FetchedAt = time.Now().UTC()
+	}
+	if err := s.repo.SaveWeatherQuery(ctx, location.City, weather); err != nil {
+		return err
+	}
+	s.bus.Publish(events.Event{City: location.City, Weather: weather})
+	return nil
+}
+
+// nextDelay is the location's interval, moved by a random amount of up to
+// Jitter times the interval either way.
+func (s *Scheduler) nextDelay(location models.WatchedLocation) time.Duration {
+	interval := location.Interval()
+	if s.cfg.Jitter == 0 {
+		return interval
+	}
+	spread := (rand.Float64()*2 - 1) * s.cfg.Jitter
+	return interval + time.Duration(spread*float64(interval))
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/db"
+)
+
+// GetBaseline summarizes the current conditions stored before at within
+// radiusKm of coordinate, on days of the year within windowDays of at.
+func (r *WeatherRepository) GetBaseline(ctx context.Context, at time.Time, coordinate models.Location, radiusKm float64, windowDays int) (models.Baseline, error) {
+	row, err := r.q.GetWeatherBaseline(ctx, db.GetWeatherBaselineParams{
+		At:         timestamptz(at),
+		Latitude:   coordinate.Latitude,
+		Longitude:  coordinate.Longitude,
+		RadiusKm:   radiusKm,
+		WindowDays: int32(windowDays),
+	})
+	if err != nil {
+		return models.Baseline{}, err
+	}
+	return models.Baseline{
+		Samples:         int(row.Samples),
+		TempMean:        row.TempMean,
+		TempStddev:      row.TempStddev,
+		PrecipMean:      row.PrecipMean,
+		PrecipStddev:    row.PrecipStddev,
+		WindspeedMean:   row.WindspeedMean,
+		WindspeedStddev: row.WindspeedStddev,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// encodeCursor builds the opaque pagination token for the (query_time, id)
+// position of the last row on a page.
+func encodeCursor(queryTime time.Time, id int32) string {
+	raw := strconv.FormatInt(queryTime.UnixMicro(), 10) + ":" + strconv.FormatInt(int64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor reverses encodeCursor. An empty cursor yields NULL parameters,
+// which start from the newest row.
+func decodeCursor(cursor string) (pgtype.Timestamptz, pgtype.Int4, error) {
+	if cursor == "" {
+		return pgtype.Timestamptz{}, pgtype.Int4{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.Int4{}, models.ErrInvalidCursor
+	}
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return pgtype.Timestamptz{}, pgtype.Int4{}, models.ErrInvalidCursor
+	}
+	ts, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.Int4{}, models.ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return pgtype.Timestamptz{}, pgtype.Int4{}, models.ErrInvalidCursor
+	}
+
+	return pgtype.Timestamptz{Time: time.UnixMicro(ts), Valid: true}, pgtype.Int4{Int32: int32(n), Valid: true}, nil
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateDigest stores an unconfirmed digest subscription, first due at
+// nextSendAt once confirmed with confirmToken. The unsubscribe token is
+// returned in the result.
+func (r *WeatherRepository) CreateDigest(ctx context.Context, in models.DigestInput, token, confirmToken string, nextSendAt time.Time) (models.DigestSubscription, error) {
+	locations, err := json.Marshal(in.Locations)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	clock, err := time.Parse(models.SendTimeLayout, in.SendTime)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+
+	params := db.CreateDigestSubscriptionParams{
+		Email:            in.Email,
+		Locations:        locations,
+		Frequency:        in.Frequency,
+		SendTime:         pgtype.Time{Microseconds: int64(clock.Hour()*60+clock.Minute()) * int64(time.Minute/time.Microsecond), Valid: true},
+		Timezone:         in.Timezone,
+		UnsubscribeToken: token,
+		ConfirmToken:     pgtype.Text{String: confirmToken, Valid: true},
+		NextSendAt:       timestamptz(nextSendAt),
+	}
+	if day, weekly := in.Day(); weekly {
+		params.Weekday = pgtype.Int2{Int16: int16(day), Valid: true}
+	}
+	row, err := r.q.CreateDigestSubscription(ctx, params)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	digest, err := digestSubscription(row)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	digest.UnsubscribeToken = row.UnsubscribeToken
+	return digest, nil
+}
+
+// GetDigest returns models.ErrDigestNotFound if there is no digest
+// subscription with the given ID. The unsubscribe token is included.
+func (r *WeatherRepository) GetDigest(ctx context.Context, id int32) (models.DigestSubscription, error) {
+	row, err := r.q.GetDigestSubscription(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DigestSubscription{}, models.ErrDigestNotFound
+	}
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	digest, err := digestSubscription(row)
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	digest.UnsubscribeToken = row.UnsubscribeToken
+	return digest, nil
+}
+
+// DeleteDigest removes a digest subscription and its delivery history. It
+// returns models.ErrDigestNotFound if there is no digest subscription with the
+// given ID.
+func (r *WeatherRepository) DeleteDigest(ctx context.Context, id int32) error {
+	n, err := r.q.DeleteDigestSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrDigestNotFound
+	}
+	return nil
+}
+
+// UnsubscribeDigest stops the digest with the given unsubscribe token. It
+// returns models.ErrDigestNotFound if no digest has the token.
+func (r *WeatherRepository) UnsubscribeDigest(ctx context.Context, token string) (models.DigestSubscription, error) {
+	row, err := r.q.UnsubscribeDigest(ctx, token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DigestSubscription{}, models.ErrDigestNotFound
+	}
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	return digestSubscription(row)
+}
+
+// ConfirmDigest confirms the digest with the given confirmation token. It
+// returns models.ErrDigestNotFound if no digest has the token.
+func (r *WeatherRepository) ConfirmDigest(ctx context.Context, token string) (models.DigestSubscription, error) {
+	row, err := r.q.ConfirmDigest(ctx, pgtype.Text{String: token, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DigestSubscription{}, models.ErrDigestNotFound
+	}
+	if err != nil {
+		return models.DigestSubscription{}, err
+	}
+	return digestSubscription(row)
+}
+
+// ClaimDueDigests returns up to batchSize digests that are due and holds them
+// for lease, after which they are due again unless recorded. The unsubscribe
+// tokens are included.
+func (r *WeatherRepository) ClaimDueDigests(ctx context.Context, batchSize int, lease time.Duration) ([]models.DigestSubscription, error) {
+	rows, err := r.q.ClaimDueDigests(ctx, db.ClaimDueDigestsParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    int32(batchSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make([]models.DigestSubscription, 0, len(rows))
+	for _, row := range rows {
+		digest, err := digestSubscription(row)
+		if err != nil {
+			return nil, err
+		}
+		digest.UnsubscribeToken = row.UnsubscribeToken
+		digests = append(digests, digest)
+	}
+	return digests, nil
+}
+
+// RecordDigestSent records that the digest's current slot was sent and moves
+// it on to next.
+func (r *WeatherRepository) RecordDigestSent(ctx context.Context, digest models.DigestSubscription, next time.Time) error {
+	return r.finishDigest(ctx, digest, nil, next)
+}
+
+// RecordDigestFailed records that the digest's current slot could not be
+// sent and will not be retried, and moves it on to next.
+func (r *WeatherRepository) RecordDigestFailed(ctx context.Context, digest models.DigestSubscription, sendErr error, next time.Time) error {
+	return r.finishDigest(ctx, digest, sendErr, next)
+}
+
+func (r *WeatherRepository) finishDigest(ctx context.Context, digest models.DigestSubscription, sendErr error, next time.Time) error {
+	status, message := models.DigestSent, ""
+	if sendErr != nil {
+		status, message = models.DigestFailed, sendErr.Error()
+	}
+
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	err = q.InsertDigestDelivery(ctx, db.InsertDigestDeliveryParams{
+		DigestID:     digest.ID,
+		ScheduledFor: timestamptz(digest.NextSendAt),
+		Status:       status,
+		Attempts:     int32(digest.Attempts + 1),
+		Error:        message,
+	})
+	if err != nil {
+		return err
+	}
+	err = q.AdvanceDigest(ctx, db.AdvanceDigestParams{
+		ID:         digest.ID,
+		NextSendAt: timestamptz(next),
+		Sent:       sendErr == nil,
+		LastError:  message,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RetryDigest records a failed attempt at the digest's current slot, which is
+// tried again after retry.
+func (r *WeatherRepository) RetryDigest(ctx context.Context, id int32, sendErr error, retry time.Duration) error {
+	return r.q.RetryDigest(ctx, db.RetryDigestParams{
+		ID:           id,
+		RetrySeconds: retry.Seconds(),
+		LastError:    sendErr.Error(),
+	})
+}
+
+// ListDigestDeliveries returns the latest deliveries of a digest, newest
+// first.
+func (r *WeatherRepository) ListDigestDeliveries(ctx context.Context, id int32, limit int) ([]models.DigestDelivery, error) {
+	rows, err := r.q.ListDigestDeliveries(ctx, db.ListDigestDeliveriesParams{
+		DigestID: id,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.DigestDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, models.DigestDelivery{
+			ScheduledFor: row.ScheduledFor.Time,
+			Status:       row.Status,
+			Attempts:     int(row.Attempts),
+			Error:        row.Error,
+			CreatedAt:    row.CreatedAt.Time,
+		})
+	}
+	return deliveries, nil
+}
+
+func digestSubscription(row db.DigestSubscription) (models.DigestSubscription, error) {
+	digest := models.DigestSubscription{
+		ID:    row.ID,
+		Email: row.Email,
+		DigestSchedule: models.DigestSchedule{
+			Frequency: row.Frequency,
+			SendTime:  time.Time{}.Add(time.Duration(row.SendTime.Microseconds) * time.Microsecond).Format(models.SendTimeLayout),
+			Timezone:  row.Timezone,
+		},
+		NextSendAt: row.NextSendAt.Time,
+		LastError:  row.LastError,
+		CreatedAt:  row.CreatedAt.Time,
+		Attempts:   int(row.Attempts),
+	}
+	if err := json.Unmarshal(row.Locations, &digest.Locations); err != nil {
+		return models.DigestSubscription{}, err
+	}
+	if row.Weekday.Valid {
+		digest.Weekday = strings.ToLower(time.Weekday(row.Weekday.Int16).String())
+	}
+	if row.LastSentAt.Valid {
+		digest.LastSentAt = &row.LastSentAt.Time
+	}
+	if row.ConfirmedAt.Valid {
+		digest.ConfirmedAt = &row.ConfirmedAt.Time
+	}
+	if row.UnsubscribedAt.Valid {
+		digest.UnsubscribedAt = &row.UnsubscribedAt.Time
+	}
+	return digest, nil
+}
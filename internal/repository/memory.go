@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// MemoryRepository is a Store that keeps the query history in process
+// memory. Nothing survives a restart; it is meant for local runs and tests.
+type MemoryRepository struct {
+	// maxEntries bounds the history; the oldest entries are dropped first.
+	// Zero means unbounded.
+	maxEntries int
+
+	mu      sync.RWMutex
+	nextID  int32
+	entries []memoryEntry
+}
+
+type memoryEntry struct {
+	id        int32
+	city      string
+	queryTime time.Time
+	weather   models.WeatherResponse
+}
+
+// NewMemoryRepository returns an empty in-memory store holding at most
+// maxEntries queries, or any number if maxEntries is zero.
+func NewMemoryRepository(maxEntries int) *MemoryRepository {
+	return &MemoryRepository{maxEntries: maxEntries}
+}
+
+func (r *MemoryRepository) SaveWeatherQuery(ctx context.Context, city string, weather models.WeatherResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	r.entries = append(r.entries, memoryEntry{
+		id:        r.nextID,
+		city:      city,
+		queryTime: time.Now(),
+		weather:   weather,
+	})
+	if r.maxEntries > 0 && len(r.entries) > r.maxEntries {
+		r.entries = append(r.entries[:0:0], r.entries[len(r.entries)-r.maxEntries:]...)
+	}
+	return nil
+}
+
+func (r *MemoryRepository) GetRecentWeather(ctx context.Context, city string, since time.Time) (models.WeatherResponse, time.Time, error) {
+	return r.latest(func(e memoryEntry) bool {
+		return e.city == city && !e.queryTime.Before(since)
+	})
+}
+
+func (r *MemoryRepository) GetLatestWeather(ctx context.Context, coordinate models.Location) (models.WeatherResponse, time.Time, error) {
+	lat, lon := RoundCoordinate(coordinate.Latitude), RoundCoordinate(coordinate.Longitude)
+	return r.latest(func(e memoryEntry) bool {
+		return RoundCoordinate(e.weather.Latitude) == lat && RoundCoordinate(e.weather.Longitude) == lon
+	})
+}
+
+// latest returns the newest entry matching match, or ErrNotFound.
+func (r *MemoryRepository) latest(match func(memoryEntry) bool) (models.WeatherResponse, time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if e := r.entries[i]; match(e) {
+			return e.weather, e.queryTime, nil
+		}
+	}
+	return models.WeatherResponse{}, time.Time{}, ErrNotFound
+}
+
+func (r *MemoryRepository) GetHistory(ctx context.Context, filter models.HistoryFilter) (models.HistoryPage, error) {
+	cursorTime, cursorID, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return models.HistoryPage{}, err
+	}
+	limit := historyLimit(filter)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	page := models.HistoryPage{Items: []models.HistoryEntry{}}
+	for i := len(r.entries) - 1; i >= 0 && len(page.Items) <= limit; i-- {
+		e := r.entries[i]
+		if filter.City != "" && e.city != filter.City {
+			continue
+		}
+		if filter.Coordinate != nil {
+			at := models.Location{Latitude: e.weather.Latitude, Longitude: e.weather.Longitude}
+			if filter.Coordinate.DistanceKm(at) > filter.RadiusKm {
+				continue
+			}
+		}
+		if !filter.From.IsZero() && e.queryTime.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !e.queryTime.Before(filter.To) {
+			continue
+		}
+		// Cursors carry microseconds, so compare at that precision.
+		if cursorTime.Valid {
+			t, c := e.queryTime.UnixMicro(), cursorTime.Time.UnixMicro()
+			if t > c || (t == c && e.id >= cursorID.Int32) {
+				continue
+			}
+		}
+
+		lat, lon := e.weather.Latitude, e.weather.Longitude
+		page.Items = append(page.Items, historyEntry(e.id, e.city, &lat, &lon, e.queryTime, e.weather, filter.Summary))
+	}
+
+	trimPage(&page, limit)
+	return page, nil
+}
+
+func (r *MemoryRepository) Stats(ctx context.Context) (models.StorageStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := models.StorageStats{
+		Backend: BackendMemory,
+		Queries: int64(len(r.entries)),
+	}
+	cities := make(map[string]struct{})
+	locations := make(map[models.Location]struct{})
+	for _, e := range r.entries {
+		cities[e.city] = struct{}{}
+		locations[models.Location{
+			Latitude:  RoundCoordinate(e.weather.Latitude),
+			Longitude: RoundCoordinate(e.weather.Longitude),
+		}] = struct{}{}
+	}
+	stats.Cities = int64(len(cities))
+	stats.Locations = int64(len(locations))
+	if len(r.entries) > 0 {
+		oldest, newest := r.entries[0].queryTime, r.entries[len(r.entries)-1].queryTime
+		stats.OldestQuery = &oldest
+		stats.NewestQuery = &newest
+	}
+	return stats, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The retention methods below come in pairs: Count* reports how many rows a
+// pass would remove, and the matching delete removes at most batchSize of
+// them per call so no statement holds locks for long.
+
+func (r *WeatherRepository) CountExpiredSnapshots(ctx context.Context, before time.Time) (int64, error) {
+	return r.q.CountExpiredSnapshots(ctx, timestamptz(before))
+}
+
+// DeleteExpiredSnapshots removes snapshots fetched before the given time,
+// along with their daily and hourly values.
+func (r *WeatherRepository) DeleteExpiredSnapshots(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	return r.q.DeleteExpiredSnapshots(ctx, db.DeleteExpiredSnapshotsParams{
+		Before:    timestamptz(before),
+		BatchSize: int32(batchSize),
+	})
+}
+
+func (r *WeatherRepository) CountDownsampleSnapshots(ctx context.Context, before time.Time) (int64, error) {
+	return r.q.CountDownsampleSnapshots(ctx, timestamptz(before))
+}
+
+// DownsampleSnapshots removes all but the latest snapshot per location and day
+// among snapshots fetched before the given time. A snapshot shares its query's
+// time and rounded coordinates, so it is kept exactly when DownsampleHistory
+// keeps the query, and BackfillSnapshots has nothing to recreate.
+func (r *WeatherRepository) DownsampleSnapshots(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	return r.q.DownsampleSnapshots(ctx, db.DownsampleSnapshotsParams{
+		Before:    timestamptz(before),
+		BatchSize: int32(batchSize),
+	})
+}
+
+func (r *WeatherRepository) CountExpiredHistory(ctx context.Context, before time.Time) (int64, error) {
+	return r.q.CountExpiredHistory(ctx, timestamptz(before))
+}
+
+// DeleteExpiredHistory removes weather queries made before the given time.
+func (r *WeatherRepository) DeleteExpiredHistory(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	return r.q.DeleteExpiredHistory(ctx, db.DeleteExpiredHistoryParams{
+		Before:    timestamptz(before),
+		BatchSize: int32(batchSize),
+	})
+}
+
+func (r *WeatherRepository) CountDownsampleHistory(ctx context.Context, before time.Time) (int64, error) {
+	return r.q.CountDownsampleHistory(ctx, timestamptz(before))
+}
+
+// DownsampleHistory removes all but the latest weather query per location and
+// day among queries made before the given time. Locations are told apart by
+// coordinates rounded as in RoundCoordinate, or by city for queries stored
+// without them.
+func (r *WeatherRepository) DownsampleHistory(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	return r.q.DownsampleHistory(ctx, db.DownsampleHistoryParams{
+		Before:    timestamptz(before),
+		BatchSize: int32(batchSize),
+	})
+}
+
+func timestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// unknownProvider is recorded for snapshots whose response carries no source,
+// such as rows stored before the provider was tracked.
+const unknownProvider = "unknown"
+
+// defaultBackfillBatchSize is used when BackfillSnapshots gets no batch size.
+const defaultBackfillBatchSize = 500
+
+// ErrNotFound is returned when no stored snapshot matches a lookup.
+var ErrNotFound = errors.New("no stored weather for location")
+
+// RoundCoordinate rounds a latitude or longitude to the precision locations
+// are keyed by (about 11 m), so repeated lookups of a place share one row.
+func RoundCoordinate(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
+
+// saveSnapshot writes weather as a forecast snapshot with its daily and hourly
+// values. It must run inside the caller's transaction.
+func saveSnapshot(ctx context.Context, q *db.Queries, city string, weather models.WeatherResponse, fetchedAt pgtype.Timestamptz, historyID int32) error {
+	name := weather.Address
+	if name == "" {
+		name = city
+	}
+	location, err := q.UpsertLocation(ctx, db.UpsertLocationParams{
+		Name:      name,
+		Country:   weather.Country,
+		Latitude:  RoundCoordinate(weather.Latitude),
+		Longitude: RoundCoordinate(weather.Longitude),
+		Timezone:  weather.Timezone,
+	})
+	if err != nil {
+		return err
+	}
+
+	provider := weather.Source
+	if provider == "" {
+		provider = unknownProvider
+	}
+	snapshot, err := q.InsertForecastSnapshot(ctx, db.InsertForecastSnapshotParams{
+		LocationID: location.ID,
+		Provider:   provider,
+		FetchedAt:  fetchedAt,
+		HistoryID:  pgtype.Int4{Int32: historyID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	var days []db.InsertDailyValuesParams
+	var hours []db.InsertHourlyValuesParams
+	for i, day := range weather.Days {
+		validAt, err := models.ParseDatetime(day.Datetime)
+		if err != nil {
+			return fmt.Errorf("day %d: %w", i, err)
+		}
+		days = append(days, db.InsertDailyValuesParams{
+			SnapshotID: snapshot.ID,
+			Position:   int16(i),
+			ValidAt:    pgtype.Timestamptz{Time: validAt, Valid: true},
+			Temp:       day.Temp,
+			Tempmin:    day.Tempmin,
+			Tempmax:    day.Tempmax,
+			Humidity:   day.Humidity,
+			Precip:     day.Precip,
+			Snow:       day.Snow,
+			Snowdepth:  day.Snowdepth,
+			Windspeed:  day.Windspeed,
+		})
+
+		for j, hour := range day.Hours {
+			validAt, err := models.ParseDatetime(hour.Datetime)
+			if err != nil {
+				return fmt.Errorf("day %d hour %d: %w", i, j, err)
+			}
+			hours = append(hours, db.InsertHourlyValuesParams{
+				SnapshotID:  snapshot.ID,
+				DayPosition: int16(i),
+				Position:    int16(j),
+				ValidAt:     pgtype.Timestamptz{Time: validAt, Valid: true},
+				Temp:        hour.Temp,
+				Tempmin:     hour.Tempmin,
+				Tempmax:     hour.Tempmax,
+				Humidity:    hour.Humidity,
+				Precip:      hour.Precip,
+				Snow:        hour.Snow,
+				Snowdepth:   hour.Snowdepth,
+				Windspeed:   hour.Windspeed,
+			})
+		}
+	}
+
+	if len(days) > 0 {
+		if _, err := q.InsertDailyValues(ctx, days); err != nil {
+			return err
+		}
+	}
+	if len(hours) > 0 {
+		if _, err := q.InsertHourlyValues(ctx, hours); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLatestWeather rebuilds the most recent snapshot stored for the location
+// at the given coordinates. It returns ErrNotFound if there is none.
+func (r *WeatherRepository) GetLatestWeather(ctx context.Context, coordinate models.Location) (models.WeatherResponse, time.Time, error) {
+	location, err := r.q.GetLocationByCoordinates(ctx, db.GetLocationByCoordinatesParams{
+		Latitude:  RoundCoordinate(coordinate.Latitude),
+		Longitude: RoundCoordinate(coordinate.Longitude),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.WeatherResponse{}, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+
+	snapshot, err := r.q.GetLatestSnapshot(ctx, location.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.WeatherResponse{}, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+
+	weather, err := r.loadSnapshot(ctx, location, snapshot)
+	if err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+	return weather, snapshot.FetchedAt.Time, nil
+}
+
+// loadSnapshot assembles a WeatherResponse from a snapshot's stored values.
+func (r *WeatherRepository) loadSnapshot(ctx context.Context, location db.Location, snapshot db.ForecastSnapshot) (models.WeatherResponse, error) {
+	dailyValues, err := r.q.ListDailyValues(ctx, snapshot.ID)
+	if err != nil {
+		return models.WeatherResponse{}, err
+	}
+	hourlyValues, err := r.q.ListHourlyValues(ctx, snapshot.ID)
+	if err != nil {
+		return models.WeatherResponse{}, err
+	}
+
+	weather := models.WeatherResponse{
+		Address:   location.Name,
+		Country:   location.Country,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Timezone:  location.Timezone,
+		Source:    snapshot.Provider,
+		Days:      make([]models.Weather, 0, len(dailyValues)),
+		FetchedAt: snapshot.FetchedAt.Time,
+	}
+	if weather.Source == unknownProvider {
+		weather.Source = ""
+	}
+
+	for _, v := range dailyValues {
+		weather.Days = append(weather.Days, models.Weather{
+			Datetime:  models.FormatDatetime(v.ValidAt.Time),
+			Tempmin:   v.Tempmin,
+			Tempmax:   v.Tempmax,
+			Humidity:  v.Humidity,
+			Precip:    v.Precip,
+			Snow:      v.Snow,
+			Snowdepth: v.Snowdepth,
+			Windspeed: v.Windspeed,
+			Temp:      v.Temp,
+			Hours:     []models.Weather{},
+		})
+	}
+	for _, v := range hourlyValues {
+		if int(v.DayPosition) >= len(weather.Days) {
+			continue
+		}
+		day := &weather.Days[v.DayPosition]
+		day.Hours = append(day.Hours, models.Weather{
+			Datetime:  models.FormatDatetime(v.ValidAt.Time),
+			Tempmin:   v.Tempmin,
+			Tempmax:   v.Tempmax,
+			Humidity:  v.Humidity,
+			Precip:    v.Precip,
+			Snow:      v.Snow,
+			Snowdepth: v.Snowdepth,
+			Windspeed: v.Windspeed,
+			Temp:      v.Temp,
+			Hours:     []models.Weather{},
+		})
+	}
+	return weather, nil
+}
+
+// BackfillSnapshots migrates weather_query_history rows that predate the
+// normalized tables into forecast snapshots, one transaction per batch. Rows
+// are matched to a location by their stored coordinates, falling back to the
+// latest known coordinates for the same city; rows with neither are skipped
+// and left in place. It is safe to run repeatedly.
+func (r *WeatherRepository) BackfillSnapshots(ctx context.Context, batchSize int) (migrated, skipped int, err error) {
+	if batchSize <= 0 {
+		batchSize = defaultBackfillBatchSize
+	}
+
+	var afterID int32
+	for {
+		rows, err := r.q.ListUnmigratedHistory(ctx, db.ListUnmigratedHistoryParams{
+			AfterID:   afterID,
+			BatchSize: int32(batchSize),
+		})
+		if err != nil {
+			return migrated, skipped, err
+		}
+		if len(rows) == 0 {
+			return migrated, skipped, nil
+		}
+
+		n, s, err := r.backfillBatch(ctx, rows)
+		if err != nil {
+			return migrated, skipped, err
+		}
+		migrated += n
+		skipped += s
+		afterID = rows[len(rows)-1].ID
+	}
+}
+
+func (r *WeatherRepository) backfillBatch(ctx context.Context, rows []db.WeatherQueryHistory) (migrated, skipped int, err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+	q := r.q.WithTx(tx)
+
+	for _, row := range rows {
+		var weather models.WeatherResponse
+		if err := json.Unmarshal(row.WeatherData, &weather); err != nil {
+			return 0, 0, fmt.Errorf("history row %d: %w", row.ID, err)
+		}
+
+		switch {
+		case row.Latitude.Valid && row.Longitude.Valid:
+			weather.Latitude, weather.Longitude = row.Latitude.Float64, row.Longitude.Float64
+		case weather.Latitude != 0 || weather.Longitude != 0:
+		default:
+			coords, err := q.GetLatestCoordinatesForCity(ctx, row.City)
+			if errors.Is(err, pgx.ErrNoRows) {
+				skipped++
+				continue
+			}
+			if err != nil {
+				return 0, 0, err
+			}
+			weather.Latitude, weather.Longitude = coords.Latitude, coords.Longitude
+		}
+
+		if err := saveSnapshot(ctx, q, row.City, weather, row.QueryTime, row.ID); err != nil {
+			return 0, 0, fmt.Errorf("history row %d: %w", row.ID, err)
+		}
+		migrated++
+	}
+	return migrated, skipped, tx.Commit(ctx)
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	sqlitedb "github.com/Orion777-cmd/weather-app/internal/db/sqlite"
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// SQLiteRepository is a Store backed by an embedded SQLite database file. It
+// keeps the query history only; snapshots, retention and partitioning are
+// Postgres features.
+type SQLiteRepository struct {
+	db *sql.DB
+	q  *sqlitedb.Queries
+}
+
+// NewSQLiteRepository opens the database at path, creating it and its schema
+// if needed. ":memory:" gives a database that lives as long as the process.
+func NewSQLiteRepository(ctx context.Context, path string) (*SQLiteRepository, error) {
+	conn, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time, and an in-memory database exists
+	// only on the connection that created it.
+	conn.SetMaxOpenConns(1)
+
+	if _, err := conn.ExecContext(ctx, sqlitedb.Schema); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &SQLiteRepository{db: conn, q: sqlitedb.New(conn)}, nil
+}
+
+// Close closes the database.
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+func (r *SQLiteRepository) SaveWeatherQuery(ctx context.Context, city string, weather models.WeatherResponse) error {
+	data, err := json.Marshal(weather)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.q.InsertWeatherQuery(ctx, sqlitedb.InsertWeatherQueryParams{
+		City:              city,
+		QueryTime:         time.Now().UnixMicro(),
+		WeatherData:       string(data),
+		Latitude:          sql.NullFloat64{Float64: weather.Latitude, Valid: true},
+		Longitude:         sql.NullFloat64{Float64: weather.Longitude, Valid: true},
+		LocationLatitude:  sql.NullFloat64{Float64: RoundCoordinate(weather.Latitude), Valid: true},
+		LocationLongitude: sql.NullFloat64{Float64: RoundCoordinate(weather.Longitude), Valid: true},
+	})
+	return err
+}
+
+func (r *SQLiteRepository) GetRecentWeather(ctx context.Context, city string, since time.Time) (models.WeatherResponse, time.Time, error) {
+	row, err := r.q.GetRecentWeather(ctx, sqlitedb.GetRecentWeatherParams{
+		City:      city,
+		QueryTime: since.UnixMicro(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WeatherResponse{}, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+	return decodeStoredWeather(row.WeatherData, row.QueryTime)
+}
+
+func (r *SQLiteRepository) GetLatestWeather(ctx context.Context, coordinate models.Location) (models.WeatherResponse, time.Time, error) {
+	row, err := r.q.GetLatestWeatherAt(ctx, sqlitedb.GetLatestWeatherAtParams{
+		LocationLatitude:  sql.NullFloat64{Float64: RoundCoordinate(coordinate.Latitude), Valid: true},
+		LocationLongitude: sql.NullFloat64{Float64: RoundCoordinate(coordinate.Longitude), Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WeatherResponse{}, time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+	return decodeStoredWeather(row.WeatherData, row.QueryTime)
+}
+
+func (r *SQLiteRepository) GetHistory(ctx context.Context, filter models.HistoryFilter) (models.HistoryPage, error) {
+	cursorTime, cursorID, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return models.HistoryPage{}, err
+	}
+	limit := historyLimit(filter)
+
+	params := sqlitedb.ListWeatherHistoryParams{
+		City:       sql.NullString{String: filter.City, Valid: filter.City != ""},
+		FromTime:   sql.NullInt64{Int64: filter.From.UnixMicro(), Valid: !filter.From.IsZero()},
+		ToTime:     sql.NullInt64{Int64: filter.To.UnixMicro(), Valid: !filter.To.IsZero()},
+		CursorTime: sql.NullInt64{Int64: cursorTime.Time.UnixMicro(), Valid: cursorTime.Valid},
+		CursorID:   sql.NullInt64{Int64: int64(cursorID.Int32), Valid: cursorID.Valid},
+		PageSize:   int64(limit + 1),
+	}
+	if filter.Coordinate != nil {
+		params.Latitude = sql.NullFloat64{Float64: filter.Coordinate.Latitude, Valid: true}
+		params.Longitude = sql.NullFloat64{Float64: filter.Coordinate.Longitude, Valid: true}
+		params.RadiusKm = sql.NullFloat64{Float64: filter.RadiusKm, Valid: true}
+	}
+
+	rows, err := r.q.ListWeatherHistory(ctx, params)
+	if err != nil {
+		return models.HistoryPage{}, err
+	}
+
+	page := models.HistoryPage{Items: []models.HistoryEntry{}}
+	for _, row := range rows {
+		var weather models.WeatherResponse
+		if err := json.Unmarshal([]byte(row.WeatherData), &weather); err != nil {
+			return models.HistoryPage{}, err
+		}
+		page.Items = append(page.Items, historyEntry(int32(row.ID), row.City,
+			nullFloat64Ptr(row.Latitude), nullFloat64Ptr(row.Longitude),
+			time.UnixMicro(row.QueryTime), weather, filter.Summary))
+	}
+
+	trimPage(&page, limit)
+	return page, nil
+}
+
+func (r *SQLiteRepository) Stats(ctx context.Context) (models.StorageStats, error) {
+	row, err := r.q.GetStorageStats(ctx)
+	if err != nil {
+		return models.StorageStats{}, err
+	}
+	stats := models.StorageStats{
+		Backend:   BackendSQLite,
+		Queries:   row.Queries,
+		Cities:    row.Cities,
+		Locations: row.Locations,
+	}
+	if row.Queries > 0 {
+		oldest, newest := time.UnixMicro(row.OldestQuery), time.UnixMicro(row.NewestQuery)
+		stats.OldestQuery = &oldest
+		stats.NewestQuery = &newest
+	}
+	return stats, nil
+}
+
+// decodeStoredWeather unmarshals a stored response and pairs it with the time
+// it was stored, which also serves as its fetch time.
+func decodeStoredWeather(data string, queryTime int64) (models.WeatherResponse, time.Time, error) {
+	var weather models.WeatherResponse
+	if err := json.Unmarshal([]byte(data), &weather); err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+	return weather, time.UnixMicro(queryTime), nil
+}
+
+func nullFloat64Ptr(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// Storage backend names, as used by the storage.backend config key.
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
+)
+
+// Store is the storage the weather service reads and writes. Postgres is the
+// full implementation; SQLite and the in-memory store keep the query history
+// only, which is enough to run the service without a database server.
+type Store interface {
+	// SaveWeatherQuery records a response fetched for city.
+	SaveWeatherQuery(ctx context.Context, city string, weather models.WeatherResponse) error
+	// GetRecentWeather returns the newest response stored for city since the
+	// given time, with the time it was stored, or ErrNotFound.
+	GetRecentWeather(ctx context.Context, city string, since time.Time) (models.WeatherResponse, time.Time, error)
+	// GetLatestWeather returns the newest response stored for the location
+	// at coordinate, with the time it was fetched, or ErrNotFound.
+	GetLatestWeather(ctx context.Context, coordinate models.Location) (models.WeatherResponse, time.Time, error)
+	// GetHistory returns one page of stored queries matching filter, newest
+	// first.
+	GetHistory(ctx context.Context, filter models.HistoryFilter) (models.HistoryPage, error)
+	// Stats summarizes what is stored.
+	Stats(ctx context.Context) (models.StorageStats, error)
+}
+
+var (
+	_ Store = (*WeatherRepository)(nil)
+	_ Store = (*SQLiteRepository)(nil)
+	_ Store = (*MemoryRepository)(nil)
+)
+
+// summarize builds the history summary of a stored response from its first
+// day, the same way ListWeatherHistorySummaries does in SQL.
+func summarize(weather models.WeatherResponse) *models.HistorySummary {
+	summary := &models.HistorySummary{Days: len(weather.Days)}
+	if len(weather.Days) > 0 {
+		day := weather.Days[0]
+		summary.Temp = day.Temp
+		summary.Tempmin = day.Tempmin
+		summary.Tempmax = day.Tempmax
+		summary.Humidity = day.Humidity
+		summary.Precip = day.Precip
+		summary.Windspeed = day.Windspeed
+	}
+	return summary
+}
+
+// historyEntry builds a page item, holding either the full response or its
+// summary.
+func historyEntry(id int32, city string, latitude, longitude *float64, queryTime time.Time, weather models.WeatherResponse, summary bool) models.HistoryEntry {
+	entry := models.HistoryEntry{
+		ID:        id,
+		City:      city,
+		Latitude:  latitude,
+		Longitude: longitude,
+		QueryTime: queryTime,
+	}
+	if summary {
+		entry.Summary = summarize(weather)
+	} else {
+		entry.Weather = &weather
+	}
+	return entry
+}
+
+// trimPage cuts a page fetched with one extra row down to limit and sets the
+// cursor for the next page if that extra row was present.
+func trimPage(page *models.HistoryPage, limit int) {
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(last.QueryTime, last.ID)
+	}
+}
+
+func historyLimit(filter models.HistoryFilter) int {
+	if filter.Limit <= 0 {
+		return models.DefaultHistoryLimit
+	}
+	return filter.Limit
+}
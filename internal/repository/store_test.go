@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+)
+
+// stores returns a fresh instance of every Store that runs without a server.
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+	sqlite, err := NewSQLiteRepository(context.Background(), ":memory:")
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	return map[string]Store{
+		BackendMemory: NewMemoryRepository(0),
+		BackendSQLite: sqlite,
+	}
+}
+
+func weatherAt(address string, lat, lon float64, temp float32) models.WeatherResponse {
+	return models.WeatherResponse{
+		Address:   address,
+		Latitude:  lat,
+		Longitude: lon,
+		Days:      []models.Weather{{Temp: temp, Tempmin: temp - 2, Tempmax: temp + 2}},
+	}
+}
+
+func TestStoreRecentWeather(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now().Add(-time.Minute)
+			if _, _, err := store.GetRecentWeather(ctx, "Berlin", start); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("empty store: got %v, want ErrNotFound", err)
+			}
+
+			store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52, 13.405, 10))
+			store.SaveWeatherQuery(ctx, "Paris", weatherAt("Paris", 48.8566, 2.3522, 15))
+			store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52, 13.405, 11))
+
+			weather, fetchedAt, err := store.GetRecentWeather(ctx, "Berlin", start)
+			if err != nil {
+				t.Fatalf("GetRecentWeather: %v", err)
+			}
+			if weather.Days[0].Temp != 11 {
+				t.Errorf("temp = %v, want the latest query's 11", weather.Days[0].Temp)
+			}
+			if fetchedAt.Before(start) {
+				t.Errorf("fetched at %v, before the test started", fetchedAt)
+			}
+
+			if _, _, err := store.GetRecentWeather(ctx, "Berlin", time.Now().Add(time.Minute)); !errors.Is(err, ErrNotFound) {
+				t.Errorf("since the future: got %v, want ErrNotFound", err)
+			}
+			if _, _, err := store.GetRecentWeather(ctx, "Rome", start); !errors.Is(err, ErrNotFound) {
+				t.Errorf("unknown city: got %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoreLatestWeather(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52001, 13.40499, 10))
+			store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52, 13.405, 12))
+
+			// Coordinates match at the rounded precision.
+			weather, _, err := store.GetLatestWeather(ctx, models.Location{Latitude: 52.52003, Longitude: 13.40502})
+			if err != nil {
+				t.Fatalf("GetLatestWeather: %v", err)
+			}
+			if weather.Days[0].Temp != 12 {
+				t.Errorf("temp = %v, want 12", weather.Days[0].Temp)
+			}
+
+			if _, _, err := store.GetLatestWeather(ctx, models.Location{Latitude: 48.8566, Longitude: 2.3522}); !errors.Is(err, ErrNotFound) {
+				t.Errorf("unknown location: got %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoreHistoryPages(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i := range 5 {
+				store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52, 13.405, float32(i)))
+			}
+			store.SaveWeatherQuery(ctx, "Paris", weatherAt("Paris", 48.8566, 2.3522, 20))
+
+			var temps []float32
+			filter := models.HistoryFilter{City: "Berlin", Limit: 2}
+			for pages := 0; ; pages++ {
+				if pages == 5 {
+					t.Fatal("cursor never ran out")
+				}
+				page, err := store.GetHistory(ctx, filter)
+				if err != nil {
+					t.Fatalf("GetHistory: %v", err)
+				}
+				if len(page.Items) > 2 {
+					t.Fatalf("page has %d items, limit is 2", len(page.Items))
+				}
+				for _, item := range page.Items {
+					if item.City != "Berlin" {
+						t.Errorf("item for %q in a Berlin page", item.City)
+					}
+					temps = append(temps, item.Weather.Days[0].Temp)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+
+			want := []float32{4, 3, 2, 1, 0}
+			if len(temps) != len(want) {
+				t.Fatalf("got temps %v, want %v", temps, want)
+			}
+			for i := range want {
+				if temps[i] != want[i] {
+					t.Fatalf("got temps %v, want %v newest first", temps, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreHistoryFilters(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52, 13.405, 10))
+			store.SaveWeatherQuery(ctx, "Potsdam", weatherAt("Potsdam", 52.3906, 13.0645, 9))
+			store.SaveWeatherQuery(ctx, "Paris", weatherAt("Paris", 48.8566, 2.3522, 15))
+
+			page, err := store.GetHistory(ctx, models.HistoryFilter{
+				Coordinate: &models.Location{Latitude: 52.52, Longitude: 13.405},
+				RadiusKm:   50,
+				Summary:    true,
+			})
+			if err != nil {
+				t.Fatalf("GetHistory: %v", err)
+			}
+			if len(page.Items) != 2 {
+				t.Fatalf("got %d items within 50 km of Berlin, want 2", len(page.Items))
+			}
+			for _, item := range page.Items {
+				if item.Weather != nil || item.Summary == nil {
+					t.Errorf("%s: want a summary only", item.City)
+				}
+			}
+			if s := page.Items[0].Summary; s.Temp != 9 || s.Tempmin != 7 || s.Tempmax != 11 || s.Days != 1 {
+				t.Errorf("summary = %+v", *s)
+			}
+
+			page, err = store.GetHistory(ctx, models.HistoryFilter{To: time.Now().Add(-time.Hour)})
+			if err != nil {
+				t.Fatalf("GetHistory: %v", err)
+			}
+			if len(page.Items) != 0 {
+				t.Errorf("got %d items before an hour ago, want none", len(page.Items))
+			}
+
+			if _, err := store.GetHistory(ctx, models.HistoryFilter{Cursor: "not a cursor"}); err == nil {
+				t.Error("invalid cursor accepted")
+			}
+		})
+	}
+}
+
+func TestStoreStats(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			stats, err := store.Stats(ctx)
+			if err != nil {
+				t.Fatalf("Stats: %v", err)
+			}
+			if stats.Backend != name || stats.Queries != 0 || stats.OldestQuery != nil {
+				t.Errorf("empty store stats = %+v", stats)
+			}
+
+			store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52, 13.405, 10))
+			store.SaveWeatherQuery(ctx, "berlin", weatherAt("Berlin", 52.52, 13.405, 11))
+			store.SaveWeatherQuery(ctx, "Paris", weatherAt("Paris", 48.8566, 2.3522, 15))
+
+			stats, err = store.Stats(ctx)
+			if err != nil {
+				t.Fatalf("Stats: %v", err)
+			}
+			if stats.Queries != 3 || stats.Cities != 3 || stats.Locations != 2 {
+				t.Errorf("stats = %+v, want 3 queries, 3 cities, 2 locations", stats)
+			}
+			if stats.OldestQuery == nil || stats.NewestQuery == nil || stats.NewestQuery.Before(*stats.OldestQuery) {
+				t.Errorf("query times = %v, %v", stats.OldestQuery, stats.NewestQuery)
+			}
+		})
+	}
+}
+
+func TestMemoryRepositoryMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRepository(2)
+	store.SaveWeatherQuery(ctx, "Berlin", weatherAt("Berlin", 52.52, 13.405, 10))
+	store.SaveWeatherQuery(ctx, "Paris", weatherAt("Paris", 48.8566, 2.3522, 15))
+	store.SaveWeatherQuery(ctx, "Rome", weatherAt("Rome", 41.9028, 12.4964, 20))
+
+	if _, _, err := store.GetRecentWeather(ctx, "Berlin", time.Time{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oldest entry kept past the bound: got %v", err)
+	}
+	stats, _ := store.Stats(ctx)
+	if stats.Queries != 2 {
+		t.Errorf("queries = %d, want 2", stats.Queries)
+	}
+}
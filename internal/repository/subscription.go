@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PendingDelivery is a claimed webhook delivery with what is needed to send it.
+type PendingDelivery struct {
+	ID          int64
+	EventKey    string
+	Payload     []byte
+	Attempts    int
+	CallbackURL string
+	Secret      string
+}
+
+// CreateSubscription stores a subscription. The secret is returned in the
+// result.
+func (r *WeatherRepository) CreateSubscription(ctx context.Context, in models.SubscriptionInput, secret string) (models.Subscription, error) {
+	params := db.CreateSubscriptionParams{
+		City:        in.City,
+		Condition:   in.Condition,
+		Days:        int16(in.Days),
+		CallbackUrl: in.CallbackURL,
+		Secret:      secret,
+	}
+	if in.Coordinate != nil {
+		params.Latitude = pgtype.Float8{Float64: in.Coordinate.Latitude, Valid: true}
+		params.Longitude = pgtype.Float8{Float64: in.Coordinate.Longitude, Valid: true}
+	}
+	row, err := r.q.CreateSubscription(ctx, params)
+	if err != nil {
+		return models.Subscription{}, err
+	}
+	sub := subscription(row)
+	sub.Secret = row.Secret
+	return sub, nil
+}
+
+// GetSubscription returns models.ErrSubscriptionNotFound if there is no
+// subscription with the given ID. The secret is not included.
+func (r *WeatherRepository) GetSubscription(ctx context.Context, id int32) (models.Subscription, error) {
+	row, err := r.q.GetSubscription(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.Subscription{}, models.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return models.Subscription{}, err
+	}
+	return subscription(row), nil
+}
+
+// DeleteSubscription removes a subscription and its deliveries. It returns
+// models.ErrSubscriptionNotFound if there is no subscription with the given ID.
+func (r *WeatherRepository) DeleteSubscription(ctx context.Context, id int32) error {
+	n, err := r.q.DeleteSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// ListSubscriptionsForLocation returns the subscriptions for any of the given
+// city names, matched case insensitively, or with a coordinate within
+// radiusKm of coordinate.
+func (r *WeatherRepository) ListSubscriptionsForLocation(ctx context.Context, cities []string, coordinate models.Location, radiusKm float64) ([]models.Subscription, error) {
+	lower := make([]string, 0, len(cities))
+	for _, city := range cities {
+		if city != "" {
+			lower = append(lower, strings.ToLower(city))
+		}
+	}
+	rows, err := r.q.ListSubscriptionsForLocation(ctx, db.ListSubscriptionsForLocationParams{
+		Cities:    lower,
+		Latitude:  coordinate.Latitude,
+		Longitude: coordinate.Longitude,
+		RadiusKm:  radiusKm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subs := make([]models.Subscription, 0, len(rows))
+	for _, row := range rows {
+		subs = append(subs, subscription(row))
+	}
+	return subs, nil
+}
+
+// EnqueueDelivery queues a notification for a subscription. It reports false
+// if one with the same event key was already queued.
+func (r *WeatherRepository) EnqueueDelivery(ctx context.Context, subscriptionID int32, eventKey string, payload []byte) (bool, error) {
+	n, err := r.q.EnqueueDelivery(ctx, db.EnqueueDeliveryParams{
+		SubscriptionID: subscriptionID,
+		EventKey:       eventKey,
+		Payload:        payload,
+	})
+	return n > 0, err
+}
+
+// ClaimDueDeliveries returns up to batchSize pending deliveries that are due
+// and holds them for lease, after which they are due again unless recorded.
+func (r *WeatherRepository) ClaimDueDeliveries(ctx context.Context, batchSize int, lease time.Duration) ([]PendingDelivery, error) {
+	rows, err := r.q.ClaimDueDeliveries(ctx, db.ClaimDueDeliveriesParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    int32(batchSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]PendingDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, PendingDelivery{
+			ID:          row.ID,
+			EventKey:    row.EventKey,
+			Payload:     row.Payload,
+			Attempts:    int(row.Attempts),
+			CallbackURL: row.CallbackUrl,
+			Secret:      row.Secret,
+		})
+	}
+	return deliveries, nil
+}
+
+func (r *WeatherRepository) MarkDeliveryDelivered(ctx context.Context, id int64, statusCode int) error {
+	return r.q.MarkDeliveryDelivered(ctx, db.MarkDeliveryDeliveredParams{
+		ID:             id,
+		LastStatusCode: pgtype.Int4{Int32: int32(statusCode), Valid: true},
+	})
+}
+
+// MarkDeliveryFailed records a failed attempt. statusCode is zero if no
+// response was received. The delivery is retried after retry unless dead is
+// set.
+func (r *WeatherRepository) MarkDeliveryFailed(ctx context.Context, id int64, statusCode int, deliveryErr error, retry time.Duration, dead bool) error {
+	return r.q.MarkDeliveryFailed(ctx, db.MarkDeliveryFailedParams{
+		ID:           id,
+		Dead:         dead,
+		StatusCode:   pgtype.Int4{Int32: int32(statusCode), Valid: statusCode != 0},
+		LastError:    deliveryErr.Error(),
+		RetrySeconds: retry.Seconds(),
+	})
+}
+
+// ListDeliveries returns the latest deliveries for a subscription, newest
+// first.
+func (r *WeatherRepository) ListDeliveries(ctx context.Context, subscriptionID int32, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.q.ListDeliveries(ctx, db.ListDeliveriesParams{
+		SubscriptionID: subscriptionID,
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		d := models.WebhookDelivery{
+			ID:             row.ID,
+			SubscriptionID: row.SubscriptionID,
+			EventKey:       row.EventKey,
+			Status:         row.Status,
+			Attempts:       int(row.Attempts),
+			NextAttemptAt:  row.NextAttemptAt.Time,
+			LastError:      row.LastError,
+			CreatedAt:      row.CreatedAt.Time,
+			Payload:        row.Payload,
+		}
+		if row.LastStatusCode.Valid {
+			code := int(row.LastStatusCode.Int32)
+			d.LastStatusCode = &code
+		}
+		if row.DeliveredAt.Valid {
+			d.DeliveredAt = &row.DeliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+func subscription(row db.Subscription) models.Subscription {
+	sub := models.Subscription{
+		ID:          row.ID,
+		City:        row.City,
+		Condition:   row.Condition,
+		Days:        int(row.Days),
+		CallbackURL: row.CallbackUrl,
+		CreatedAt:   row.CreatedAt.Time,
+	}
+	if row.Latitude.Valid && row.Longitude.Valid {
+		sub.Coordinate = &models.Location{Latitude: row.Latitude.Float64, Longitude: row.Longitude.Float64}
+	}
+	return sub
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/db"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ComputeVerification rescores stored forecasts against the observations
+// stored since the given time, replacing the previous scores. It returns the
+// number of scores written.
+func (r *WeatherRepository) ComputeVerification(ctx context.Context, since time.Time) (int64, error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.q.WithTx(tx)
+	if err := q.DeleteForecastVerification(ctx); err != nil {
+		return 0, err
+	}
+	n, err := q.ComputeForecastVerification(ctx, db.ComputeForecastVerificationParams{
+		Since:       timestamptz(since),
+		MaxLeadDays: models.MaxVerificationLeadDays,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit(ctx)
+}
+
+// GetVerification returns the stored scores matching filter.
+func (r *WeatherRepository) GetVerification(ctx context.Context, filter models.VerificationFilter) ([]models.VerificationScore, error) {
+	rows, err := r.q.ListForecastVerification(ctx, db.ListForecastVerificationParams{
+		Provider: pgtype.Text{String: filter.Provider, Valid: filter.Provider != ""},
+		Field:    pgtype.Text{String: filter.Field, Valid: filter.Field != ""},
+		LeadDays: pgtype.Int4{Int32: int32(filter.LeadDays), Valid: filter.LeadDays != 0},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]models.VerificationScore, 0, len(rows))
+	for _, row := range rows {
+		scores = append(scores, models.VerificationScore{
+			Provider:   row.Provider,
+			Field:      row.Field,
+			LeadDays:   int(row.LeadDays),
+			Samples:    int(row.Samples),
+			MAE:        row.Mae,
+			Bias:       row.Bias,
+			RMSE:       row.Rmse,
+			ComputedAt: row.ComputedAt.Time,
+		})
+	}
+	return scores, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/db"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (r *WeatherRepository) ListWatchedLocations(ctx context.Context) ([]models.WatchedLocation, error) {
+	rows, err := r.q.ListWatchedLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return watchedLocations(rows), nil
+}
+
+// GetWatchedLocation returns models.ErrWatchedLocationNotFound if there is no
+// watched location with the given ID.
+func (r *WeatherRepository) GetWatchedLocation(ctx context.Context, id int32) (models.WatchedLocation, error) {
+	row, err := r.q.GetWatchedLocation(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.WatchedLocation{}, models.ErrWatchedLocationNotFound
+	}
+	if err != nil {
+		return models.WatchedLocation{}, err
+	}
+	return watchedLocation(row), nil
+}
+
+// CreateWatchedLocation adds a watched location. It is due for its first
+// fetch immediately.
+func (r *WeatherRepository) CreateWatchedLocation(ctx context.Context, in models.WatchedLocationInput) (models.WatchedLocation, error) {
+	lat, lon := watchedCoordinate(in)
+	row, err := r.q.CreateWatchedLocation(ctx, db.CreateWatchedLocationParams{
+		Name:            in.Name,
+		City:            in.City,
+		Latitude:        lat,
+		Longitude:       lon,
+		IntervalSeconds: int32(in.IntervalSeconds),
+	})
+	if err != nil {
+		return models.WatchedLocation{}, err
+	}
+	return watchedLocation(row), nil
+}
+
+// UpdateWatchedLocation replaces a watched location and makes it due
+// immediately. It returns models.ErrWatchedLocationNotFound if there is no
+// watched location with the given ID.
+func (r *WeatherRepository) UpdateWatchedLocation(ctx context.Context, id int32, in models.WatchedLocationInput) (models.WatchedLocation, error) {
+	lat, lon := watchedCoordinate(in)
+	row, err := r.q.UpdateWatchedLocation(ctx, db.UpdateWatchedLocationParams{
+		ID:              id,
+		Name:            in.Name,
+		City:            in.City,
+		Latitude:        lat,
+		Longitude:       lon,
+		IntervalSeconds: int32(in.IntervalSeconds),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.WatchedLocation{}, models.ErrWatchedLocationNotFound
+	}
+	if err != nil {
+		return models.WatchedLocation{}, err
+	}
+	return watchedLocation(row), nil
+}
+
+// DeleteWatchedLocation returns models.ErrWatchedLocationNotFound if there is
+// no watched location with the given ID.
+func (r *WeatherRepository) DeleteWatchedLocation(ctx context.Context, id int32) error {
+	n, err := r.q.DeleteWatchedLocation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return models.ErrWatchedLocationNotFound
+	}
+	return nil
+}
+
+// ClaimDueWatchedLocations returns up to batchSize watched locations that are
+// due and holds them for lease. A claimed location that is not recorded with
+// RecordWatchedFetch becomes due again when the lease runs out.
+func (r *WeatherRepository) ClaimDueWatchedLocations(ctx context.Context, batchSize int, lease time.Duration) ([]models.WatchedLocation, error) {
+	rows, err := r.q.ClaimDueWatchedLocations(ctx, db.ClaimDueWatchedLocationsParams{
+		LeaseSeconds: lease.Seconds(),
+		BatchSize:    int32(batchSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return watchedLocations(rows), nil
+}
+
+// RecordWatchedFetch stores the outcome of a prefetch and schedules the next
+// one after delay. fetchErr is nil on success.
+func (r *WeatherRepository) RecordWatchedFetch(ctx context.Context, id int32, fetchErr error, delay time.Duration) error {
+	params := db.RecordWatchedFetchParams{
+		ID:           id,
+		Succeeded:    fetchErr == nil,
+		DelaySeconds: delay.Seconds(),
+	}
+	if fetchErr != nil {
+		params.LastError = fetchErr.Error()
+	}
+	return r.q.RecordWatchedFetch(ctx, params)
+}
+
+func watchedCoordinate(in models.WatchedLocationInput) (pgtype.Float8, pgtype.Float8) {
+	if in.Coordinate == nil {
+		return pgtype.Float8{}, pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: in.Coordinate.Latitude, Valid: true},
+		pgtype.Float8{Float64: in.Coordinate.Longitude, Valid: true}
+}
+
+func watchedLocations(rows []db.WatchedLocation) []models.WatchedLocation {
+	locations := make([]models.WatchedLocation, 0, len(rows))
+	for _, row := range rows {
+		locations = append(locations, watchedLocation(row))
+	}
+	return locations
+}
+
+func watchedLocation(row db.WatchedLocation) models.WatchedLocation {
+	w := models.WatchedLocation{
+		ID:              row.ID,
+		Name:            row.Name,
+		City:            row.City,
+		IntervalSeconds: int(row.IntervalSeconds),
+		NextFetchAt:     row.NextFetchAt.Time,
+		LastError:       row.LastError,
+		CreatedAt:       row.CreatedAt.Time,
+		UpdatedAt:       row.UpdatedAt.Time,
+	}
+	if row.Latitude.Valid && row.Longitude.Valid {
+		w.Coordinate = &models.Location{Latitude: row.Latitude.Float64, Longitude: row.Longitude.Float64}
+	}
+	if row.LastFetchedAt.Valid {
+		w.LastFetchedAt = &row.LastFetchedAt.Time
+	}
+	return w
+}
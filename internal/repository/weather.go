@@ -3,26 +3,179 @@ package repository
 import (
     "context"
     "encoding/json"
+    "time"
     "github.com/Orion777-cmd/weather-app/internal/constants/models"
     "github.com/Orion777-cmd/weather-app/internal/db"
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgtype"
 )
 
+// DB is the part of a pgx connection the repository needs: sqlc queries plus
+// transactions.
+type DB interface {
+	db.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WeatherRepository is the Postgres Store. Besides the query history it keeps
+// normalized forecast snapshots and backs retention and backfill.
 type WeatherRepository struct {
-    q db.Querier
+    conn DB
+    q    *db.Queries
 }
 
-func NewWeatherRepository(q db.Querier) *WeatherRepository {
-    return &WeatherRepository{q: q}
+func NewWeatherRepository(conn DB) *WeatherRepository {
+    return &WeatherRepository{conn: conn, q: db.New(conn)}
 }
 
+// SaveWeatherQuery records the query in weather_query_history and stores the
+// response as a normalized forecast snapshot, in a single transaction.
 func (r *WeatherRepository) SaveWeatherQuery(ctx context.Context, city string, weather models.WeatherResponse) error {
     data, err := json.Marshal(weather)
     if err != nil {
         return err
     }
-    _, err = r.q.InsertWeatherQuery(ctx, db.InsertWeatherQueryParams{
+
+    tx, err := r.conn.Begin(ctx)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback(ctx)
+
+    q := r.q.WithTx(tx)
+    row, err := q.InsertWeatherQuery(ctx, db.InsertWeatherQueryParams{
         City:        city,
         Column2: data,
+        Latitude:  pgtype.Float8{Float64: weather.Latitude, Valid: true},
+        Longitude: pgtype.Float8{Float64: weather.Longitude, Valid: true},
     })
-    return err
-}
\ No newline at end of file
+    if err != nil {
+        return err
+    }
+
+    if err := saveSnapshot(ctx, q, city, weather, row.QueryTime, row.ID); err != nil {
+        return err
+    }
+    return tx.Commit(ctx)
+}
+
+// GetRecentWeather returns the newest response stored for city since the given
+// time, with the time it was stored. It returns ErrNotFound if there is none.
+func (r *WeatherRepository) GetRecentWeather(ctx context.Context, city string, since time.Time) (models.WeatherResponse, time.Time, error) {
+	rows, err := r.q.GetRecentWeather(ctx, db.GetRecentWeatherParams{
+		City:      city,
+		QueryTime: timestamptz(since),
+	})
+	if err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+	if len(rows) == 0 {
+		return models.WeatherResponse{}, time.Time{}, ErrNotFound
+	}
+
+	var weather models.WeatherResponse
+	if err := json.Unmarshal(rows[0].WeatherData, &weather); err != nil {
+		return models.WeatherResponse{}, time.Time{}, err
+	}
+	return weather, rows[0].QueryTime.Time, nil
+}
+
+// GetHistory returns one page of stored weather queries matching filter,
+// newest first.
+func (r *WeatherRepository) GetHistory(ctx context.Context, filter models.HistoryFilter) (models.HistoryPage, error) {
+	cursorTime, cursorID, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return models.HistoryPage{}, err
+	}
+
+	limit := historyLimit(filter)
+
+	// One extra row tells us whether another page exists.
+	params := db.ListWeatherHistoryParams{
+		City:       pgtype.Text{String: filter.City, Valid: filter.City != ""},
+		FromTime:   pgtype.Timestamptz{Time: filter.From, Valid: !filter.From.IsZero()},
+		ToTime:     pgtype.Timestamptz{Time: filter.To, Valid: !filter.To.IsZero()},
+		CursorTime: cursorTime,
+		CursorID:   cursorID,
+		PageSize:   int32(limit + 1),
+	}
+	if filter.Coordinate != nil {
+		params.Latitude = pgtype.Float8{Float64: filter.Coordinate.Latitude, Valid: true}
+		params.Longitude = pgtype.Float8{Float64: filter.Coordinate.Longitude, Valid: true}
+		params.RadiusKm = pgtype.Float8{Float64: filter.RadiusKm, Valid: true}
+	}
+
+	page := models.HistoryPage{Items: []models.HistoryEntry{}}
+	if filter.Summary {
+		rows, err := r.q.ListWeatherHistorySummaries(ctx, db.ListWeatherHistorySummariesParams(params))
+		if err != nil {
+			return models.HistoryPage{}, err
+		}
+		for _, row := range rows {
+			page.Items = append(page.Items, models.HistoryEntry{
+				ID:        row.ID,
+				City:      row.City,
+				Latitude:  float8Ptr(row.Latitude),
+				Longitude: float8Ptr(row.Longitude),
+				QueryTime: row.QueryTime.Time,
+				Summary: &models.HistorySummary{
+					Days:      int(row.Days),
+					Temp:      row.Temp,
+					Tempmin:   row.Tempmin,
+					Tempmax:   row.Tempmax,
+					Humidity:  row.Humidity,
+					Precip:    row.Precip,
+					Windspeed: row.Windspeed,
+				},
+			})
+		}
+	} else {
+		rows, err := r.q.ListWeatherHistory(ctx, params)
+		if err != nil {
+			return models.HistoryPage{}, err
+		}
+		for _, row := range rows {
+			var weather models.WeatherResponse
+			if err := json.Unmarshal(row.WeatherData, &weather); err != nil {
+				return models.HistoryPage{}, err
+			}
+			page.Items = append(page.Items, models.HistoryEntry{
+				ID:        row.ID,
+				City:      row.City,
+				Latitude:  float8Ptr(row.Latitude),
+				Longitude: float8Ptr(row.Longitude),
+				QueryTime: row.QueryTime.Time,
+				Weather:   &weather,
+			})
+		}
+	}
+
+	trimPage(&page, limit)
+	return page, nil
+}
+
+// Stats counts the stored queries and the locations they resolved to.
+func (r *WeatherRepository) Stats(ctx context.Context) (models.StorageStats, error) {
+	row, err := r.q.GetStorageStats(ctx)
+	if err != nil {
+		return models.StorageStats{}, err
+	}
+	stats := models.StorageStats{
+		Backend:   BackendPostgres,
+		Queries:   row.Queries,
+		Cities:    row.Cities,
+		Locations: row.Locations,
+	}
+	if row.OldestQuery.Valid {
+		stats.OldestQuery = &row.OldestQuery.Time
+		stats.NewestQuery = &row.NewestQuery.Time
+	}
+	return stats, nil
+}
+
+func float8Ptr(v pgtype.Float8) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
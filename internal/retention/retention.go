@@ -0,0 +1,169 @@
+// Package retention prunes stored weather data on a schedule: full snapshots
+// are kept for a number of days, older data is downsampled to one query and
+// its snapshot per location per day, and anything past a hard limit is deleted.
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	runsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_retention_runs_total",
+		Help: "Retention runs by result.",
+	}, []string{"result"})
+	rowsDeletedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_retention_rows_deleted_total",
+		Help: "Rows deleted by the retention worker.",
+	}, []string{"table", "action"})
+	rowsEligible = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "weather_retention_rows_eligible",
+		Help: "Rows eligible for deletion at the start of the last run.",
+	}, []string{"table", "action"})
+	runDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "weather_retention_run_duration_seconds",
+		Help:    "Duration of retention runs.",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	})
+	lastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_retention_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful retention run.",
+	})
+)
+
+// Config controls the retention schedule and policy.
+type Config struct {
+	Interval      time.Duration
+	FullDays      int
+	HardLimitDays int
+	BatchSize     int
+	// DryRun only counts and logs the rows that would be deleted.
+	DryRun bool
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Interval, validation.Required),
+		validation.Field(&c.FullDays, validation.Min(1)),
+		validation.Field(&c.HardLimitDays, validation.Min(c.FullDays+1)),
+		validation.Field(&c.BatchSize, validation.Required, validation.Min(1)),
+	)
+}
+
+// Worker applies the retention policy to the weather repository.
+type Worker struct {
+	repo *repository.WeatherRepository
+	cfg  Config
+	log  *zap.Logger
+}
+
+// step is one pruning pass over a table.
+type step struct {
+	table  string
+	action string
+	before time.Time
+	count  func(ctx context.Context, before time.Time) (int64, error)
+	purge  func(ctx context.Context, before time.Time, batchSize int) (int64, error)
+}
+
+func NewWorker(repo *repository.WeatherRepository, cfg Config, log *zap.Logger) *Worker {
+	return &Worker{repo: repo, cfg: cfg, log: log}
+}
+
+// Run applies the policy immediately and then on every interval until ctx is
+// cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.log.Error("Retention run failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce performs a single retention pass.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	start := time.Now()
+	defer func() { runDuration.Observe(time.Since(start).Seconds()) }()
+
+	// Cut-offs are aligned to midnight so a day is never partially downsampled.
+	today := start.Truncate(24 * time.Hour)
+	downsampleBefore := today.AddDate(0, 0, -w.cfg.FullDays)
+	expireBefore := today.AddDate(0, 0, -w.cfg.HardLimitDays)
+
+	// Both tables keep the latest row per location and day, so the snapshots
+	// kept are those of the queries kept, whichever order the steps run in.
+	steps := []step{
+		{"forecast_snapshots", "expire", expireBefore, w.repo.CountExpiredSnapshots, w.repo.DeleteExpiredSnapshots},
+		{"forecast_snapshots", "downsample", downsampleBefore, w.repo.CountDownsampleSnapshots, w.repo.DownsampleSnapshots},
+		{"weather_query_history", "expire", expireBefore, w.repo.CountExpiredHistory, w.repo.DeleteExpiredHistory},
+		{"weather_query_history", "downsample", downsampleBefore, w.repo.CountDownsampleHistory, w.repo.DownsampleHistory},
+	}
+
+	for _, s := range steps {
+		if err := w.runStep(ctx, s); err != nil {
+			runsTotal.WithLabelValues("error").Inc()
+			return err
+		}
+	}
+
+	runsTotal.WithLabelValues("success").Inc()
+	lastSuccess.SetToCurrentTime()
+	w.log.Info("Retention run completed", zap.Duration("duration", time.Since(start)), zap.Bool("dry_run", w.cfg.DryRun))
+	return nil
+}
+
+func (w *Worker) runStep(ctx context.Context, s step) error {
+	eligible, err := s.count(ctx, s.before)
+	if err != nil {
+		return err
+	}
+	rowsEligible.WithLabelValues(s.table, s.action).Set(float64(eligible))
+
+	if w.cfg.DryRun || eligible == 0 {
+		w.log.Info("Retention step",
+			zap.String("table", s.table),
+			zap.String("action", s.action),
+			zap.Time("before", s.before),
+			zap.Int64("eligible", eligible),
+			zap.Bool("dry_run", w.cfg.DryRun))
+		return nil
+	}
+
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := s.purge(ctx, s.before, w.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		deleted += n
+		rowsDeletedTotal.WithLabelValues(s.table, s.action).Add(float64(n))
+		if n < int64(w.cfg.BatchSize) {
+			break
+		}
+	}
+
+	w.log.Info("Retention step",
+		zap.String("table", s.table),
+		zap.String("action", s.action),
+		zap.Time("before", s.before),
+		zap.Int64("deleted", deleted))
+	return nil
+}
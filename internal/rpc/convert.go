@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	weatherv1 "github.com/Orion777-cmd/weather-app/proto/weather/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func weatherRequest(in *weatherv1.WeatherRequest) models.WeatherRequest {
+	rq := models.WeatherRequest{
+		City:     in.GetCity(),
+		DateTime: in.GetDatetime(),
+		Filter:   in.GetFilter(),
+	}
+	if l := in.GetLocation(); l != nil {
+		rq.Coordinate = location(l)
+	}
+	return rq
+}
+
+func historyFilter(in *weatherv1.GetHistoryRequest) models.HistoryFilter {
+	filter := models.HistoryFilter{
+		City:     in.GetCity(),
+		RadiusKm: in.GetRadiusKm(),
+		Cursor:   in.GetCursor(),
+		Limit:    int(in.GetLimit()),
+		Summary:  in.GetSummary(),
+	}
+	if l := in.GetCoordinate(); l != nil {
+		coordinate := location(l)
+		filter.Coordinate = &coordinate
+	}
+	if in.From != nil {
+		filter.From = in.From.AsTime()
+	}
+	if in.To != nil {
+		filter.To = in.To.AsTime()
+	}
+	return filter
+}
+
+func location(l *weatherv1.Location) models.Location {
+	return models.Location{Latitude: l.GetLatitude(), Longitude: l.GetLongitude()}
+}
+
+func weatherResponse(w models.WeatherResponse) *weatherv1.WeatherResponse {
+	out := &weatherv1.WeatherResponse{
+		Address:   w.Address,
+		Country:   w.Country,
+		Latitude:  w.Latitude,
+		Longitude: w.Longitude,
+		Timezone:  w.Timezone,
+		Source:    w.Source,
+		Days:      weatherList(w.Days),
+		Origin:    w.Origin,
+		Age:       w.Age,
+		Stale:     w.Stale,
+	}
+	if !w.FetchedAt.IsZero() {
+		out.FetchedAt = timestamppb.New(w.FetchedAt)
+	}
+	if a := w.Anomalies; a != nil {
+		out.Anomalies = &weatherv1.Anomalies{
+			Samples:    int32(a.Samples),
+			WindowDays: int32(a.WindowDays),
+			Temp:       anomalyScore(a.Temp),
+			Precip:     anomalyScore(a.Precip),
+			Windspeed:  anomalyScore(a.Windspeed),
+			Flags:      a.Flags,
+		}
+	}
+	return out
+}
+
+func weatherList(days []models.Weather) []*weatherv1.Weather {
+	if len(days) == 0 {
+		return nil
+	}
+	out := make([]*weatherv1.Weather, 0, len(days))
+	for _, d := range days {
+		out = append(out, &weatherv1.Weather{
+			Datetime:  d.Datetime,
+			Tempmin:   d.Tempmin,
+			Tempmax:   d.Tempmax,
+			Humidity:  d.Humidity,
+			Precip:    d.Precip,
+			Snow:      d.Snow,
+			Snowdepth: d.Snowdepth,
+			Windspeed: d.Windspeed,
+			Temp:      d.Temp,
+			Hours:     weatherList(d.Hours),
+		})
+	}
+	return out
+}
+
+func anomalyScore(s *models.AnomalyScore) *weatherv1.AnomalyScore {
+	if s == nil {
+		return nil
+	}
+	return &weatherv1.AnomalyScore{Value: s.Value, Mean: s.Mean, Stddev: s.Stddev, ZScore: s.ZScore}
+}
+
+func historyPage(page models.HistoryPage) *weatherv1.GetHistoryResponse {
+	out := &weatherv1.GetHistoryResponse{
+		Items:      make([]*weatherv1.HistoryEntry, 0, len(page.Items)),
+		NextCursor: page.NextCursor,
+	}
+	for _, e := range page.Items {
+		entry := &weatherv1.HistoryEntry{
+			Id:        e.ID,
+			City:      e.City,
+			Latitude:  e.Latitude,
+			Longitude: e.Longitude,
+			QueryTime: timestamppb.New(e.QueryTime),
+		}
+		if e.Weather != nil {
+			entry.Weather = weatherResponse(*e.Weather)
+		}
+		if s := e.Summary; s != nil {
+			entry.Summary = &weatherv1.HistorySummary{
+				Days:      int32(s.Days),
+				Temp:      s.Temp,
+				Tempmin:   s.Tempmin,
+				Tempmax:   s.Tempmax,
+				Humidity:  s.Humidity,
+				Precip:    s.Precip,
+				Windspeed: s.Windspeed,
+			}
+		}
+		out.Items = append(out.Items, entry)
+	}
+	return out
+}
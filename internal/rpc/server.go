@@ -0,0 +1,191 @@
+// Package rpc serves the weather API over gRPC, using the same
+// module.WeatherService as the HTTP handlers. The service is defined in
+// proto/weather/v1/weather.proto.
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	weatherv1 "github.com/Orion777-cmd/weather-app/proto/weather/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config tunes WatchWeather streams.
+type Config struct {
+	// Refresh is how often a stream asks the weather service for its
+	// location, so updates arrive even when nothing else fetches it.
+	Refresh time.Duration
+	// Buffer is how many updates a slow client may fall behind by before
+	// missing some.
+	Buffer int
+	// MatchRadiusKm is how close an update must be to a watched location to
+	// count as it.
+	MatchRadiusKm float64
+}
+
+// Server implements weatherv1.WeatherServiceServer.
+type Server struct {
+	weatherv1.UnimplementedWeatherServiceServer
+
+	weatherService module.WeatherService
+	bus            *events.Bus
+	cfg            Config
+	logger         *zap.Logger
+}
+
+// NewServer creates a new Server.
+func NewServer(weatherService module.WeatherService, bus *events.Bus, cfg Config, logger *zap.Logger) *Server {
+	return &Server{
+		weatherService: weatherService,
+		bus:            bus,
+		cfg:            cfg,
+		logger:         logger,
+	}
+}
+
+// Register adds the weather service to g.
+func (s *Server) Register(g *grpc.Server) {
+	weatherv1.RegisterWeatherServiceServer(g, s)
+}
+
+// GetWeather mirrors GET /weather.
+func (s *Server) GetWeather(ctx context.Context, in *weatherv1.WeatherRequest) (*weatherv1.WeatherResponse, error) {
+	rq := weatherRequest(in)
+	if rq.DateTime == "" {
+		rq.DateTime = time.Now().Format("2006-01-02")
+	}
+	if err := rq.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	weather, err := s.weatherService.GetWeather(ctx, rq)
+	if err != nil {
+		s.logger.Warn("Failed to fetch weather", zap.Error(err), zap.Any("request", rq))
+		return nil, statusError(ctx, err)
+	}
+	return weatherResponse(weather), nil
+}
+
+// GetHistory mirrors GET /history.
+func (s *Server) GetHistory(ctx context.Context, in *weatherv1.GetHistoryRequest) (*weatherv1.GetHistoryResponse, error) {
+	filter := historyFilter(in)
+	if err := filter.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	history, err := s.weatherService.GetHistory(ctx, filter)
+	if err != nil {
+		s.logger.Warn("Failed to retrieve history", zap.Error(err))
+		return nil, statusError(ctx, err)
+	}
+	return historyPage(history), nil
+}
+
+// WatchWeather mirrors GET /weather/stream: it sends the current weather and
+// then every fresh update for the location until the client cancels.
+func (s *Server) WatchWeather(in *weatherv1.WatchWeatherRequest, stream grpc.ServerStreamingServer[weatherv1.WeatherUpdate]) error {
+	target := models.LocationQuery{City: strings.TrimSpace(in.GetCity())}
+	if l := in.GetLocation(); l != nil {
+		coordinate := location(l)
+		target.Coordinate = &coordinate
+	}
+	if err := target.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sub := s.bus.Subscribe(s.cfg.Buffer)
+	defer sub.Close()
+	ctx := stream.Context()
+	s.logger.Info("Weather watch opened", zap.String("location", target.Label()))
+
+	var sentID uint64
+	if id, weather, ok := s.current(ctx, target); ok {
+		// Updates published while fetching are already in the snapshot.
+		sentID = id
+		if err := stream.Send(&weatherv1.WeatherUpdate{EventId: id, Weather: weatherResponse(weather)}); err != nil {
+			return err
+		}
+	}
+
+	refresh := time.NewTicker(s.cfg.Refresh)
+	defer refresh.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Weather watch closed", zap.String("location", target.Label()))
+			return status.FromContextError(ctx.Err()).Err()
+		case e := <-sub.C:
+			if e.ID <= sentID || !target.Matches(e.City, e.Weather, s.cfg.MatchRadiusKm) {
+				continue
+			}
+			sentID = e.ID
+			if err := stream.Send(&weatherv1.WeatherUpdate{EventId: e.ID, Weather: weatherResponse(e.Weather)}); err != nil {
+				return err
+			}
+		case <-refresh.C:
+			// Fresh data is published on the bus and arrives on sub.C.
+			s.current(ctx, target)
+		}
+	}
+}
+
+// current fetches the weather for target. It returns the latest event ID at
+// that point, so events up to it can be skipped.
+func (s *Server) current(ctx context.Context, target models.LocationQuery) (uint64, models.WeatherResponse, bool) {
+	weather, err := s.weatherService.GetWeather(ctx, target.Request(time.Now().Format("2006-01-02")))
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("Failed to fetch weather for watch", zap.Error(err), zap.String("location", target.Label()))
+		}
+		return 0, models.WeatherResponse{}, false
+	}
+	return s.bus.LastID(), weather, true
+}
+
+// statusError maps an error from the weather service to a status, as the
+// HTTP API maps it to a status code, unless it was caused by the call being
+// cancelled or running out of time. Server side failures are reported by
+// their kind only.
+func statusError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	switch {
+	case errors.Is(err, models.ErrInvalidRequest):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrLocationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, models.ErrAmbiguousLocation):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, models.ErrUpstreamRateLimited):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, models.ErrUpstreamUnavailable):
+		return status.Error(codes.Unavailable, models.ErrUpstreamUnavailable.Error())
+	case errors.Is(err, models.ErrStorage):
+		return status.Error(codes.Internal, models.ErrStorage.Error())
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+// LoggingInterceptor logs every unary call with its outcome.
+func LoggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logger.Info("gRPC call",
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("latency", time.Since(start)),
+		)
+		return resp, err
+	}
+}
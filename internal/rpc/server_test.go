@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	weatherv1 "github.com/Orion777-cmd/weather-app/proto/weather/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// failingProvider forecasts one day for every city except those in errs,
+// for which it fails.
+type failingProvider struct {
+	errs map[string]error
+}
+
+func (p failingProvider) Geocode(_ context.Context, city string) (models.Place, error) {
+	if err := p.errs[city]; err != nil {
+		return models.Place{}, err
+	}
+	return models.Place{Name: city, Country: "DE", Coordinate: models.Location{Latitude: 52.52, Longitude: 13.405}}, nil
+}
+
+func (p failingProvider) GetWeather(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error {
+	place, err := p.Geocode(ctx, rq.City)
+	if err != nil {
+		return err
+	}
+	response.Address = place.Name
+	response.Country = place.Country
+	response.Latitude = place.Coordinate.Latitude
+	response.Longitude = place.Coordinate.Longitude
+	response.Days = []models.Weather{{Datetime: rq.DateTime + " 00:00:00", Temp: 12}}
+	return nil
+}
+
+// dial serves srv over an in-memory listener and returns a client for it.
+func dial(t *testing.T, srv *Server) weatherv1.WeatherServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer(grpc.UnaryInterceptor(LoggingInterceptor(zap.NewNop())))
+	srv.Register(g)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return weatherv1.NewWeatherServiceClient(conn)
+}
+
+func TestGetWeather(t *testing.T) {
+	provider := failingProvider{errs: map[string]error{
+		"Atlantis":    fmt.Errorf("geocoding: %w", models.ErrLocationNotFound),
+		"Springfield": &models.AmbiguousLocationError{City: "Springfield"},
+		"Busy":        &models.RateLimitedError{RetryAfter: time.Minute},
+		"Down":        fmt.Errorf("GET https://provider/?appid=secret: %w", models.ErrUpstreamUnavailable),
+		"Broken":      errors.New("GET https://provider/?appid=secret: unexpected EOF"),
+	}}
+	svc := module.NewService(provider, repository.NewMemoryRepository(10), nil, events.NewBus(1), module.Config{CacheTTL: time.Hour}, zap.NewNop())
+	client := dial(t, NewServer(svc, events.NewBus(1), Config{}, zap.NewNop()))
+	ctx := context.Background()
+
+	weather, err := client.GetWeather(ctx, &weatherv1.WeatherRequest{City: "Berlin", Datetime: "2026-10-19"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weather.GetAddress() != "Berlin" || weather.GetLatitude() != 52.52 || len(weather.GetDays()) != 1 || weather.GetDays()[0].GetTemp() != 12 {
+		t.Errorf("got %v, want Berlin's day", weather)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		in      *weatherv1.WeatherRequest
+		code    codes.Code
+		message string
+	}{
+		{"no location", &weatherv1.WeatherRequest{}, codes.InvalidArgument, ""},
+		{"city and location", &weatherv1.WeatherRequest{City: "Berlin", Location: &weatherv1.Location{Latitude: 52.52, Longitude: 13.405}}, codes.InvalidArgument, ""},
+		{"unknown city", &weatherv1.WeatherRequest{City: "Atlantis"}, codes.NotFound, "geocoding: location not found"},
+		{"ambiguous city", &weatherv1.WeatherRequest{City: "Springfield"}, codes.FailedPrecondition, ""},
+		{"rate limited", &weatherv1.WeatherRequest{City: "Busy"}, codes.Unavailable, ""},
+		{"provider down", &weatherv1.WeatherRequest{City: "Down"}, codes.Unavailable, "weather provider unavailable"},
+		{"internal", &weatherv1.WeatherRequest{City: "Broken"}, codes.Internal, "internal error"},
+	} {
+		_, err := client.GetWeather(ctx, tt.in)
+		s := status.Convert(err)
+		if s.Code() != tt.code || tt.message != "" && s.Message() != tt.message {
+			t.Errorf("%s: %v %q, want %v %q", tt.name, s.Code(), s.Message(), tt.code, tt.message)
+		}
+	}
+}
+
+func TestStatusErrorCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if code := status.Code(statusError(ctx, errors.New("fetch aborted"))); code != codes.Canceled {
+		t.Errorf("cancelled call: %v, want Canceled", code)
+	}
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	if code := status.Code(statusError(ctx, models.ErrUpstreamUnavailable)); code != codes.DeadlineExceeded {
+		t.Errorf("timed out call: %v, want DeadlineExceeded", code)
+	}
+}
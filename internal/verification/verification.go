@@ -0,0 +1,92 @@
+// Package verification scores stored forecasts against the conditions later
+// observed at the same location. Each daily forecast in a snapshot is paired
+// with the current conditions stored for that location on the day it was
+// for, and the errors are summarized as MAE, bias and RMSE per provider, field
+// and lead time.
+package verification
+
+import (
+	"context"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	runsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_verification_runs_total",
+		Help: "Forecast verification runs by result.",
+	}, []string{"result"})
+	scoresWritten = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_verification_scores",
+		Help: "Scores written by the last verification run.",
+	})
+	lastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "weather_verification_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful verification run.",
+	})
+)
+
+// Config controls how often forecasts are rescored and over how much history.
+type Config struct {
+	Interval time.Duration
+	// WindowDays is how many days of observations are scored against.
+	WindowDays int
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Interval, validation.Required),
+		validation.Field(&c.WindowDays, validation.Required, validation.Min(1)),
+	)
+}
+
+// Worker recomputes the verification scores on a schedule.
+type Worker struct {
+	repo *repository.WeatherRepository
+	cfg  Config
+	log  *zap.Logger
+}
+
+func NewWorker(repo *repository.WeatherRepository, cfg Config, log *zap.Logger) *Worker {
+	return &Worker{repo: repo, cfg: cfg, log: log}
+}
+
+// Run scores forecasts immediately and then on every interval until ctx is
+// cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			w.log.Error("Forecast verification failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce replaces the stored scores with ones computed over the last
+// WindowDays of observations.
+func (w *Worker) RunOnce(ctx context.Context) error {
+	since := time.Now().AddDate(0, 0, -w.cfg.WindowDays)
+	n, err := w.repo.ComputeVerification(ctx, since)
+	if err != nil {
+		runsTotal.WithLabelValues("error").Inc()
+		return err
+	}
+
+	runsTotal.WithLabelValues("success").Inc()
+	scoresWritten.Set(float64(n))
+	lastSuccess.SetToCurrentTime()
+	w.log.Info("Forecast verification completed", zap.Int64("scores", n), zap.Time("since", since))
+	return nil
+}
@@ -0,0 +1,244 @@
+// Package webhook notifies subscribers when a condition holds on the forecast
+// for their location. The Evaluator turns weather updates into queued
+// deliveries and the Dispatcher posts them to the subscribers' callback URLs.
+//
+// Every request carries the headers
+//
+//	X-Webhook-Delivery:  the delivery ID, stable across retries
+//	X-Webhook-Event:     the event key
+//	X-Webhook-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256>
+//
+// where the HMAC is keyed with the subscription secret and computed over the
+// timestamp, a dot and the raw body. Receivers should recompute it and reject
+// stale timestamps. Any 2xx response counts as delivered; anything else is
+// retried with exponential backoff until MaxAttempts, after which the
+// delivery is left in the dead state.
+//
+// Deliveries only connect to public addresses, whatever a callback host
+// resolves to at the time, and ignore proxy settings.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+)
+
+var (
+	deliveriesQueued = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_webhook_deliveries_queued_total",
+		Help: "Webhook deliveries queued by the subscription evaluator.",
+	})
+	duplicatesSuppressed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "weather_webhook_duplicates_suppressed_total",
+		Help: "Matches not queued because the same event was already notified.",
+	})
+	attemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_webhook_attempts_total",
+		Help: "Webhook delivery attempts by result.",
+	}, []string{"result"})
+)
+
+// Config controls delivery polling, timeouts and retries.
+type Config struct {
+	Interval    time.Duration
+	BatchSize   int
+	Concurrency int
+	Timeout     time.Duration
+	MaxAttempts int
+	// BackoffBase is the delay after the first failure; it doubles with every
+	// further failure up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Interval, validation.Required),
+		validation.Field(&c.BatchSize, validation.Required, validation.Min(1)),
+		validation.Field(&c.Concurrency, validation.Required, validation.Min(1)),
+		validation.Field(&c.Timeout, validation.Required),
+		validation.Field(&c.MaxAttempts, validation.Required, validation.Min(1)),
+		validation.Field(&c.BackoffBase, validation.Required),
+		validation.Field(&c.BackoffMax, validation.Required, validation.Min(c.BackoffBase)),
+	)
+}
+
+// Dispatcher sends queued webhook deliveries.
+type Dispatcher struct {
+	repo   *repository.WeatherRepository
+	client *http.Client
+	cfg    Config
+	log    *zap.Logger
+}
+
+func NewDispatcher(repo *repository.WeatherRepository, cfg Config, log *zap.Logger) *Dispatcher {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   cfg.Timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnly,
+	}).DialContext
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		cfg:    cfg,
+		log:    log,
+	}
+}
+
+// publicOnly refuses connections to non-public addresses. It runs after the
+// host name is resolved, for every connection including redirects, so a
+// callback host cannot be pointed at an internal service later.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !models.PublicAddr(addr) {
+		return fmt.Errorf("%w: %s", models.ErrCallbackNotPublic, addr)
+	}
+	return nil
+}
+
+// Run sends due deliveries immediately and then on every interval until ctx
+// is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			d.log.Error("Webhook dispatch failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends every delivery that is currently due, a batch at a time.
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	lease := 2*d.cfg.Timeout + d.cfg.Interval
+	for {
+		due, err := d.repo.ClaimDueDeliveries(ctx, d.cfg.BatchSize, lease)
+		if err != nil {
+			return err
+		}
+
+		sem := make(chan struct{}, d.cfg.Concurrency)
+		var wg sync.WaitGroup
+		for _, delivery := range due {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				d.deliver(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(due) < d.cfg.BatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// deliver makes one attempt and records its outcome.
+func (d *Dispatcher) deliver(ctx context.Context, delivery repository.PendingDelivery) {
+	statusCode, err := d.send(ctx, delivery)
+	record := context.WithoutCancel(ctx)
+	if err == nil {
+		attemptsTotal.WithLabelValues("delivered").Inc()
+		if err := d.repo.MarkDeliveryDelivered(record, delivery.ID, statusCode); err != nil {
+			d.log.Error("Failed to record webhook delivery", zap.Error(err), zap.Int64("delivery_id", delivery.ID))
+		}
+		return
+	}
+
+	dead := delivery.Attempts+1 >= d.cfg.MaxAttempts
+	if dead {
+		attemptsTotal.WithLabelValues("dead").Inc()
+		d.log.Warn("Webhook delivery dead", zap.Error(err), zap.Int64("delivery_id", delivery.ID), zap.Int("attempts", delivery.Attempts+1))
+	} else {
+		attemptsTotal.WithLabelValues("failed").Inc()
+		d.log.Info("Webhook delivery failed, will retry", zap.Error(err), zap.Int64("delivery_id", delivery.ID))
+	}
+	if err := d.repo.MarkDeliveryFailed(record, delivery.ID, statusCode, err, d.backoff(delivery.Attempts), dead); err != nil {
+		d.log.Error("Failed to record webhook delivery", zap.Error(err), zap.Int64("delivery_id", delivery.ID))
+	}
+}
+
+// send posts the payload. It returns the response status, or zero if there
+// was no response, and an error unless the status is 2xx.
+func (d *Dispatcher) send(ctx context.Context, delivery repository.PendingDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.CallbackURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Event", delivery.EventKey)
+	req.Header.Set("X-Webhook-Signature", Sign(delivery.Secret, time.Now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff is the delay before the next attempt after attempts earlier
+// failures: BackoffBase doubled per failure, capped at BackoffMax, less up to
+// a fifth at random so retries from one outage spread out.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BackoffMax
+	if attempts < 32 {
+		if exp := d.cfg.BackoffBase << attempts; exp > 0 && exp < delay {
+			delay = exp
+		}
+	}
+	return delay - time.Duration(rand.Float64()*0.2*float64(delay))
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
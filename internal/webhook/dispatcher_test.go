@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	body := []byte(`{"event":"test"}`)
+
+	// printf '1767225600.{"event":"test"}' | openssl dgst -sha256 -hmac s3cret
+	want := "t=1767225600,v1=732bb40fb3fe62adb038c61ec1dd3b0593d32e8360b0f4726d10f00c1e66dcfc"
+	if got := Sign("s3cret", at, body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	if Sign("other", at, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("s3cret", at.Add(time.Second), body) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if Sign("s3cret", at, []byte(`{"event":"tampered"}`)) == want {
+		t.Error("signature does not depend on the body")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{cfg: Config{BackoffBase: time.Minute, BackoffMax: time.Hour}}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Minute},
+		{1, 2 * time.Minute},
+		{2, 4 * time.Minute},
+		{5, 32 * time.Minute},
+		{6, time.Hour},
+		{31, time.Hour},
+		// Shifts past the width of a Duration must not wrap around.
+		{40, time.Hour},
+		{1000, time.Hour},
+	}
+	for _, tt := range tests {
+		for range 100 {
+			got := d.backoff(tt.attempts)
+			if got > tt.want || got < tt.want*4/5 {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", tt.attempts, got, tt.want*4/5, tt.want)
+			}
+		}
+	}
+}
+
+func TestSendRefusesNonPublicAddresses(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(nil, Config{Timeout: time.Second}, zap.NewNop())
+	_, err := d.send(context.Background(), repository.PendingDelivery{
+		ID:          1,
+		CallbackURL: server.URL,
+		Secret:      "s3cret",
+		Payload:     []byte(`{}`),
+	})
+	if !errors.Is(err, models.ErrCallbackNotPublic) {
+		t.Errorf("send to %s: got %v, want ErrCallbackNotPublic", server.URL, err)
+	}
+	if hits.Load() != 0 {
+		t.Error("the loopback server was reached")
+	}
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/expr"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Evaluator checks subscriptions against every weather update published on
+// the bus and queues a delivery for each forecast day that meets a
+// subscription's condition. A day is only notified once per subscription.
+type Evaluator struct {
+	repo     *repository.WeatherRepository
+	bus      *events.Bus
+	radiusKm float64
+	log      *zap.Logger
+}
+
+// NewEvaluator returns an evaluator that matches coordinate subscriptions
+// within radiusKm of an update.
+func NewEvaluator(repo *repository.WeatherRepository, bus *events.Bus, radiusKm float64, log *zap.Logger) *Evaluator {
+	return &Evaluator{repo: repo, bus: bus, radiusKm: radiusKm, log: log}
+}
+
+// Run evaluates events until ctx is cancelled. The subscription is lossless,
+// so a burst of updates delays notifications instead of losing them.
+func (e *Evaluator) Run(ctx context.Context) {
+	sub := e.bus.SubscribeLossless()
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-sub.C:
+			if err := e.Evaluate(ctx, event); err != nil && ctx.Err() == nil {
+				e.log.Error("Failed to evaluate subscriptions", zap.Error(err), zap.Uint64("event_id", event.ID))
+			}
+		}
+	}
+}
+
+// Evaluate queues deliveries for the subscriptions event satisfies.
+func (e *Evaluator) Evaluate(ctx context.Context, event events.Event) error {
+	weather := event.Weather
+	coordinate := models.Location{Latitude: weather.Latitude, Longitude: weather.Longitude}
+	subs, err := e.repo.ListSubscriptionsForLocation(ctx, []string{event.City, weather.Address}, coordinate, e.radiusKm)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		condition, err := expr.Compile(sub.Condition)
+		if err != nil {
+			// Conditions are compiled when subscriptions are created, so this
+			// only happens if the language changes under stored rows.
+			e.log.Warn("Skipping subscription with invalid condition", zap.Error(err), zap.Int32("subscription_id", sub.ID))
+			continue
+		}
+
+		for _, day := range forecastDays(weather, sub.Days) {
+			if !condition.Match(day) {
+				continue
+			}
+			if err := e.enqueue(ctx, sub, weather, day); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Evaluator) enqueue(ctx context.Context, sub models.Subscription, weather models.WeatherResponse, day models.Weather) error {
+	// The event is the condition holding on a given forecast date, so later
+	// updates of the same day do not notify again.
+	eventKey := "condition:" + forecastDate(day)
+	payload, err := json.Marshal(models.WebhookPayload{
+		SubscriptionID: sub.ID,
+		EventKey:       eventKey,
+		Condition:      sub.Condition,
+		Address:        weather.Address,
+		Country:        weather.Country,
+		Latitude:       weather.Latitude,
+		Longitude:      weather.Longitude,
+		Day:            day,
+		FetchedAt:      weather.FetchedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	queued, err := e.repo.EnqueueDelivery(ctx, sub.ID, eventKey, payload)
+	if err != nil {
+		return err
+	}
+	if queued {
+		deliveriesQueued.Inc()
+		e.log.Info("Webhook delivery queued", zap.Int32("subscription_id", sub.ID), zap.String("event_key", eventKey))
+	} else {
+		duplicatesSuppressed.Inc()
+	}
+	return nil
+}
+
+// forecastDays returns up to n forecast days of weather. The first entry of
+// Days holds current conditions and is skipped.
+func forecastDays(weather models.WeatherResponse, n int) []models.Weather {
+	if len(weather.Days) <= 1 {
+		return nil
+	}
+	days := weather.Days[1:]
+	if len(days) > n {
+		days = days[:n]
+	}
+	return days
+}
+
+// forecastDate is the date part of a day's Datetime.
+func forecastDate(day models.Weather) string {
+	date, _, _ := strings.Cut(day.Datetime, " ")
+	return date
+}
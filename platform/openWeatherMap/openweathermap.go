@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/Orion777-cmd/weather-app/internal/constants/models"
@@ -14,6 +15,13 @@ import (
 	"go.uber.org/zap"
 )
 
+// ProviderName identifies OpenWeatherMap data in stored snapshots.
+const ProviderName = "openweathermap"
+
+// ambiguityRadiusKm is how far apart geocoding results must be to count as
+// different places rather than parts of one.
+const ambiguityRadiusKm = 50
+
 // openWeatherMap implements the WeatherAPI interface for OpenWeatherMap.
 type OpenWeatherMap struct {
 	geocodingBaseURL string
@@ -32,13 +40,19 @@ func InitOpenWeatherMap(geocodingBaseURL, oneCallBaseURL string, log *zap.Logger
 
 // geocodeResponse holds the Geocoding API response structure.
 type geocodeResponse struct {
-	Lat float64 `json:"lat"`
-	Lon float64 `json:"lon"`
+	Name    string  `json:"name"`
+	State   string  `json:"state"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
 }
 
 // oneCallResponse holds the One Call API response structure.
 type oneCallResponse struct {
-	Current struct {
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	Timezone string  `json:"timezone"`
+	Current  struct {
 		Dt        int64   `json:"dt"`        // Unix timestamp
 		Temp      float64 `json:"temp"`      // Kelvin
 		Humidity  float64 `json:"humidity"`  // Percentage
@@ -76,54 +90,117 @@ type oneCallResponse struct {
 	} `json:"daily"`
 }
 
+// Geocode resolves a city name to its coordinates using the Geocoding API,
+// returning the best match. If the name matches places further apart than
+// ambiguityRadiusKm they are listed in the result's Candidates.
+func (o *OpenWeatherMap) Geocode(ctx context.Context, city string) (models.Place, error) {
+	cityQuery := url.QueryEscape(city)
+	url := fmt.Sprintf(o.geocodingBaseURL, cityQuery)
+	o.log.Info("Calling Geocoding API", zap.String("url", url))
+
+	body, err := o.get(ctx, url)
+	if err != nil {
+		o.log.Error("Unable to get geocoding data", zap.Error(err), zap.String("city", city))
+		return models.Place{}, err
+	}
+
+	var geocodes []geocodeResponse
+	if err := json.Unmarshal(body, &geocodes); err != nil {
+		o.log.Error("Error unmarshaling geocoding JSON", zap.Error(err), zap.String("city", city))
+		return models.Place{}, fmt.Errorf("%w: error unmarshaling geocoding JSON: %v", models.ErrUpstreamUnavailable, err)
+	}
+
+	if len(geocodes) == 0 {
+		o.log.Warn("No geocoding results found", zap.String("city", city))
+		return models.Place{}, fmt.Errorf("%w: %q", models.ErrLocationNotFound, city)
+	}
+
+	places := make([]models.Place, 0, len(geocodes))
+	for _, g := range geocodes {
+		place := models.Place{
+			Name:       g.Name,
+			State:      g.State,
+			Country:    g.Country,
+			Coordinate: models.Location{Latitude: g.Lat, Longitude: g.Lon},
+		}
+		distinct := true
+		for _, p := range places {
+			if p.Coordinate.DistanceKm(place.Coordinate) <= ambiguityRadiusKm {
+				distinct = false
+				break
+			}
+		}
+		if distinct {
+			places = append(places, place)
+		}
+	}
+	place := places[0]
+	if len(places) > 1 {
+		place.Candidates = places
+	}
+	return place, nil
+}
+
+// get fetches url, classifying failures as models.ErrUpstreamUnavailable or,
+// when the provider rate limits us, a *models.RateLimitedError.
+func (o *OpenWeatherMap) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrUpstreamUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &models.RateLimitedError{RetryAfter: retryAfter(resp.Header.Get("Retry-After"))}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", models.ErrUpstreamUnavailable, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error reading response: %v", models.ErrUpstreamUnavailable, err)
+	}
+	return body, nil
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date, returning zero if it is absent or invalid.
+func retryAfter(header string) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && time.Until(t) > 0 {
+		return time.Until(t).Round(time.Second)
+	}
+	return 0
+}
+
 func (o *OpenWeatherMap) GetWeather(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error {
 	// Validate request
 	if err := rq.Validate(); err != nil {
 		o.log.Error("Invalid request", zap.Error(err), zap.Any("request", rq))
-		return fmt.Errorf("validation failed: %v", err)
+		return models.InvalidRequest(err)
 	}
 
 	var lat, lon float64
+	var name, country string
 
 	// Determine coordinates
 	if rq.City != "" {
 		// City-based: Call Geocoding API
-		cityQuery := url.QueryEscape(rq.City)
-		o.log.Info("*******************Geocoding base URL template", zap.String("geocodingBaseURL", o.geocodingBaseURL))
-		url := fmt.Sprintf(o.geocodingBaseURL, cityQuery)
-		o.log.Info("Calling Geocoding API", zap.String("url", url))
-
-		resp, err := http.Get(url)
-		if err != nil {
-			o.log.Error("Unable to get geocoding data", zap.Error(err), zap.Any("request", rq))
-			return fmt.Errorf("geocoding request failed: %v", err)
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			o.log.Error("Unexpected geocoding status", zap.Int("status", resp.StatusCode), zap.Any("request", rq))
-			return fmt.Errorf("unexpected geocoding status: %d", resp.StatusCode)
-		}
-
-		body, err := io.ReadAll(resp.Body)
+		place, err := o.Geocode(ctx, rq.City)
 		if err != nil {
-			o.log.Error("Error reading geocoding response", zap.Error(err), zap.Any("request", rq))
-			return fmt.Errorf("error reading geocoding response: %v", err)
-		}
-
-		var geocodes []geocodeResponse
-		if err := json.Unmarshal(body, &geocodes); err != nil {
-			o.log.Error("Error unmarshaling geocoding JSON", zap.Error(err), zap.Any("request", rq))
-			return fmt.Errorf("error unmarshaling geocoding JSON: %v", err)
+			return err
 		}
-
-		if len(geocodes) == 0 {
-			o.log.Error("No geocoding results found", zap.Any("request", rq))
-			return fmt.Errorf("no geocoding results for city: %s", rq.City)
-		}
-
-		lat = geocodes[0].Lat
-		lon = geocodes[0].Lon
+		lat = place.Coordinate.Latitude
+		lon = place.Coordinate.Longitude
+		name = place.Name
+		country = place.Country
 	} else {
 		// Lat/lon-based
 		lat = rq.Coordinate.Latitude
@@ -134,36 +211,31 @@ func (o *OpenWeatherMap) GetWeather(ctx context.Context, rq models.WeatherReques
 	url := fmt.Sprintf(o.oneCallBaseURL, fmt.Sprintf("%f", lat), fmt.Sprintf("%f", lon))
 	o.log.Info("Calling One Call API", zap.String("url", url))
 
-	resp, err := http.Get(url)
+	body, err := o.get(ctx, url)
 	if err != nil {
 		o.log.Error("Unable to get weather data", zap.Error(err), zap.Any("request", rq))
-		return fmt.Errorf("weather request failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		o.log.Error("Unexpected weather status", zap.Int("status", resp.StatusCode), zap.Any("request", rq))
-		return fmt.Errorf("unexpected weather status: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		o.log.Error("Error reading weather response", zap.Error(err), zap.Any("request", rq))
-		return fmt.Errorf("error reading weather response: %v", err)
+		return err
 	}
 
 	var weatherData oneCallResponse
 	if err := json.Unmarshal(body, &weatherData); err != nil {
 		o.log.Error("Error unmarshaling weather JSON", zap.Error(err), zap.Any("request", rq))
-		return fmt.Errorf("error unmarshaling weather JSON: %v", err)
+		return fmt.Errorf("%w: error unmarshaling weather JSON: %v", models.ErrUpstreamUnavailable, err)
 	}
 
 	// Map to WeatherResponse
+	response.Address = name
+	response.Country = country
+	response.Latitude = weatherData.Lat
+	response.Longitude = weatherData.Lon
+	response.Timezone = weatherData.Timezone
+	response.Source = ProviderName
+	response.FetchedAt = time.Now().UTC()
 	response.Days = make([]models.Weather, 0, len(weatherData.Daily)+1)
 
 	// Add current weather as first day
 	currentWeather := models.Weather{
-		Datetime:  time.Unix(weatherData.Current.Dt, 0).Format("2006-01-02 15:04:05"),
+		Datetime:  models.FormatDatetime(time.Unix(weatherData.Current.Dt, 0)),
 		Tempmin:   float32(weatherData.Current.Temp - 273.15), // Celsius
 		Tempmax:   float32(weatherData.Current.Temp - 273.15), // Approximate
 		Humidity:  float32(weatherData.Current.Humidity),
@@ -179,7 +251,7 @@ func (o *OpenWeatherMap) GetWeather(ctx context.Context, rq models.WeatherReques
 	// Add daily forecasts
 	for _, daily := range weatherData.Daily {
 		dailyWeather := models.Weather{
-			Datetime:  time.Unix(daily.Dt, 0).Format("2006-01-02 15:04:05"),
+			Datetime:  models.FormatDatetime(time.Unix(daily.Dt, 0)),
 			Tempmin:   float32(daily.Temp.Min - 273.15),
 			Tempmax:   float32(daily.Temp.Max - 273.15),
 			Humidity:  float32(daily.Humidity),
@@ -198,7 +270,7 @@ func (o *OpenWeatherMap) GetWeather(ctx context.Context, rq models.WeatherReques
 		currentWeather.Hours = make([]models.Weather, 0, len(weatherData.Hourly))
 		for _, hourly := range weatherData.Hourly[:24] { // Limit to 24 hours
 			hourlyWeather := models.Weather{
-				Datetime:  time.Unix(hourly.Dt, 0).Format("2006-01-02 15:04:05"),
+				Datetime:  models.FormatDatetime(time.Unix(hourly.Dt, 0)),
 				Tempmin:   float32(hourly.Temp - 273.15),
 				Tempmax:   float32(hourly.Temp - 273.15),
 				Humidity:  float32(hourly.Humidity),
@@ -8,4 +8,27 @@ import (
 
 type WeatherAPI interface {
 	GetWeather(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error
-}
\ No newline at end of file
+	Geocode(ctx context.Context, city string) (models.Place, error)
+}
+
+// CachedWeatherAPI is a WeatherAPI fronted by an in-process cache. Cached
+// serves a response from memory only and reports whether it had one; it is
+// marked Stale once past the cache's TTL, and GetWeather then fetches anew.
+type CachedWeatherAPI interface {
+	WeatherAPI
+	Cached(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) bool
+}
+
+// EmailMessage is an email with plain text and HTML alternatives. Headers are
+// added to the message as is.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg EmailMessage) error
+}
@@ -0,0 +1,204 @@
+// Package smtp sends email through an SMTP server. For local development any
+// SMTP sink works, for example Mailpit from docker-compose, which accepts mail
+// on port 1025 without TLS or authentication and shows it on port 8025.
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/platform"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"go.uber.org/zap"
+)
+
+// TLS modes.
+const (
+	// TLSNone sends in the clear. Only use it with a local sink.
+	TLSNone = "none"
+	// TLSStartTLS upgrades the connection with STARTTLS, usually on port 587.
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects over TLS, usually on port 465.
+	TLSImplicit = "tls"
+)
+
+// Config describes the SMTP server and the sender address.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      string
+	Timeout  time.Duration
+}
+
+func (c Config) Validate() error {
+	err := validation.ValidateStruct(&c,
+		validation.Field(&c.Host, validation.Required),
+		validation.Field(&c.Port, validation.Required, validation.Min(1), validation.Max(65535)),
+		validation.Field(&c.From, validation.Required),
+		validation.Field(&c.TLS, validation.Required, validation.In(TLSNone, TLSStartTLS, TLSImplicit)),
+		validation.Field(&c.Timeout, validation.Required),
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	return nil
+}
+
+// Mailer implements platform.Mailer. It opens a connection per message.
+type Mailer struct {
+	cfg  Config
+	from *mail.Address
+	log  *zap.Logger
+}
+
+// NewMailer returns a mailer for a valid config.
+func NewMailer(cfg Config, log *zap.Logger) platform.Mailer {
+	from, _ := mail.ParseAddress(cfg.From)
+	return &Mailer{cfg: cfg, from: from, log: log}
+}
+
+func (m *Mailer) Send(ctx context.Context, msg platform.EmailMessage) error {
+	body, err := m.compose(msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	// net/smtp has no context support, so the deadline is put on the
+	// connection instead.
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.cfg.TLS == TLSStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: m.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	m.log.Info("Email sent", zap.String("to", msg.To), zap.String("subject", msg.Subject))
+	return client.Quit()
+}
+
+func (m *Mailer) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	if m.cfg.TLS == TLSImplicit {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: m.cfg.Host}}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// compose builds a multipart/alternative message with quoted-printable text
+// and HTML parts.
+func (m *Mailer) compose(msg platform.EmailMessage) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, alt := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alt.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(alt.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		"From":         m.from.String(),
+		"To":           msg.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-ID":   "<" + messageID() + "@" + m.cfg.Host + ">",
+		"MIME-Version": "1.0",
+		"Content-Type": "multipart/alternative; boundary=" + parts.Boundary(),
+	}
+	for k, v := range msg.Headers {
+		headers[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&out, "%s: %s\r\n", k, headers[k])
+	}
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func messageID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
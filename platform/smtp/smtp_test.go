@@ -0,0 +1,106 @@
+package smtp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/platform"
+	"go.uber.org/zap"
+)
+
+// TestMailpit sends through the Mailpit sink from docker-compose and reads the
+// message back from its API. Start it with
+//
+//	docker compose up -d mailpit
+//	MAILPIT_URL=http://localhost:8025 go test ./platform/smtp
+//
+// SMTP is expected on port 1025 of the same host.
+func TestMailpit(t *testing.T) {
+	base := os.Getenv("MAILPIT_URL")
+	if base == "" {
+		t.Skip("MAILPIT_URL not set")
+	}
+	api, err := url.Parse(base)
+	if err != nil {
+		t.Fatalf("MAILPIT_URL: %v", err)
+	}
+
+	cfg := Config{
+		Host:    api.Hostname(),
+		Port:    1025,
+		From:    "Weather <weather@localhost>",
+		TLS:     TLSNone,
+		Timeout: 10 * time.Second,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	to := fmt.Sprintf("test-%d@example.com", time.Now().UnixNano())
+	msg := platform.EmailMessage{
+		To:      to,
+		Subject: "Wetter für München",
+		Text:    "Sunny, 24 °C.\n" + strings.Repeat("long line ", 20),
+		HTML:    "<p>Sunny, 24 &deg;C.</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://weather.example.com/v1/digests/unsubscribe?token=abc>"},
+	}
+	if err := NewMailer(cfg, zap.NewNop()).Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	var search struct {
+		Messages []struct {
+			ID      string
+			Subject string
+		}
+	}
+	getJSON(t, base+"/api/v1/search?query="+url.QueryEscape("to:"+to), &search)
+	if len(search.Messages) != 1 {
+		t.Fatalf("found %d messages to %s, want 1", len(search.Messages), to)
+	}
+	if got := search.Messages[0].Subject; got != msg.Subject {
+		t.Errorf("subject = %q, want %q", got, msg.Subject)
+	}
+
+	id := search.Messages[0].ID
+	var message struct {
+		Text string
+		HTML string
+	}
+	getJSON(t, base+"/api/v1/message/"+id, &message)
+	// Long lines are wrapped for quoted-printable and must come back whole.
+	text := strings.ReplaceAll(message.Text, "\r\n", "\n")
+	if strings.TrimSpace(text) != strings.TrimSpace(msg.Text) {
+		t.Errorf("text = %q, want %q", message.Text, msg.Text)
+	}
+	if !strings.Contains(message.HTML, msg.HTML) {
+		t.Errorf("html = %q, want it to contain %q", message.HTML, msg.HTML)
+	}
+
+	var headers map[string][]string
+	getJSON(t, base+"/api/v1/message/"+id+"/headers", &headers)
+	if got := headers["List-Unsubscribe"]; len(got) != 1 || got[0] != msg.Headers["List-Unsubscribe"] {
+		t.Errorf("List-Unsubscribe = %q, want %q", got, msg.Headers["List-Unsubscribe"])
+	}
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+}
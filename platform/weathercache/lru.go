@@ -0,0 +1,76 @@
+package weathercache
+
+import (
+	"container/list"
+	"time"
+)
+
+// lru is a size-bounded least-recently-used map whose entries also carry an
+// expiry time. It is not safe for concurrent use.
+type lru[V any] struct {
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	onEvict  func()
+}
+
+type lruEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func newLRU[V any](capacity int, onEvict func()) *lru[V] {
+	return &lru[V]{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		onEvict:  onEvict,
+	}
+}
+
+// get returns the value for key if present and not expired at now. Expired
+// entries are removed.
+func (l *lru[V]) get(key string, now time.Time) (V, bool) {
+	var zero V
+	el, ok := l.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*lruEntry[V])
+	if !now.Before(entry.expires) {
+		l.remove(el)
+		return zero, false
+	}
+	l.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// add stores value under key until expires, evicting the least recently used
+// entry if the cache is full.
+func (l *lru[V]) add(key string, value V, expires time.Time) {
+	if el, ok := l.items[key]; ok {
+		entry := el.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expires = expires
+		l.order.MoveToFront(el)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry[V]{key: key, value: value, expires: expires})
+	if l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+		if l.onEvict != nil {
+			l.onEvict()
+		}
+	}
+}
+
+func (l *lru[V]) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.items, el.Value.(*lruEntry[V]).key)
+}
+
+func (l *lru[V]) len() int {
+	return l.order.Len()
+}
@@ -0,0 +1,277 @@
+// Package weathercache is an in-process cache in front of a platform.WeatherAPI.
+// Responses are kept in a bounded LRU and keyed by coordinates rounded to a
+// configurable precision, so nearby requests share an entry. Concurrent misses
+// for the same key are coalesced into one upstream call.
+//
+// Entries are fresh for TTL. After that, up to MaxAge, Cached still serves
+// them marked stale, and the caller decides whether to refresh them; GetWeather
+// treats them as misses.
+package weathercache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/platform"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache names used as the "cache" metric label.
+const (
+	weatherCache = "weather"
+	geocodeCache = "geocode"
+)
+
+var (
+	hitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_memory_cache_hits_total",
+		Help: "In-process cache hits.",
+	}, []string{"cache"})
+	missesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_memory_cache_misses_total",
+		Help: "In-process cache misses.",
+	}, []string{"cache"})
+	evictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_memory_cache_evictions_total",
+		Help: "Entries evicted from the in-process cache to make room.",
+	}, []string{"cache"})
+	staleTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_memory_cache_stale_total",
+		Help: "Stale entries served from the in-process cache.",
+	}, []string{"cache"})
+	coalescedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_memory_cache_coalesced_total",
+		Help: "Misses that shared an in-flight upstream call instead of making their own.",
+	}, []string{"cache"})
+	entries = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "weather_memory_cache_entries",
+		Help: "Entries currently held in the in-process cache.",
+	}, []string{"cache"})
+)
+
+// Config sizes the cache.
+type Config struct {
+	Capacity int
+	// TTL is how long an entry is fresh.
+	TTL time.Duration
+	// MaxAge is how long an entry may be served at all. Between TTL and
+	// MaxAge Cached serves it stale. Zero means the same as TTL.
+	MaxAge time.Duration
+	// Precision is the number of decimal places coordinates are rounded to
+	// when building cache keys. Two places is roughly 1 km.
+	Precision int
+}
+
+func (c Config) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Capacity, validation.Required, validation.Min(1)),
+		validation.Field(&c.TTL, validation.Required),
+		validation.Field(&c.MaxAge, validation.Min(c.TTL)),
+		validation.Field(&c.Precision, validation.Min(0), validation.Max(6)),
+	)
+}
+
+// Cache wraps a WeatherAPI with an in-process cache.
+type Cache struct {
+	api platform.WeatherAPI
+	cfg Config
+	log *zap.Logger
+
+	mu      sync.Mutex
+	weather *lru[models.WeatherResponse]
+	places  *lru[models.Place]
+	group   singleflight.Group
+}
+
+// New returns a WeatherAPI that serves from memory where possible and falls
+// through to api otherwise.
+func New(api platform.WeatherAPI, cfg Config, log *zap.Logger) *Cache {
+	if cfg.MaxAge < cfg.TTL {
+		cfg.MaxAge = cfg.TTL
+	}
+	return &Cache{
+		api:     api,
+		cfg:     cfg,
+		log:     log,
+		weather: newLRU[models.WeatherResponse](cfg.Capacity, evictionsTotal.WithLabelValues(weatherCache).Inc),
+		places:  newLRU[models.Place](cfg.Capacity, evictionsTotal.WithLabelValues(geocodeCache).Inc),
+	}
+}
+
+var _ platform.CachedWeatherAPI = (*Cache)(nil)
+
+// GetWeather serves the fresh cached response for the request's rounded
+// coordinates, fetching it upstream if there is none. City requests are
+// geocoded first, through the geocoding cache. Responses served from memory,
+// or from an upstream call another request made, have Origin set to
+// models.OriginCache.
+func (c *Cache) GetWeather(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error {
+	key, upstream, place, err := c.resolve(ctx, rq)
+	if err != nil {
+		return err
+	}
+
+	var weather models.WeatherResponse
+	if cached, ok := c.lookup(key); ok && time.Since(cached.FetchedAt) < c.cfg.TTL {
+		hitsTotal.WithLabelValues(weatherCache).Inc()
+		weather = cached
+		weather.Origin = models.OriginCache
+	} else {
+		missesTotal.WithLabelValues(weatherCache).Inc()
+		// Only the caller whose call went upstream gets the response as
+		// fresh; those that joined it get it as cached, so the data is
+		// stored and published once.
+		leader := false
+		v, err, _ := c.group.Do("weather:"+key, func() (any, error) {
+			leader = true
+			return c.fetch(ctx, key, upstream)
+		})
+		if !leader {
+			coalescedTotal.WithLabelValues(weatherCache).Inc()
+		}
+		if err != nil {
+			return err
+		}
+		weather = v.(models.WeatherResponse)
+		if !leader {
+			weather.Origin = models.OriginCache
+		}
+	}
+
+	setPlace(&weather, place)
+	*response = weather
+	return nil
+}
+
+// Cached serves the response for rq from memory only, reporting whether an
+// entry was found. Entries past the TTL are served with Stale set, and it is
+// up to the caller to refresh them through GetWeather. Unlike GetWeather it
+// never calls upstream for the weather, so callers can try it before slower
+// stores.
+func (c *Cache) Cached(ctx context.Context, rq models.WeatherRequest, response *models.WeatherResponse) bool {
+	key, _, place, err := c.resolve(ctx, rq)
+	if err != nil {
+		return false
+	}
+	weather, cached := c.lookup(key)
+	if !cached {
+		return false
+	}
+	hitsTotal.WithLabelValues(weatherCache).Inc()
+	weather.Origin = models.OriginCache
+	if time.Since(weather.FetchedAt) >= c.cfg.TTL {
+		staleTotal.WithLabelValues(weatherCache).Inc()
+		weather.Stale = true
+	}
+	setPlace(&weather, place)
+	*response = weather
+	return true
+}
+
+// resolve returns the cache key and upstream request for rq, and the place a
+// city request was geocoded to.
+func (c *Cache) resolve(ctx context.Context, rq models.WeatherRequest) (string, models.WeatherRequest, *models.Place, error) {
+	if err := rq.Validate(); err != nil {
+		return "", models.WeatherRequest{}, nil, models.InvalidRequest(err)
+	}
+
+	coordinate := rq.Coordinate
+	var place *models.Place
+	if rq.City != "" {
+		p, err := c.Geocode(ctx, rq.City)
+		if err != nil {
+			return "", models.WeatherRequest{}, nil, err
+		}
+		place = &p
+		coordinate = p.Coordinate
+	}
+	coordinate = c.round(coordinate)
+	key := fmt.Sprintf("%.*f,%.*f", c.cfg.Precision, coordinate.Latitude, c.cfg.Precision, coordinate.Longitude)
+	return key, models.WeatherRequest{Coordinate: coordinate, DateTime: rq.DateTime}, place, nil
+}
+
+// setPlace names weather after the place a city request was geocoded to.
+func setPlace(weather *models.WeatherResponse, place *models.Place) {
+	if place != nil {
+		weather.Address = place.Name
+		weather.Country = place.Country
+	}
+}
+
+// Geocode resolves city through the geocoding cache. Names are matched case
+// insensitively.
+func (c *Cache) Geocode(ctx context.Context, city string) (models.Place, error) {
+	key := strings.ToLower(strings.TrimSpace(city))
+
+	c.mu.Lock()
+	place, ok := c.places.get(key, time.Now())
+	c.mu.Unlock()
+	if ok {
+		hitsTotal.WithLabelValues(geocodeCache).Inc()
+		return place, nil
+	}
+
+	missesTotal.WithLabelValues(geocodeCache).Inc()
+	v, err, shared := c.group.Do("geocode:"+key, func() (any, error) {
+		place, err := c.api.Geocode(context.WithoutCancel(ctx), city)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.places.add(key, place, time.Now().Add(c.cfg.TTL))
+		entries.WithLabelValues(geocodeCache).Set(float64(c.places.len()))
+		c.mu.Unlock()
+		return place, nil
+	})
+	if shared {
+		coalescedTotal.WithLabelValues(geocodeCache).Inc()
+	}
+	if err != nil {
+		return models.Place{}, err
+	}
+	return v.(models.Place), nil
+}
+
+func (c *Cache) lookup(key string) (models.WeatherResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.weather.get(key, time.Now())
+}
+
+// fetch calls upstream and stores the result. It runs inside a singleflight
+// call shared by several requests, so it must not be cancelled when the
+// request that started it goes away.
+func (c *Cache) fetch(ctx context.Context, key string, rq models.WeatherRequest) (models.WeatherResponse, error) {
+	var weather models.WeatherResponse
+	if err := c.api.GetWeather(context.WithoutCancel(ctx), rq, &weather); err != nil {
+		return models.WeatherResponse{}, err
+	}
+	if weather.FetchedAt.IsZero() {
+		weather.FetchedAt = time.Now().UTC()
+	}
+
+	c.mu.Lock()
+	c.weather.add(key, weather, weather.FetchedAt.Add(c.cfg.MaxAge))
+	entries.WithLabelValues(weatherCache).Set(float64(c.weather.len()))
+	c.mu.Unlock()
+
+	c.log.Debug("Cached weather", zap.String("key", key))
+	return weather, nil
+}
+
+func (c *Cache) round(l models.Location) models.Location {
+	scale := math.Pow10(c.cfg.Precision)
+	return models.Location{
+		Latitude:  math.Round(l.Latitude*scale) / scale,
+		Longitude: math.Round(l.Longitude*scale) / scale,
+	}
+}
@@ -0,0 +1,168 @@
+package weathercache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Orion777-cmd/weather-app/internal/constants/models"
+	"github.com/Orion777-cmd/weather-app/internal/events"
+	"github.com/Orion777-cmd/weather-app/internal/module"
+	"github.com/Orion777-cmd/weather-app/internal/repository"
+	"go.uber.org/zap"
+)
+
+func TestLRU(t *testing.T) {
+	evicted := 0
+	l := newLRU[int](2, func() { evicted++ })
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	l.add("a", 1, later)
+	l.add("b", 2, later)
+	l.get("a", now)
+	l.add("c", 3, later)
+	if _, ok := l.get("b", now); ok || evicted != 1 {
+		t.Errorf("least recently used entry kept, %d evictions", evicted)
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if v, ok := l.get(key, now); !ok || v != want {
+			t.Errorf("%s = %d, %v, want %d", key, v, ok, want)
+		}
+	}
+
+	// Replacing a value does not evict.
+	l.add("a", 4, later)
+	if v, _ := l.get("a", now); v != 4 || evicted != 1 || l.len() != 2 {
+		t.Errorf("replaced a = %d with %d entries and %d evictions", v, l.len(), evicted)
+	}
+
+	// Expired entries are dropped when looked up.
+	l.add("c", 5, now.Add(time.Second))
+	if _, ok := l.get("c", now.Add(2*time.Second)); ok || l.len() != 1 {
+		t.Errorf("expired entry served, %d entries left", l.len())
+	}
+}
+
+// slowAPI counts upstream calls and holds them until release is closed.
+type slowAPI struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (a *slowAPI) Geocode(_ context.Context, city string) (models.Place, error) {
+	return models.Place{Name: city, Coordinate: models.Location{Latitude: 52.52, Longitude: 13.405}}, nil
+}
+
+func (a *slowAPI) GetWeather(_ context.Context, rq models.WeatherRequest, response *models.WeatherResponse) error {
+	a.calls.Add(1)
+	<-a.release
+	response.Latitude = rq.Coordinate.Latitude
+	response.Longitude = rq.Coordinate.Longitude
+	response.Days = []models.Weather{{Datetime: "2026-10-19 00:00:00", Temp: 12}}
+	return nil
+}
+
+// countingStore counts the weather queries saved to it.
+type countingStore struct {
+	repository.Store
+	saves atomic.Int32
+}
+
+func (s *countingStore) SaveWeatherQuery(ctx context.Context, city string, weather models.WeatherResponse) error {
+	s.saves.Add(1)
+	return s.Store.SaveWeatherQuery(ctx, city, weather)
+}
+
+func TestCoalescedRequests(t *testing.T) {
+	const requests = 50
+	api := &slowAPI{release: make(chan struct{})}
+	cache := New(api, Config{Capacity: 10, TTL: time.Minute, Precision: 2}, zap.NewNop())
+	store := &countingStore{Store: repository.NewMemoryRepository(0)}
+	bus := events.NewBus(0)
+	updates := bus.Subscribe(requests)
+	svc := module.NewService(cache, store, nil, bus, module.Config{}, zap.NewNop())
+
+	var started, done sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := range requests {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			// Nearby coordinates share the rounded key.
+			rq := models.WeatherRequest{
+				Coordinate: models.Location{Latitude: 52.52 + float64(i%3)*0.001, Longitude: 13.405},
+				DateTime:   "2026-10-19",
+			}
+			started.Done()
+			_, err := svc.GetWeather(context.Background(), rq)
+			errs <- err
+		}()
+	}
+	started.Wait()
+	// Give every request time to join the call in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(api.release)
+	done.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := api.calls.Load(); n != 1 {
+		t.Errorf("%d upstream calls, want 1", n)
+	}
+	if n := store.saves.Load(); n != 1 {
+		t.Errorf("%d saves, want 1", n)
+	}
+	if n := len(updates.C); n != 1 {
+		t.Errorf("%d events published, want 1", n)
+	}
+
+	// Later requests are served from memory.
+	if _, err := svc.GetWeather(context.Background(), models.WeatherRequest{City: "Berlin", DateTime: "2026-10-19"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, saves := api.calls.Load(), store.saves.Load(); n != 1 || saves != 1 {
+		t.Errorf("after a hit: %d upstream calls and %d saves, want 1 and 1", n, saves)
+	}
+}
+
+func TestStaleEntriesAreRefreshed(t *testing.T) {
+	api := &slowAPI{release: make(chan struct{})}
+	close(api.release)
+	cache := New(api, Config{Capacity: 10, TTL: time.Nanosecond, MaxAge: time.Hour, Precision: 2}, zap.NewNop())
+	store := &countingStore{Store: repository.NewMemoryRepository(0)}
+	bus := events.NewBus(0)
+	updates := bus.Subscribe(2)
+	svc := module.NewService(cache, store, nil, bus, module.Config{}, zap.NewNop())
+	rq := models.WeatherRequest{City: "Berlin", DateTime: "2026-10-19"}
+
+	if _, err := svc.GetWeather(context.Background(), rq); err != nil {
+		t.Fatal(err)
+	}
+	<-updates.C
+
+	// The entry is past its TTL: it is served stale, and the refresh goes
+	// through the service, which stores and publishes it.
+	weather, err := svc.GetWeather(context.Background(), rq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !weather.Stale || weather.Origin != models.OriginCache {
+		t.Errorf("served origin %q, stale %v, want a stale cache hit", weather.Origin, weather.Stale)
+	}
+	select {
+	case <-updates.C:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the refresh was not published")
+	}
+	if n, saves := api.calls.Load(), store.saves.Load(); n != 2 || saves != 2 {
+		t.Errorf("%d upstream calls and %d saves, want 2 and 2", n, saves)
+	}
+}
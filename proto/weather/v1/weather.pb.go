@@ -0,0 +1,1188 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: weather/v1/weather.proto
+
+// The weather API over gRPC. It mirrors the HTTP API: GetWeather is
+// GET /weather, GetHistory is GET /history and WatchWeather is
+// GET /weather/stream.
+
+package weatherv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Location) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Location) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+// WeatherRequest names either a city or a location.
+type WeatherRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	City     string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Location *Location              `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	// Date as YYYY-MM-DD. Defaults to today.
+	Datetime string `protobuf:"bytes,3,opt,name=datetime,proto3" json:"datetime,omitempty"`
+	// Optional expression selecting the days and hours returned, such as
+	// "temp > 20 && precip == 0".
+	Filter        string `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeatherRequest) Reset() {
+	*x = WeatherRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeatherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeatherRequest) ProtoMessage() {}
+
+func (x *WeatherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeatherRequest.ProtoReflect.Descriptor instead.
+func (*WeatherRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WeatherRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *WeatherRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *WeatherRequest) GetDatetime() string {
+	if x != nil {
+		return x.Datetime
+	}
+	return ""
+}
+
+func (x *WeatherRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+type Weather struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Datetime      string                 `protobuf:"bytes,1,opt,name=datetime,proto3" json:"datetime,omitempty"`
+	Tempmin       float32                `protobuf:"fixed32,2,opt,name=tempmin,proto3" json:"tempmin,omitempty"`
+	Tempmax       float32                `protobuf:"fixed32,3,opt,name=tempmax,proto3" json:"tempmax,omitempty"`
+	Humidity      float32                `protobuf:"fixed32,4,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Precip        float32                `protobuf:"fixed32,5,opt,name=precip,proto3" json:"precip,omitempty"`
+	Snow          float32                `protobuf:"fixed32,6,opt,name=snow,proto3" json:"snow,omitempty"`
+	Snowdepth     float32                `protobuf:"fixed32,7,opt,name=snowdepth,proto3" json:"snowdepth,omitempty"`
+	Windspeed     float32                `protobuf:"fixed32,8,opt,name=windspeed,proto3" json:"windspeed,omitempty"`
+	Temp          float32                `protobuf:"fixed32,9,opt,name=temp,proto3" json:"temp,omitempty"`
+	Hours         []*Weather             `protobuf:"bytes,10,rep,name=hours,proto3" json:"hours,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Weather) Reset() {
+	*x = Weather{}
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Weather) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Weather) ProtoMessage() {}
+
+func (x *Weather) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Weather.ProtoReflect.Descriptor instead.
+func (*Weather) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Weather) GetDatetime() string {
+	if x != nil {
+		return x.Datetime
+	}
+	return ""
+}
+
+func (x *Weather) GetTempmin() float32 {
+	if x != nil {
+		return x.Tempmin
+	}
+	return 0
+}
+
+func (x *Weather) GetTempmax() float32 {
+	if x != nil {
+		return x.Tempmax
+	}
+	return 0
+}
+
+func (x *Weather) GetHumidity() float32 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *Weather) GetPrecip() float32 {
+	if x != nil {
+		return x.Precip
+	}
+	return 0
+}
+
+func (x *Weather) GetSnow() float32 {
+	if x != nil {
+		return x.Snow
+	}
+	return 0
+}
+
+func (x *Weather) GetSnowdepth() float32 {
+	if x != nil {
+		return x.Snowdepth
+	}
+	return 0
+}
+
+func (x *Weather) GetWindspeed() float32 {
+	if x != nil {
+		return x.Windspeed
+	}
+	return 0
+}
+
+func (x *Weather) GetTemp() float32 {
+	if x != nil {
+		return x.Temp
+	}
+	return 0
+}
+
+func (x *Weather) GetHours() []*Weather {
+	if x != nil {
+		return x.Hours
+	}
+	return nil
+}
+
+type AnomalyScore struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         float64                `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	Mean          float64                `protobuf:"fixed64,2,opt,name=mean,proto3" json:"mean,omitempty"`
+	Stddev        float64                `protobuf:"fixed64,3,opt,name=stddev,proto3" json:"stddev,omitempty"`
+	ZScore        float64                `protobuf:"fixed64,4,opt,name=z_score,json=zScore,proto3" json:"z_score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnomalyScore) Reset() {
+	*x = AnomalyScore{}
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnomalyScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnomalyScore) ProtoMessage() {}
+
+func (x *AnomalyScore) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnomalyScore.ProtoReflect.Descriptor instead.
+func (*AnomalyScore) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnomalyScore) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *AnomalyScore) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *AnomalyScore) GetStddev() float64 {
+	if x != nil {
+		return x.Stddev
+	}
+	return 0
+}
+
+func (x *AnomalyScore) GetZScore() float64 {
+	if x != nil {
+		return x.ZScore
+	}
+	return 0
+}
+
+// Anomalies compares the current conditions with the location's history.
+type Anomalies struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Samples       int32                  `protobuf:"varint,1,opt,name=samples,proto3" json:"samples,omitempty"`
+	WindowDays    int32                  `protobuf:"varint,2,opt,name=window_days,json=windowDays,proto3" json:"window_days,omitempty"`
+	Temp          *AnomalyScore          `protobuf:"bytes,3,opt,name=temp,proto3" json:"temp,omitempty"`
+	Precip        *AnomalyScore          `protobuf:"bytes,4,opt,name=precip,proto3" json:"precip,omitempty"`
+	Windspeed     *AnomalyScore          `protobuf:"bytes,5,opt,name=windspeed,proto3" json:"windspeed,omitempty"`
+	Flags         []string               `protobuf:"bytes,6,rep,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Anomalies) Reset() {
+	*x = Anomalies{}
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Anomalies) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Anomalies) ProtoMessage() {}
+
+func (x *Anomalies) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Anomalies.ProtoReflect.Descriptor instead.
+func (*Anomalies) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Anomalies) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *Anomalies) GetWindowDays() int32 {
+	if x != nil {
+		return x.WindowDays
+	}
+	return 0
+}
+
+func (x *Anomalies) GetTemp() *AnomalyScore {
+	if x != nil {
+		return x.Temp
+	}
+	return nil
+}
+
+func (x *Anomalies) GetPrecip() *AnomalyScore {
+	if x != nil {
+		return x.Precip
+	}
+	return nil
+}
+
+func (x *Anomalies) GetWindspeed() *AnomalyScore {
+	if x != nil {
+		return x.Windspeed
+	}
+	return nil
+}
+
+func (x *Anomalies) GetFlags() []string {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+type WeatherResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Address   string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Country   string                 `protobuf:"bytes,2,opt,name=country,proto3" json:"country,omitempty"`
+	Latitude  float64                `protobuf:"fixed64,3,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64                `protobuf:"fixed64,4,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Timezone  string                 `protobuf:"bytes,5,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Source    string                 `protobuf:"bytes,6,opt,name=source,proto3" json:"source,omitempty"`
+	Days      []*Weather             `protobuf:"bytes,7,rep,name=days,proto3" json:"days,omitempty"`
+	FetchedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=fetched_at,json=fetchedAt,proto3" json:"fetched_at,omitempty"`
+	// "provider" or "cache".
+	Origin string `protobuf:"bytes,9,opt,name=origin,proto3" json:"origin,omitempty"`
+	// Age of the data in seconds.
+	Age           int64      `protobuf:"varint,10,opt,name=age,proto3" json:"age,omitempty"`
+	Stale         bool       `protobuf:"varint,11,opt,name=stale,proto3" json:"stale,omitempty"`
+	Anomalies     *Anomalies `protobuf:"bytes,12,opt,name=anomalies,proto3" json:"anomalies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeatherResponse) Reset() {
+	*x = WeatherResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeatherResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeatherResponse) ProtoMessage() {}
+
+func (x *WeatherResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeatherResponse.ProtoReflect.Descriptor instead.
+func (*WeatherResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WeatherResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *WeatherResponse) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *WeatherResponse) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *WeatherResponse) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *WeatherResponse) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *WeatherResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *WeatherResponse) GetDays() []*Weather {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *WeatherResponse) GetFetchedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FetchedAt
+	}
+	return nil
+}
+
+func (x *WeatherResponse) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+func (x *WeatherResponse) GetAge() int64 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *WeatherResponse) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *WeatherResponse) GetAnomalies() *Anomalies {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+type GetHistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	City  string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	// Entries within radius_km of coordinate. Both or neither must be set.
+	Coordinate *Location              `protobuf:"bytes,2,opt,name=coordinate,proto3" json:"coordinate,omitempty"`
+	RadiusKm   float64                `protobuf:"fixed64,3,opt,name=radius_km,json=radiusKm,proto3" json:"radius_km,omitempty"`
+	From       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"`
+	Cursor     string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Limit      int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Return summaries instead of full responses.
+	Summary       bool `protobuf:"varint,8,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetHistoryRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetCoordinate() *Location {
+	if x != nil {
+		return x.Coordinate
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetRadiusKm() float64 {
+	if x != nil {
+		return x.RadiusKm
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetSummary() bool {
+	if x != nil {
+		return x.Summary
+	}
+	return false
+}
+
+type HistorySummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Days          int32                  `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"`
+	Temp          float32                `protobuf:"fixed32,2,opt,name=temp,proto3" json:"temp,omitempty"`
+	Tempmin       float32                `protobuf:"fixed32,3,opt,name=tempmin,proto3" json:"tempmin,omitempty"`
+	Tempmax       float32                `protobuf:"fixed32,4,opt,name=tempmax,proto3" json:"tempmax,omitempty"`
+	Humidity      float32                `protobuf:"fixed32,5,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Precip        float32                `protobuf:"fixed32,6,opt,name=precip,proto3" json:"precip,omitempty"`
+	Windspeed     float32                `protobuf:"fixed32,7,opt,name=windspeed,proto3" json:"windspeed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistorySummary) Reset() {
+	*x = HistorySummary{}
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistorySummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistorySummary) ProtoMessage() {}
+
+func (x *HistorySummary) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistorySummary.ProtoReflect.Descriptor instead.
+func (*HistorySummary) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HistorySummary) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *HistorySummary) GetTemp() float32 {
+	if x != nil {
+		return x.Temp
+	}
+	return 0
+}
+
+func (x *HistorySummary) GetTempmin() float32 {
+	if x != nil {
+		return x.Tempmin
+	}
+	return 0
+}
+
+func (x *HistorySummary) GetTempmax() float32 {
+	if x != nil {
+		return x.Tempmax
+	}
+	return 0
+}
+
+func (x *HistorySummary) GetHumidity() float32 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *HistorySummary) GetPrecip() float32 {
+	if x != nil {
+		return x.Precip
+	}
+	return 0
+}
+
+func (x *HistorySummary) GetWindspeed() float32 {
+	if x != nil {
+		return x.Windspeed
+	}
+	return 0
+}
+
+// HistoryEntry is a stored weather query. Exactly one of weather and summary
+// is set.
+type HistoryEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	City          string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	Latitude      *float64               `protobuf:"fixed64,3,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude     *float64               `protobuf:"fixed64,4,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	QueryTime     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=query_time,json=queryTime,proto3" json:"query_time,omitempty"`
+	Weather       *WeatherResponse       `protobuf:"bytes,6,opt,name=weather,proto3" json:"weather,omitempty"`
+	Summary       *HistorySummary        `protobuf:"bytes,7,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryEntry) Reset() {
+	*x = HistoryEntry{}
+	mi := &file_weather_v1_weather_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryEntry) ProtoMessage() {}
+
+func (x *HistoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryEntry.ProtoReflect.Descriptor instead.
+func (*HistoryEntry) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *HistoryEntry) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *HistoryEntry) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *HistoryEntry) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *HistoryEntry) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *HistoryEntry) GetQueryTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.QueryTime
+	}
+	return nil
+}
+
+func (x *HistoryEntry) GetWeather() *WeatherResponse {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+func (x *HistoryEntry) GetSummary() *HistorySummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+type GetHistoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*HistoryEntry        `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// Empty on the last page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetHistoryResponse) GetItems() []*HistoryEntry {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *GetHistoryResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// WatchWeatherRequest names either a city or a location.
+type WatchWeatherRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	City          string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	Location      *Location              `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchWeatherRequest) Reset() {
+	*x = WatchWeatherRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchWeatherRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchWeatherRequest) ProtoMessage() {}
+
+func (x *WatchWeatherRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchWeatherRequest.ProtoReflect.Descriptor instead.
+func (*WatchWeatherRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchWeatherRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *WatchWeatherRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+type WeatherUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Increases with every update; 0 for the initial weather when nothing has
+	// been published yet.
+	EventId       uint64           `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Weather       *WeatherResponse `protobuf:"bytes,2,opt,name=weather,proto3" json:"weather,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WeatherUpdate) Reset() {
+	*x = WeatherUpdate{}
+	mi := &file_weather_v1_weather_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WeatherUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WeatherUpdate) ProtoMessage() {}
+
+func (x *WeatherUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WeatherUpdate.ProtoReflect.Descriptor instead.
+func (*WeatherUpdate) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WeatherUpdate) GetEventId() uint64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *WeatherUpdate) GetWeather() *WeatherResponse {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+var File_weather_v1_weather_proto protoreflect.FileDescriptor
+
+const file_weather_v1_weather_proto_rawDesc = "" +
+	"\n" +
+	"\x18weather/v1/weather.proto\x12\n" +
+	"weather.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"D\n" +
+	"\bLocation\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\"\x8a\x01\n" +
+	"\x0eWeatherRequest\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x120\n" +
+	"\blocation\x18\x02 \x01(\v2\x14.weather.v1.LocationR\blocation\x12\x1a\n" +
+	"\bdatetime\x18\x03 \x01(\tR\bdatetime\x12\x16\n" +
+	"\x06filter\x18\x04 \x01(\tR\x06filter\"\x9c\x02\n" +
+	"\aWeather\x12\x1a\n" +
+	"\bdatetime\x18\x01 \x01(\tR\bdatetime\x12\x18\n" +
+	"\atempmin\x18\x02 \x01(\x02R\atempmin\x12\x18\n" +
+	"\atempmax\x18\x03 \x01(\x02R\atempmax\x12\x1a\n" +
+	"\bhumidity\x18\x04 \x01(\x02R\bhumidity\x12\x16\n" +
+	"\x06precip\x18\x05 \x01(\x02R\x06precip\x12\x12\n" +
+	"\x04snow\x18\x06 \x01(\x02R\x04snow\x12\x1c\n" +
+	"\tsnowdepth\x18\a \x01(\x02R\tsnowdepth\x12\x1c\n" +
+	"\twindspeed\x18\b \x01(\x02R\twindspeed\x12\x12\n" +
+	"\x04temp\x18\t \x01(\x02R\x04temp\x12)\n" +
+	"\x05hours\x18\n" +
+	" \x03(\v2\x13.weather.v1.WeatherR\x05hours\"i\n" +
+	"\fAnomalyScore\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04mean\x18\x02 \x01(\x01R\x04mean\x12\x16\n" +
+	"\x06stddev\x18\x03 \x01(\x01R\x06stddev\x12\x17\n" +
+	"\az_score\x18\x04 \x01(\x01R\x06zScore\"\xf4\x01\n" +
+	"\tAnomalies\x12\x18\n" +
+	"\asamples\x18\x01 \x01(\x05R\asamples\x12\x1f\n" +
+	"\vwindow_days\x18\x02 \x01(\x05R\n" +
+	"windowDays\x12,\n" +
+	"\x04temp\x18\x03 \x01(\v2\x18.weather.v1.AnomalyScoreR\x04temp\x120\n" +
+	"\x06precip\x18\x04 \x01(\v2\x18.weather.v1.AnomalyScoreR\x06precip\x126\n" +
+	"\twindspeed\x18\x05 \x01(\v2\x18.weather.v1.AnomalyScoreR\twindspeed\x12\x14\n" +
+	"\x05flags\x18\x06 \x03(\tR\x05flags\"\x8c\x03\n" +
+	"\x0fWeatherResponse\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12\x1a\n" +
+	"\blatitude\x18\x03 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x04 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\btimezone\x18\x05 \x01(\tR\btimezone\x12\x16\n" +
+	"\x06source\x18\x06 \x01(\tR\x06source\x12'\n" +
+	"\x04days\x18\a \x03(\v2\x13.weather.v1.WeatherR\x04days\x129\n" +
+	"\n" +
+	"fetched_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tfetchedAt\x12\x16\n" +
+	"\x06origin\x18\t \x01(\tR\x06origin\x12\x10\n" +
+	"\x03age\x18\n" +
+	" \x01(\x03R\x03age\x12\x14\n" +
+	"\x05stale\x18\v \x01(\bR\x05stale\x123\n" +
+	"\tanomalies\x18\f \x01(\v2\x15.weather.v1.AnomaliesR\tanomalies\"\x9e\x02\n" +
+	"\x11GetHistoryRequest\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x124\n" +
+	"\n" +
+	"coordinate\x18\x02 \x01(\v2\x14.weather.v1.LocationR\n" +
+	"coordinate\x12\x1b\n" +
+	"\tradius_km\x18\x03 \x01(\x01R\bradiusKm\x12.\n" +
+	"\x04from\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\x12\x18\n" +
+	"\asummary\x18\b \x01(\bR\asummary\"\xbe\x01\n" +
+	"\x0eHistorySummary\x12\x12\n" +
+	"\x04days\x18\x01 \x01(\x05R\x04days\x12\x12\n" +
+	"\x04temp\x18\x02 \x01(\x02R\x04temp\x12\x18\n" +
+	"\atempmin\x18\x03 \x01(\x02R\atempmin\x12\x18\n" +
+	"\atempmax\x18\x04 \x01(\x02R\atempmax\x12\x1a\n" +
+	"\bhumidity\x18\x05 \x01(\x02R\bhumidity\x12\x16\n" +
+	"\x06precip\x18\x06 \x01(\x02R\x06precip\x12\x1c\n" +
+	"\twindspeed\x18\a \x01(\x02R\twindspeed\"\xb9\x02\n" +
+	"\fHistoryEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04city\x18\x02 \x01(\tR\x04city\x12\x1f\n" +
+	"\blatitude\x18\x03 \x01(\x01H\x00R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\x04 \x01(\x01H\x01R\tlongitude\x88\x01\x01\x129\n" +
+	"\n" +
+	"query_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tqueryTime\x125\n" +
+	"\aweather\x18\x06 \x01(\v2\x1b.weather.v1.WeatherResponseR\aweather\x124\n" +
+	"\asummary\x18\a \x01(\v2\x1a.weather.v1.HistorySummaryR\asummaryB\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitude\"e\n" +
+	"\x12GetHistoryResponse\x12.\n" +
+	"\x05items\x18\x01 \x03(\v2\x18.weather.v1.HistoryEntryR\x05items\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"[\n" +
+	"\x13WatchWeatherRequest\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x120\n" +
+	"\blocation\x18\x02 \x01(\v2\x14.weather.v1.LocationR\blocation\"a\n" +
+	"\rWeatherUpdate\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x04R\aeventId\x125\n" +
+	"\aweather\x18\x02 \x01(\v2\x1b.weather.v1.WeatherResponseR\aweather2\xf2\x01\n" +
+	"\x0eWeatherService\x12E\n" +
+	"\n" +
+	"GetWeather\x12\x1a.weather.v1.WeatherRequest\x1a\x1b.weather.v1.WeatherResponse\x12K\n" +
+	"\n" +
+	"GetHistory\x12\x1d.weather.v1.GetHistoryRequest\x1a\x1e.weather.v1.GetHistoryResponse\x12L\n" +
+	"\fWatchWeather\x12\x1f.weather.v1.WatchWeatherRequest\x1a\x19.weather.v1.WeatherUpdate0\x01B@Z>github.com/Orion777-cmd/weather-app/proto/weather/v1;weatherv1b\x06proto3"
+
+var (
+	file_weather_v1_weather_proto_rawDescOnce sync.Once
+	file_weather_v1_weather_proto_rawDescData []byte
+)
+
+func file_weather_v1_weather_proto_rawDescGZIP() []byte {
+	file_weather_v1_weather_proto_rawDescOnce.Do(func() {
+		file_weather_v1_weather_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_weather_v1_weather_proto_rawDesc), len(file_weather_v1_weather_proto_rawDesc)))
+	})
+	return file_weather_v1_weather_proto_rawDescData
+}
+
+var file_weather_v1_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_weather_v1_weather_proto_goTypes = []any{
+	(*Location)(nil),              // 0: weather.v1.Location
+	(*WeatherRequest)(nil),        // 1: weather.v1.WeatherRequest
+	(*Weather)(nil),               // 2: weather.v1.Weather
+	(*AnomalyScore)(nil),          // 3: weather.v1.AnomalyScore
+	(*Anomalies)(nil),             // 4: weather.v1.Anomalies
+	(*WeatherResponse)(nil),       // 5: weather.v1.WeatherResponse
+	(*GetHistoryRequest)(nil),     // 6: weather.v1.GetHistoryRequest
+	(*HistorySummary)(nil),        // 7: weather.v1.HistorySummary
+	(*HistoryEntry)(nil),          // 8: weather.v1.HistoryEntry
+	(*GetHistoryResponse)(nil),    // 9: weather.v1.GetHistoryResponse
+	(*WatchWeatherRequest)(nil),   // 10: weather.v1.WatchWeatherRequest
+	(*WeatherUpdate)(nil),         // 11: weather.v1.WeatherUpdate
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_weather_v1_weather_proto_depIdxs = []int32{
+	0,  // 0: weather.v1.WeatherRequest.location:type_name -> weather.v1.Location
+	2,  // 1: weather.v1.Weather.hours:type_name -> weather.v1.Weather
+	3,  // 2: weather.v1.Anomalies.temp:type_name -> weather.v1.AnomalyScore
+	3,  // 3: weather.v1.Anomalies.precip:type_name -> weather.v1.AnomalyScore
+	3,  // 4: weather.v1.Anomalies.windspeed:type_name -> weather.v1.AnomalyScore
+	2,  // 5: weather.v1.WeatherResponse.days:type_name -> weather.v1.Weather
+	12, // 6: weather.v1.WeatherResponse.fetched_at:type_name -> google.protobuf.Timestamp
+	4,  // 7: weather.v1.WeatherResponse.anomalies:type_name -> weather.v1.Anomalies
+	0,  // 8: weather.v1.GetHistoryRequest.coordinate:type_name -> weather.v1.Location
+	12, // 9: weather.v1.GetHistoryRequest.from:type_name -> google.protobuf.Timestamp
+	12, // 10: weather.v1.GetHistoryRequest.to:type_name -> google.protobuf.Timestamp
+	12, // 11: weather.v1.HistoryEntry.query_time:type_name -> google.protobuf.Timestamp
+	5,  // 12: weather.v1.HistoryEntry.weather:type_name -> weather.v1.WeatherResponse
+	7,  // 13: weather.v1.HistoryEntry.summary:type_name -> weather.v1.HistorySummary
+	8,  // 14: weather.v1.GetHistoryResponse.items:type_name -> weather.v1.HistoryEntry
+	0,  // 15: weather.v1.WatchWeatherRequest.location:type_name -> weather.v1.Location
+	5,  // 16: weather.v1.WeatherUpdate.weather:type_name -> weather.v1.WeatherResponse
+	1,  // 17: weather.v1.WeatherService.GetWeather:input_type -> weather.v1.WeatherRequest
+	6,  // 18: weather.v1.WeatherService.GetHistory:input_type -> weather.v1.GetHistoryRequest
+	10, // 19: weather.v1.WeatherService.WatchWeather:input_type -> weather.v1.WatchWeatherRequest
+	5,  // 20: weather.v1.WeatherService.GetWeather:output_type -> weather.v1.WeatherResponse
+	9,  // 21: weather.v1.WeatherService.GetHistory:output_type -> weather.v1.GetHistoryResponse
+	11, // 22: weather.v1.WeatherService.WatchWeather:output_type -> weather.v1.WeatherUpdate
+	20, // [20:23] is the sub-list for method output_type
+	17, // [17:20] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_weather_v1_weather_proto_init() }
+func file_weather_v1_weather_proto_init() {
+	if File_weather_v1_weather_proto != nil {
+		return
+	}
+	file_weather_v1_weather_proto_msgTypes[8].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_weather_v1_weather_proto_rawDesc), len(file_weather_v1_weather_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weather_v1_weather_proto_goTypes,
+		DependencyIndexes: file_weather_v1_weather_proto_depIdxs,
+		MessageInfos:      file_weather_v1_weather_proto_msgTypes,
+	}.Build()
+	File_weather_v1_weather_proto = out.File
+	file_weather_v1_weather_proto_goTypes = nil
+	file_weather_v1_weather_proto_depIdxs = nil
+}